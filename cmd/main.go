@@ -5,6 +5,7 @@ import (
 	"log"
 	"video-platform/config"
 	"video-platform/internal/handler"
+	"video-platform/internal/service"
 	"video-platform/pkg/database"
 	"video-platform/pkg/redis"
 
@@ -28,6 +29,10 @@ func main() {
 		log.Fatal(err)
 	}
 	defer database.CloseMongoDB()
+	// 关注、点赞、分享等功能依赖唯一索引去重，启动时确保索引存在
+	if _, err := service.EnsureIndexes(ctx, false); err != nil {
+		log.Fatal(err)
+	}
 	if err := redis.InitRedis(ctx, config.GlobalConfig.Redis.URI); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"video-platform/config"
+	"video-platform/internal/service"
+	"video-platform/pkg/database"
+	"video-platform/pkg/redis"
+)
+
+// task 数据迁移/修复任务
+type task struct {
+	desc  string
+	run   func(ctx context.Context, dryRun bool) (*service.MigrationResult, error)
+	redis bool // 是否需要连接Redis
+}
+
+// tasks 可执行的任务列表
+var tasks = map[string]task{
+	"recount-interactions": {
+		desc: "根据点赞和收藏记录重新计算视频的likes和favorites计数",
+		run:  service.RecountVideoInteractions,
+	},
+	"ensure-indexes": {
+		desc: "创建短链接、通知、私信、播放列表等功能依赖的唯一索引和查询索引",
+		run:  service.EnsureIndexes,
+	},
+	"refresh-recommend-pool": {
+		desc: "根据近期公开视频的互动数据刷新推荐候选池，建议定时执行",
+		run:  service.RefreshRecommendCandidates,
+	},
+	"repair-video-copies": {
+		desc: "修复收藏、观看历史、稍后再看和播放列表中与视频不一致的标题、封面和时长",
+		run:  service.RepairVideoCopies,
+	},
+	"repair-annotations": {
+		desc: "删除标记已不存在的孤立注释，并补齐注释的video_id",
+		run:  service.RepairAnnotations,
+	},
+	"compact-note-revisions": {
+		desc: "按保留策略清理笔记的历史版本，建议每天执行",
+		run:  service.CompactNoteRevisions,
+	},
+	"cleanup-note-images": {
+		desc: "删除上传超过24小时仍未保存到笔记的图片，建议每天执行",
+		run:  service.CleanupNoteImages,
+	},
+	"rebuild-trending": {
+		desc:  "根据最近一周的点赞、评论、观看和分享记录重建热门排行榜",
+		run:   service.RebuildTrending,
+		redis: true,
+	},
+}
+
+func usage() {
+	fmt.Println("数据迁移工具")
+	fmt.Println("\n用法:")
+	fmt.Println("  migrate -task <任务名> [-dry-run]")
+	fmt.Println("\n选项:")
+	flag.PrintDefaults()
+	fmt.Println("\n任务:")
+	for name, t := range tasks {
+		fmt.Printf("  %-24s %s\n", name, t.desc)
+	}
+}
+
+func main() {
+	name := flag.String("task", "", "要执行的任务")
+	dryRun := flag.Bool("dry-run", false, "试运行模式，只统计不修改数据")
+	flag.Usage = usage
+	flag.Parse()
+
+	t, ok := tasks[*name]
+	if !ok {
+		usage()
+		os.Exit(1)
+	}
+
+	// 初始化配置
+	if err := config.Init(); err != nil {
+		log.Fatal(err)
+	}
+
+	// 连接数据库
+	ctx := context.Background()
+	if err := database.InitMongoDB(ctx, config.GlobalConfig.MongoDB, false); err != nil {
+		log.Fatal(err)
+	}
+	defer database.CloseMongoDB()
+
+	if t.redis {
+		if err := redis.InitRedis(ctx, config.GlobalConfig.Redis.URI); err != nil {
+			log.Fatal(err)
+		}
+		defer redis.CloseRedis()
+	}
+
+	result, err := t.run(ctx, *dryRun)
+	if err != nil {
+		log.Fatalf("任务 %s 执行失败: %v", *name, err)
+	}
+
+	if *dryRun {
+		fmt.Printf("[试运行] 任务 %s: 扫描 %d 条，需要修复 %d 条\n", *name, result.Scanned, result.Fixed)
+	} else {
+		fmt.Printf("任务 %s 完成: 扫描 %d 条，修复 %d 条\n", *name, result.Scanned, result.Fixed)
+	}
+}
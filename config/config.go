@@ -11,13 +11,20 @@ import (
 
 // Config 全局配置结构体
 type Config struct {
-	Env     string
-	MongoDB MongoDBConfig
-	Server  ServerConfig
-	Storage StorageConfig
-	JWT     JWTConfig
-	Redis   RedisConfig
-	SMS     SMSConfig
+	Env        string
+	MongoDB    MongoDBConfig
+	Server     ServerConfig
+	Storage    StorageConfig
+	JWT        JWTConfig
+	Redis      RedisConfig
+	SMS        SMSConfig
+	Danmaku    DanmakuConfig
+	Share      ShareConfig
+	Feed       FeedConfig
+	Recommend  RecommendConfig
+	WatchLater WatchLaterConfig
+	Moderation ModerationConfig
+	Note       NoteConfig
 }
 
 // MongoDBConfig MongoDB配置
@@ -59,6 +66,50 @@ type SMSConfig struct {
 	RegionID   string
 }
 
+// DanmakuConfig 弹幕配置
+type DanmakuConfig struct {
+	RateLimit  int64 // 限流窗口内每个用户最多发送的弹幕数
+	RateWindow int64 // 限流窗口（秒）
+}
+
+// ShareConfig 分享链接配置
+type ShareConfig struct {
+	BaseURL    string // 短链接的访问域名，如 https://v.example.com
+	LandingURL string // 短链接跳转的目标地址模板，%s 为视频ID
+}
+
+// FeedConfig 关注动态流配置
+type FeedConfig struct {
+	PushThreshold int64 // 粉丝数不超过该值的作者发布视频时推送到粉丝收件箱，超过时由粉丝读取时拉取
+	InboxSize     int64 // 每个用户收件箱保留的视频数
+}
+
+// RecommendConfig 推荐配置
+type RecommendConfig struct {
+	PoolSize      int64 // 候选池大小
+	PoolDays      int64 // 候选池只包含最近多少天发布的视频
+	FreshHalfLife int64 // 新鲜度半衰期（小时）
+}
+
+// WatchLaterConfig 稍后再看配置
+type WatchLaterConfig struct {
+	MaxItems          int64 // 每个用户最多保存的视频数
+	AutoRemovePercent int64 // 观看进度达到时长的百分比后自动移出列表，0表示不自动移出
+}
+
+// ModerationConfig 举报与审核配置
+type ModerationConfig struct {
+	ModeratorIDs  []string // 审核员用户ID
+	HideThreshold int64    // 同一内容被举报达到该次数后自动隐藏，0表示不自动隐藏
+	ClaimTimeout  int64    // 领取的审核工单超过该时间（分钟）未处理时，其他审核员可以重新领取
+}
+
+// NoteConfig 笔记配置
+type NoteConfig struct {
+	RevisionMax      int64 // 每条笔记最多保留的历史版本数
+	RevisionKeepDays int64 // 最近多少天内的历史版本全部保留，更早的每天只保留最后一个版本
+}
+
 var GlobalConfig Config
 
 // 从环境变量获取字符串，如果不存在则返回默认值
@@ -128,6 +179,36 @@ func Init() error {
 			Endpoint:   getEnvString("SMS_ENDPOINT", "dysmsapi.aliyuncs.com"),
 			RegionID:   getEnvString("SMS_REGION_ID", "cn-shenzhen"),
 		},
+		Danmaku: DanmakuConfig{
+			RateLimit:  getEnvInt64("DANMAKU_RATE_LIMIT", 5),   // 每个窗口5条
+			RateWindow: getEnvInt64("DANMAKU_RATE_WINDOW", 10), // 10秒
+		},
+		Share: ShareConfig{
+			BaseURL:    getEnvString("SHARE_BASE_URL", "http://localhost:8080"),
+			LandingURL: getEnvString("SHARE_LANDING_URL", "/api/v1/videos/%s/stream"),
+		},
+		Feed: FeedConfig{
+			PushThreshold: getEnvInt64("FEED_PUSH_THRESHOLD", 5000),
+			InboxSize:     getEnvInt64("FEED_INBOX_SIZE", 1000),
+		},
+		Recommend: RecommendConfig{
+			PoolSize:      getEnvInt64("RECOMMEND_POOL_SIZE", 2000),
+			PoolDays:      getEnvInt64("RECOMMEND_POOL_DAYS", 30),
+			FreshHalfLife: getEnvInt64("RECOMMEND_FRESH_HALF_LIFE", 72), // 72小时
+		},
+		WatchLater: WatchLaterConfig{
+			MaxItems:          getEnvInt64("WATCH_LATER_MAX_ITEMS", 1000),
+			AutoRemovePercent: getEnvInt64("WATCH_LATER_AUTO_REMOVE_PERCENT", 90),
+		},
+		Moderation: ModerationConfig{
+			ModeratorIDs:  getEnvStringSlice("MODERATOR_IDS", nil),
+			HideThreshold: getEnvInt64("REPORT_HIDE_THRESHOLD", 5),
+			ClaimTimeout:  getEnvInt64("REPORT_CLAIM_TIMEOUT", 30), // 30分钟
+		},
+		Note: NoteConfig{
+			RevisionMax:      getEnvInt64("NOTE_REVISION_MAX", 50),
+			RevisionKeepDays: getEnvInt64("NOTE_REVISION_KEEP_DAYS", 7),
+		},
 	}
 
 	// 确保上传目录存在
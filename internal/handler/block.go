@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BlockHandler struct {
+	blockService service.BlockService
+}
+
+func NewBlockHandler(blockService service.BlockService) *BlockHandler {
+	if blockService == nil {
+		blockService = service.NewBlockService()
+	}
+	return &BlockHandler{
+		blockService: blockService,
+	}
+}
+
+// Block 拉黑用户
+func (h *BlockHandler) Block(c *gin.Context) {
+	h.block(c, model.BlockModeBlock, "拉黑成功")
+}
+
+// Unblock 取消拉黑
+func (h *BlockHandler) Unblock(c *gin.Context) {
+	h.unblock(c, model.BlockModeBlock, "取消拉黑成功")
+}
+
+// Mute 静音用户
+func (h *BlockHandler) Mute(c *gin.Context) {
+	h.block(c, model.BlockModeMute, "静音成功")
+}
+
+// Unmute 取消静音
+func (h *BlockHandler) Unmute(c *gin.Context) {
+	h.unblock(c, model.BlockModeMute, "取消静音成功")
+}
+
+// List 获取屏蔽列表，可按 mode 过滤
+func (h *BlockHandler) List(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	mode := c.Query("mode")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	result, err := h.blockService.List(c.Request.Context(), userID.(string), mode, page, size)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListBlocks] 获取屏蔽列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+func (h *BlockHandler) block(c *gin.Context, mode, message string) {
+	userID, _ := c.Get("userId")
+	targetID := c.Param("userId")
+
+	if err := h.blockService.Block(c.Request.Context(), userID.(string), targetID, mode); err != nil {
+		h.fail(c, err)
+		slog.Error("[Block] 屏蔽用户失败", "error", err, "targetId", targetID, "mode", mode)
+		return
+	}
+
+	response.Success(c, gin.H{"message": message})
+}
+
+func (h *BlockHandler) unblock(c *gin.Context, mode, message string) {
+	userID, _ := c.Get("userId")
+	targetID := c.Param("userId")
+
+	if err := h.blockService.Unblock(c.Request.Context(), userID.(string), targetID, mode); err != nil {
+		h.fail(c, err)
+		slog.Error("[Unblock] 取消屏蔽失败", "error", err, "targetId", targetID, "mode", mode)
+		return
+	}
+
+	response.Success(c, gin.H{"message": message})
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *BlockHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrBlockNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidBlock):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChapterHandler struct {
+	chapterService service.ChapterService
+}
+
+func NewChapterHandler(chapterService service.ChapterService) *ChapterHandler {
+	if chapterService == nil {
+		chapterService = service.NewChapterService()
+	}
+	return &ChapterHandler{
+		chapterService: chapterService,
+	}
+}
+
+// Set 设置视频章节（整体替换）
+func (h *ChapterHandler) Set(c *gin.Context) {
+	userID := c.GetString("userId")
+	videoID := c.Param("videoId")
+
+	var req model.SetChaptersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[SetChapters] 无效的请求参数", "error", err)
+		return
+	}
+
+	chapters, err := h.chapterService.SetChapters(c.Request.Context(), userID, videoID, req.Chapters)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[SetChapters] 设置章节失败", "error", err, "videoId", videoID)
+		return
+	}
+	response.Success(c, chapters)
+}
+
+// Get 获取视频章节，format 为 vtt 或 mp4 时以附件形式下载章节文件
+func (h *ChapterHandler) Get(c *gin.Context) {
+	userID := c.GetString("userId")
+	videoID := c.Param("videoId")
+
+	format := c.DefaultQuery("format", model.ChapterFormatJSON)
+	if format == model.ChapterFormatJSON {
+		chapters, err := h.chapterService.GetChapters(c.Request.Context(), userID, videoID)
+		if err != nil {
+			h.fail(c, err)
+			return
+		}
+		response.Success(c, chapters)
+		return
+	}
+
+	file, err := h.chapterService.ExportChapters(c.Request.Context(), userID, videoID, format)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Header("Content-Disposition", contentDisposition(file.Filename))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *ChapterHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidChapter), errors.Is(err, service.ErrInvalidChapterFormat):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrChapterForbidden):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrChapterVideoNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
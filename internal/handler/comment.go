@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandler(commentService service.CommentService) *CommentHandler {
+	if commentService == nil {
+		commentService = service.NewCommentService()
+	}
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// Create 发表评论
+func (h *CommentHandler) Create(c *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := c.Get("userId")
+	if !exists {
+		response.Fail(c, http.StatusUnauthorized, "用户未登录")
+		slog.Error("[CreateComment] 用户未登录")
+		return
+	}
+
+	videoID := c.Param("videoId")
+
+	var req model.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[CreateComment] 无效的请求参数", "error", err)
+		return
+	}
+
+	comment, err := h.commentService.Create(c.Request.Context(), userID.(string), videoID, &req)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[CreateComment] 发表评论失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, comment)
+}
+
+// List 获取视频评论列表
+func (h *CommentHandler) List(c *gin.Context) {
+	videoID := c.Param("videoId")
+
+	var query model.CommentQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[ListComments] 无效的请求参数", "error", err)
+		return
+	}
+
+	// 获取当前用户ID（如果已登录）
+	currentUserID, _ := c.Get("userId")
+	viewerID, _ := currentUserID.(string)
+
+	comments, err := h.commentService.List(c.Request.Context(), videoID, viewerID, query)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListComments] 获取评论列表失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, comments)
+}
+
+// ListReplies 获取评论的回复列表
+func (h *CommentHandler) ListReplies(c *gin.Context) {
+	commentID := c.Param("commentId")
+
+	var query model.CommentQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[ListReplies] 无效的请求参数", "error", err)
+		return
+	}
+
+	// 获取当前用户ID（如果已登录）
+	currentUserID, _ := c.Get("userId")
+	viewerID, _ := currentUserID.(string)
+
+	replies, err := h.commentService.ListReplies(c.Request.Context(), commentID, viewerID, query)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListReplies] 获取回复列表失败", "error", err, "commentId", commentID)
+		return
+	}
+
+	response.Success(c, replies)
+}
+
+// Update 编辑评论
+func (h *CommentHandler) Update(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	commentID := c.Param("commentId")
+
+	var req model.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[UpdateComment] 无效的请求参数", "error", err)
+		return
+	}
+
+	comment, err := h.commentService.Update(c.Request.Context(), userID.(string), commentID, req.Content)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[UpdateComment] 编辑评论失败", "error", err, "commentId", commentID)
+		return
+	}
+
+	response.Success(c, comment)
+}
+
+// Delete 删除评论
+func (h *CommentHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	commentID := c.Param("commentId")
+
+	if err := h.commentService.Delete(c.Request.Context(), userID.(string), commentID); err != nil {
+		h.fail(c, err)
+		slog.Error("[DeleteComment] 删除评论失败", "error", err, "commentId", commentID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Like 点赞评论
+func (h *CommentHandler) Like(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	commentID := c.Param("commentId")
+
+	if err := h.commentService.Like(c.Request.Context(), userID.(string), commentID); err != nil {
+		h.fail(c, err)
+		slog.Error("[LikeComment] 点赞评论失败", "error", err, "commentId", commentID)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "点赞成功"})
+}
+
+// Unlike 取消点赞评论
+func (h *CommentHandler) Unlike(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	commentID := c.Param("commentId")
+
+	if err := h.commentService.Unlike(c.Request.Context(), userID.(string), commentID); err != nil {
+		h.fail(c, err)
+		slog.Error("[UnlikeComment] 取消点赞失败", "error", err, "commentId", commentID)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "取消点赞成功"})
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *CommentHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrCommentVideoNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCommentForbidden), errors.Is(err, service.ErrBlocked), errors.Is(err, service.ErrBlockingTarget):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
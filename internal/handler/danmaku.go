@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+	"video-platform/pkg/ws"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DanmakuHandler struct {
+	danmakuService service.DanmakuService
+}
+
+func NewDanmakuHandler(danmakuService service.DanmakuService) *DanmakuHandler {
+	if danmakuService == nil {
+		danmakuService = service.NewDanmakuService(nil)
+	}
+	return &DanmakuHandler{
+		danmakuService: danmakuService,
+	}
+}
+
+// Send 发送弹幕
+func (h *DanmakuHandler) Send(c *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := c.Get("userId")
+	if !exists {
+		response.Fail(c, http.StatusUnauthorized, "用户未登录")
+		slog.Error("[SendDanmaku] 用户未登录")
+		return
+	}
+
+	videoID := c.Param("videoId")
+
+	var req model.SendDanmakuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[SendDanmaku] 无效的请求参数", "error", err)
+		return
+	}
+
+	danmaku, err := h.danmakuService.Send(c.Request.Context(), userID.(string), videoID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDanmakuRateLimited):
+			response.Fail(c, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, service.ErrBlocked), errors.Is(err, service.ErrBlockingTarget):
+			response.Fail(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrDanmakuVideoNotFound):
+			response.Fail(c, http.StatusNotFound, err.Error())
+		default:
+			response.Fail(c, http.StatusInternalServerError, err.Error())
+		}
+		slog.Error("[SendDanmaku] 发送弹幕失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, danmaku)
+}
+
+// List 获取时间窗口内的弹幕
+func (h *DanmakuHandler) List(c *gin.Context) {
+	videoID := c.Param("videoId")
+
+	var query model.DanmakuQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[ListDanmaku] 无效的请求参数", "error", err)
+		return
+	}
+
+	// 获取当前用户ID（如果已登录）
+	viewerID := c.GetString("userId")
+
+	danmakus, err := h.danmakuService.List(c.Request.Context(), videoID, viewerID, query)
+	if err != nil {
+		if errors.Is(err, service.ErrDanmakuVideoNotFound) {
+			response.Fail(c, http.StatusNotFound, err.Error())
+		} else {
+			response.Fail(c, http.StatusInternalServerError, err.Error())
+		}
+		slog.Error("[ListDanmaku] 获取弹幕失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, danmakus)
+}
+
+// Live 通过WebSocket实时接收视频的新弹幕
+func (h *DanmakuHandler) Live(c *gin.Context) {
+	videoID := c.Param("videoId")
+
+	// 升级前检查可见性，非公开视频只有作者可以订阅
+	if err := h.danmakuService.CheckVisible(c.Request.Context(), videoID, c.GetString("userId")); err != nil {
+		if errors.Is(err, service.ErrDanmakuVideoNotFound) {
+			response.Fail(c, http.StatusNotFound, err.Error())
+		} else {
+			response.Fail(c, http.StatusInternalServerError, err.Error())
+		}
+		slog.Error("[LiveDanmaku] 订阅弹幕失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	conn, err := ws.Upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade失败时已经写入了错误响应
+		slog.Error("[LiveDanmaku] WebSocket升级失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	client := ws.NewClient(conn)
+	h.danmakuService.Subscribe(videoID, client)
+	defer h.danmakuService.Unsubscribe(videoID, client)
+
+	// 只推送不接收，弹幕统一通过HTTP接口发送以便鉴权和限流
+	client.Run(nil)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	if exportService == nil {
+		exportService = service.NewExportService(nil)
+	}
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// Export 导出标记、注释和笔记，以附件形式下载
+func (h *ExportHandler) Export(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var query model.ExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "请指定要导出的视频")
+		return
+	}
+
+	file, err := h.exportService.Export(c.Request.Context(), userID.(string), &query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidExportFormat), errors.Is(err, service.ErrExportTooMany):
+			response.Fail(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrExportVideoNotFound):
+			response.Fail(c, http.StatusNotFound, err.Error())
+		default:
+			response.Fail(c, http.StatusInternalServerError, "导出失败")
+			slog.Error("[Export] 导出标记和笔记失败", "error", err, "videoIds", query.VideoIDs)
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", contentDisposition(file.Filename))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// contentDisposition 生成附件下载头，filename 为ASCII兼容名称，filename* 保留中文文件名
+func contentDisposition(filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r > 0x7e || r < 0x20 || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, url.PathEscape(filename))
+}
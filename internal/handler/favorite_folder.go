@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FavoriteFolderHandler struct {
+	folderService service.FavoriteFolderService
+}
+
+func NewFavoriteFolderHandler(folderService service.FavoriteFolderService) *FavoriteFolderHandler {
+	if folderService == nil {
+		folderService = service.NewFavoriteFolderService()
+	}
+	return &FavoriteFolderHandler{
+		folderService: folderService,
+	}
+}
+
+// List 获取收藏夹列表
+func (h *FavoriteFolderHandler) List(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	folders, err := h.folderService.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListFavoriteFolders] 获取收藏夹列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, folders)
+}
+
+// Create 创建收藏夹
+func (h *FavoriteFolderHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var req model.FavoriteFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[CreateFavoriteFolder] 无效的请求参数", "error", err)
+		return
+	}
+
+	folder, err := h.folderService.Create(c.Request.Context(), userID.(string), req.Name)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[CreateFavoriteFolder] 创建收藏夹失败", "error", err)
+		return
+	}
+
+	response.Success(c, folder)
+}
+
+// Rename 重命名收藏夹
+func (h *FavoriteFolderHandler) Rename(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	folderID := c.Param("folderId")
+
+	var req model.FavoriteFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[RenameFavoriteFolder] 无效的请求参数", "error", err)
+		return
+	}
+
+	folder, err := h.folderService.Rename(c.Request.Context(), userID.(string), folderID, req.Name)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[RenameFavoriteFolder] 重命名收藏夹失败", "error", err, "folderId", folderID)
+		return
+	}
+
+	response.Success(c, folder)
+}
+
+// Delete 删除收藏夹，其中的收藏移回默认收藏夹
+func (h *FavoriteFolderHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	folderID := c.Param("folderId")
+
+	if err := h.folderService.Delete(c.Request.Context(), userID.(string), folderID); err != nil {
+		h.fail(c, err)
+		slog.Error("[DeleteFavoriteFolder] 删除收藏夹失败", "error", err, "folderId", folderID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// MoveFavorite 将收藏移动到其他收藏夹
+func (h *FavoriteFolderHandler) MoveFavorite(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	videoID := c.Param("videoId")
+
+	var req model.MoveFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[MoveFavorite] 无效的请求参数", "error", err)
+		return
+	}
+
+	if err := h.folderService.MoveFavorite(c.Request.Context(), userID.(string), videoID, req.FolderID); err != nil {
+		h.fail(c, err)
+		slog.Error("[MoveFavorite] 移动收藏失败", "error", err, "videoId", videoID, "folderId", req.FolderID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *FavoriteFolderHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFavoriteFolderNotFound), errors.Is(err, service.ErrFavoriteNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrFavoriteFolderExists):
+		response.Fail(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrFavoriteFolderLimit):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedHandler struct {
+	feedService      service.FeedService
+	recommendService service.RecommendService
+}
+
+func NewFeedHandler(feedService service.FeedService, recommendService service.RecommendService) *FeedHandler {
+	if feedService == nil {
+		feedService = service.NewFeedService()
+	}
+	if recommendService == nil {
+		recommendService = service.NewRecommendService()
+	}
+	return &FeedHandler{
+		feedService:      feedService,
+		recommendService: recommendService,
+	}
+}
+
+// Following 获取关注动态（游标分页）
+func (h *FeedHandler) Following(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var query model.FeedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[FollowingFeed] 无效的请求参数", "error", err)
+		return
+	}
+
+	feed, err := h.feedService.Timeline(c.Request.Context(), userID.(string), query)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[FollowingFeed] 获取关注动态失败", "error", err)
+		return
+	}
+
+	response.Success(c, feed)
+}
+
+// Recommend 获取推荐视频，未登录时返回热门视频
+func (h *FeedHandler) Recommend(c *gin.Context) {
+	userID := c.GetString("userId")
+
+	var query model.FeedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[RecommendFeed] 无效的请求参数", "error", err)
+		return
+	}
+
+	feed, err := h.recommendService.Recommend(c.Request.Context(), userID, query)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[RecommendFeed] 获取推荐视频失败", "error", err)
+		return
+	}
+
+	response.Success(c, feed)
+}
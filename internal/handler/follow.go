@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowHandler struct {
+	followService service.FollowService
+}
+
+func NewFollowHandler(followService service.FollowService) *FollowHandler {
+	if followService == nil {
+		followService = service.NewFollowService()
+	}
+	return &FollowHandler{
+		followService: followService,
+	}
+}
+
+// Follow 关注用户
+func (h *FollowHandler) Follow(c *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := c.Get("userId")
+	if !exists {
+		response.Fail(c, http.StatusUnauthorized, "用户未登录")
+		slog.Error("[Follow] 用户未登录")
+		return
+	}
+
+	targetID := c.Param("userId")
+	if targetID == "" {
+		response.Fail(c, http.StatusBadRequest, "用户ID不能为空")
+		slog.Error("[Follow] 用户ID为空")
+		return
+	}
+
+	if err := h.followService.Follow(c.Request.Context(), userID.(string), targetID); err != nil {
+		if errors.Is(err, service.ErrBlocked) || errors.Is(err, service.ErrBlockingTarget) {
+			response.Fail(c, http.StatusForbidden, err.Error())
+		} else {
+			response.Fail(c, http.StatusInternalServerError, err.Error())
+		}
+		slog.Error("[Follow] 关注失败", "error", err, "targetId", targetID)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "关注成功"})
+}
+
+// Unfollow 取消关注
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := c.Get("userId")
+	if !exists {
+		response.Fail(c, http.StatusUnauthorized, "用户未登录")
+		slog.Error("[Unfollow] 用户未登录")
+		return
+	}
+
+	targetID := c.Param("userId")
+	if targetID == "" {
+		response.Fail(c, http.StatusBadRequest, "用户ID不能为空")
+		slog.Error("[Unfollow] 用户ID为空")
+		return
+	}
+
+	if err := h.followService.Unfollow(c.Request.Context(), userID.(string), targetID); err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[Unfollow] 取消关注失败", "error", err, "targetId", targetID)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "取消关注成功"})
+}
+
+// GetFollowers 获取粉丝列表
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	userID := c.Param("userId")
+
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "12"))
+
+	followers, err := h.followService.GetFollowers(c.Request.Context(), userID, page, size)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[GetFollowers] 获取粉丝列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, followers)
+}
+
+// GetFollowing 获取关注列表
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	userID := c.Param("userId")
+
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "12"))
+
+	following, err := h.followService.GetFollowing(c.Request.Context(), userID, page, size)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[GetFollowing] 获取关注列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, following)
+}
+
+// GetRelation 获取当前用户与目标用户的关注关系
+func (h *FollowHandler) GetRelation(c *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := c.Get("userId")
+	if !exists {
+		response.Fail(c, http.StatusUnauthorized, "用户未登录")
+		slog.Error("[GetRelation] 用户未登录")
+		return
+	}
+
+	targetID := c.Param("userId")
+
+	relation, err := h.followService.GetRelation(c.Request.Context(), userID.(string), targetID)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[GetRelation] 获取关注关系失败", "error", err)
+		return
+	}
+
+	response.Success(c, relation)
+}
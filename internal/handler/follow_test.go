@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 创建一个FollowService的Mock
+type MockFollowService struct {
+	mock.Mock
+}
+
+func (m *MockFollowService) Follow(ctx context.Context, followerID, followeeID string) error {
+	args := m.Called(ctx, followerID, followeeID)
+	return args.Error(0)
+}
+
+func (m *MockFollowService) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	args := m.Called(ctx, followerID, followeeID)
+	return args.Error(0)
+}
+
+func (m *MockFollowService) GetFollowers(ctx context.Context, userID string, page, size int) (*model.FollowListResponse, error) {
+	args := m.Called(ctx, userID, page, size)
+	return args.Get(0).(*model.FollowListResponse), args.Error(1)
+}
+
+func (m *MockFollowService) GetFollowing(ctx context.Context, userID string, page, size int) (*model.FollowListResponse, error) {
+	args := m.Called(ctx, userID, page, size)
+	return args.Get(0).(*model.FollowListResponse), args.Error(1)
+}
+
+func (m *MockFollowService) GetRelation(ctx context.Context, userID, targetID string) (*model.FollowRelation, error) {
+	args := m.Called(ctx, userID, targetID)
+	return args.Get(0).(*model.FollowRelation), args.Error(1)
+}
+
+func (m *MockFollowService) GetFollowStats(ctx context.Context, userID string) (*model.FollowStats, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*model.FollowStats), args.Error(1)
+}
+
+// 设置测试环境
+func setupFollowTest() (*gin.Context, *httptest.ResponseRecorder, *MockFollowService, *FollowHandler) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	// 创建默认的请求对象，避免Context()方法返回nil
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	mockService := new(MockFollowService)
+	handler := NewFollowHandler(mockService)
+	return c, w, mockService, handler
+}
+
+// 测试关注用户
+func TestFollow(t *testing.T) {
+	c, w, mockService, handler := setupFollowTest()
+
+	// 模拟当前登录用户
+	userId := primitive.NewObjectID().Hex()
+	targetId := primitive.NewObjectID().Hex()
+	c.Set("userId", userId)
+	c.Params = []gin.Param{{Key: "userId", Value: targetId}}
+
+	// 模拟服务层响应
+	mockService.On("Follow", mock.Anything, userId, targetId).Return(nil)
+
+	// 执行测试
+	handler.Follow(c)
+
+	// 验证响应
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp response.Response
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, resp.Code)
+
+	// 验证调用
+	mockService.AssertExpectations(t)
+}
+
+// 测试重复关注
+func TestFollowAlreadyFollowed(t *testing.T) {
+	c, w, mockService, handler := setupFollowTest()
+
+	// 模拟当前登录用户
+	userId := primitive.NewObjectID().Hex()
+	targetId := primitive.NewObjectID().Hex()
+	c.Set("userId", userId)
+	c.Params = []gin.Param{{Key: "userId", Value: targetId}}
+
+	// 模拟服务层返回错误
+	mockService.On("Follow", mock.Anything, userId, targetId).Return(errors.New("已经关注过该用户"))
+
+	// 执行测试
+	handler.Follow(c)
+
+	// 验证响应
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var resp response.Response
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, resp.Code)
+	assert.Equal(t, "已经关注过该用户", resp.Msg)
+
+	// 验证调用
+	mockService.AssertExpectations(t)
+}
+
+// 测试关注已将自己拉黑的用户
+func TestFollowBlocked(t *testing.T) {
+	c, w, mockService, handler := setupFollowTest()
+
+	// 模拟当前登录用户
+	userId := primitive.NewObjectID().Hex()
+	targetId := primitive.NewObjectID().Hex()
+	c.Set("userId", userId)
+	c.Params = []gin.Param{{Key: "userId", Value: targetId}}
+
+	// 模拟对方已拉黑当前用户
+	mockService.On("Follow", mock.Anything, userId, targetId).Return(service.ErrBlocked)
+
+	// 执行测试
+	handler.Follow(c)
+
+	// 验证响应
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var resp response.Response
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Nil(t, err)
+	assert.Equal(t, service.ErrBlocked.Error(), resp.Msg)
+
+	// 验证调用
+	mockService.AssertExpectations(t)
+}
+
+// 测试获取粉丝列表
+func TestGetFollowers(t *testing.T) {
+	c, w, mockService, handler := setupFollowTest()
+
+	userId := primitive.NewObjectID().Hex()
+	followerId := primitive.NewObjectID().Hex()
+	c.Params = []gin.Param{{Key: "userId", Value: userId}}
+
+	// 模拟查询参数
+	c.Request = httptest.NewRequest("GET", "/?page=1&size=5", nil)
+
+	// 模拟服务层响应
+	followers := &model.FollowListResponse{
+		Users: []model.FollowUser{
+			{
+				UserBrief:  model.UserBrief{ID: followerId, Username: "follower"},
+				FollowedAt: time.Now(),
+				IsMutual:   true,
+			},
+		},
+		Total: 1,
+		Page:  1,
+		Size:  5,
+	}
+
+	mockService.On("GetFollowers", mock.Anything, userId, 1, 5).Return(followers, nil)
+
+	// 执行测试
+	handler.GetFollowers(c)
+
+	// 验证响应
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp response.Response
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, resp.Code)
+
+	// 验证互关标记
+	data := resp.Data.(map[string]interface{})
+	users := data["users"].([]interface{})
+	assert.Len(t, users, 1)
+	assert.Equal(t, true, users[0].(map[string]interface{})["isMutual"])
+
+	// 验证调用
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importFileLimit 导入文件大小上限
+const importFileLimit = 2 << 20
+
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+func NewImportHandler(importService service.ImportService) *ImportHandler {
+	if importService == nil {
+		importService = service.NewImportService()
+	}
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// Import 从 WebVTT、SRT 或导出的 JSON 文件导入标记和笔记
+func (h *ImportHandler) Import(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	videoID := c.Param("videoId")
+
+	var req model.ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的导入参数")
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "请选择要导入的文件")
+		return
+	}
+	if header.Size > importFileLimit {
+		response.Fail(c, http.StatusBadRequest, "导入文件不能超过2MB")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "读取导入文件失败")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, importFileLimit))
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "读取导入文件失败")
+		return
+	}
+
+	result, err := h.importService.Import(c.Request.Context(), userID.(string), videoID, header.Filename, data, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImportVideoNotFound):
+			response.Fail(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrImportVideoForbidden):
+			response.Fail(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrImportEmpty), errors.Is(err, service.ErrImportTooMany):
+			response.Fail(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Fail(c, http.StatusInternalServerError, "导入失败")
+			slog.Error("[Import] 导入标记和笔记失败", "error", err, "videoId", videoID)
+		}
+		return
+	}
+	response.Success(c, result)
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"video-platform/internal/model"
 	"video-platform/internal/service"
 	"video-platform/pkg/response"
@@ -38,20 +41,34 @@ func (h *MarkHandler) AddMark(c *gin.Context) {
 	mark.UserID = userID.(string)
 
 	if err := h.markService.AddMark(c.Request.Context(), userID.(string), &mark); err != nil {
-		response.Fail(c, http.StatusInternalServerError, "添加标记失败")
+		h.failMark(c, err, "添加标记失败")
 		return
 	}
 
 	response.Success(c, mark)
 }
 
+// MarkCategories 获取标记分类及默认颜色
+func (h *MarkHandler) MarkCategories(c *gin.Context) {
+	response.Success(c, model.MarkCategories)
+}
+
 // GetMarks 获取标记列表
 func (h *MarkHandler) GetMarks(c *gin.Context) {
 	// 从上下文获取用户ID
 	userID, _ := c.Get("userId")
-	videoID := c.Query("videoId") // 从查询参数获取视频ID
 
-	marks, err := h.markService.GetMarks(c.Request.Context(), userID.(string), videoID)
+	var query model.MarkQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的查询参数")
+		return
+	}
+	if query.End > 0 && query.End < query.Start {
+		response.Fail(c, http.StatusBadRequest, "结束时间不能早于开始时间")
+		return
+	}
+
+	marks, err := h.markService.GetMarks(c.Request.Context(), userID.(string), &query)
 	if err != nil {
 		response.Fail(c, http.StatusInternalServerError, "获取标记列表失败")
 		return
@@ -85,7 +102,7 @@ func (h *MarkHandler) UpdateMark(c *gin.Context) {
 	}
 
 	if err := h.markService.UpdateMark(c.Request.Context(), userID.(string), markID, &mark); err != nil {
-		response.Fail(c, http.StatusInternalServerError, "更新标记失败")
+		h.failMark(c, err, "更新标记失败")
 		return
 	}
 
@@ -146,7 +163,7 @@ func (h *MarkHandler) AddAnnotation(c *gin.Context) {
 	annotation.MarkID = markID
 
 	if err := h.markService.AddAnnotation(c.Request.Context(), &annotation); err != nil {
-		response.Fail(c, http.StatusInternalServerError, "添加注释失败")
+		h.failMark(c, err, "添加注释失败")
 		return
 	}
 
@@ -155,11 +172,12 @@ func (h *MarkHandler) AddAnnotation(c *gin.Context) {
 
 // GetAnnotations 获取注释列表
 func (h *MarkHandler) GetAnnotations(c *gin.Context) {
+	userID, _ := c.Get("userId")
 	markID, _ := primitive.ObjectIDFromHex(c.Param("markId"))
 
-	annotations, err := h.markService.GetAnnotations(c.Request.Context(), markID)
+	annotations, err := h.markService.GetAnnotations(c.Request.Context(), userID.(string), markID)
 	if err != nil {
-		response.Fail(c, http.StatusInternalServerError, "获取注释列表失败")
+		h.failMark(c, err, "获取注释列表失败")
 		return
 	}
 
@@ -225,16 +243,17 @@ func (h *MarkHandler) AddNote(c *gin.Context) {
 	note.UserID = userID.(string)
 
 	if err := h.markService.AddNote(c.Request.Context(), &note); err != nil {
-		response.Fail(c, http.StatusInternalServerError, "添加笔记失败")
+		h.failNote(c, err, "添加笔记失败")
 		return
 	}
 	response.Success(c, note)
 }
 
-// GetNotes 获取笔记列表
+// GetNotes 获取自己的笔记和别人分享给自己的笔记
 func (h *MarkHandler) GetNotes(c *gin.Context) {
+	userID, _ := c.Get("userId")
 	videoID := c.Query("videoId") // 从查询参数获取视频ID
-	notes, err := h.markService.GetNotes(c.Request.Context(), videoID)
+	notes, err := h.markService.GetNotes(c.Request.Context(), userID.(string), videoID)
 	if err != nil {
 		response.Fail(c, http.StatusInternalServerError, "获取笔记失败")
 		return
@@ -242,27 +261,23 @@ func (h *MarkHandler) GetNotes(c *gin.Context) {
 	response.Success(c, notes)
 }
 
-// ExportMarks 导出标记、注释和笔记
-func (h *MarkHandler) ExportMarks(c *gin.Context) {
-	videoID := c.Query("videoId") // 从查询参数获取视频ID
-	userID, _ := c.Get("userId")
-
-	marks, err := h.markService.GetMarks(c.Request.Context(), userID.(string), videoID)
-	if err != nil {
-		response.Fail(c, http.StatusInternalServerError, "获取标记失败")
-		return
-	}
+// GetCommunityNotes 获取视频下公开的社区笔记
+func (h *MarkHandler) GetCommunityNotes(c *gin.Context) {
+	videoID := c.Param("videoId")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
 
-	notes, err := h.markService.GetNotes(c.Request.Context(), videoID)
+	result, err := h.markService.GetCommunityNotes(c.Request.Context(), c.GetString("userId"), videoID, page, size)
 	if err != nil {
-		response.Fail(c, http.StatusInternalServerError, "获取笔记失败")
+		if errors.Is(err, service.ErrNoteVideoForbidden) {
+			response.Fail(c, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Fail(c, http.StatusInternalServerError, "获取社区笔记失败")
+		slog.Error("[GetCommunityNotes] 获取社区笔记失败", "error", err, "videoId", videoID)
 		return
 	}
-
-	response.Success(c, gin.H{
-		"marks": marks,
-		"notes": notes,
-	})
+	response.Success(c, result)
 }
 
 // UpdateNote 更新笔记
@@ -277,7 +292,7 @@ func (h *MarkHandler) UpdateNote(c *gin.Context) {
 	}
 
 	if err := h.markService.UpdateNote(c.Request.Context(), userID.(string), noteID, &note); err != nil {
-		response.Fail(c, http.StatusInternalServerError, "更新笔记失败")
+		h.failNote(c, err, "更新笔记失败")
 		return
 	}
 
@@ -296,3 +311,105 @@ func (h *MarkHandler) DeleteNote(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// failMark 标记参数无效返回400，标记不存在返回404，无权访问或被对方拉黑返回403，其他错误返回 message
+func (h *MarkHandler) failMark(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMark), errors.Is(err, service.ErrInvalidRoom):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrMarkNotFound), errors.Is(err, service.ErrRoomNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrMarkForbidden), errors.Is(err, service.ErrRoomForbidden),
+		errors.Is(err, service.ErrBlocked), errors.Is(err, service.ErrBlockingTarget):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ListNoteRevisions 获取笔记的历史版本列表
+func (h *MarkHandler) ListNoteRevisions(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	noteID, _ := primitive.ObjectIDFromHex(c.Param("noteId"))
+
+	revisions, err := h.markService.ListNoteRevisions(c.Request.Context(), userID.(string), noteID)
+	if err != nil {
+		h.failNote(c, err, "获取笔记历史版本失败")
+		return
+	}
+	response.Success(c, revisions)
+}
+
+// GetNoteRevision 获取笔记的某个历史版本
+func (h *MarkHandler) GetNoteRevision(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	noteID, _ := primitive.ObjectIDFromHex(c.Param("noteId"))
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的版本号")
+		return
+	}
+
+	revision, err := h.markService.GetNoteRevision(c.Request.Context(), userID.(string), noteID, version)
+	if err != nil {
+		h.failNote(c, err, "获取笔记历史版本失败")
+		return
+	}
+	response.Success(c, revision)
+}
+
+// RestoreNoteRevision 把笔记恢复到某个历史版本
+func (h *MarkHandler) RestoreNoteRevision(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	noteID, _ := primitive.ObjectIDFromHex(c.Param("noteId"))
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的版本号")
+		return
+	}
+
+	note, err := h.markService.RestoreNoteRevision(c.Request.Context(), userID.(string), noteID, version)
+	if err != nil {
+		h.failNote(c, err, "恢复笔记失败")
+		return
+	}
+	response.Success(c, note)
+}
+
+// UploadNoteImage 上传笔记图片，返回的地址可插入笔记的Markdown内容
+func (h *MarkHandler) UploadNoteImage(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "请选择要上传的图片")
+		return
+	}
+
+	image, err := h.markService.UploadNoteImage(c.Request.Context(), userID.(string), file)
+	if err != nil {
+		h.failNote(c, err, "上传图片失败")
+		return
+	}
+	response.Success(c, image)
+}
+
+// failNote 参数无效返回400，无权操作返回403，笔记或版本不存在返回404，并发修改返回409，其他错误返回 message
+func (h *MarkHandler) failNote(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidNoteVisibility), errors.Is(err, service.ErrNoteTooLong),
+		errors.Is(err, service.ErrNoteVersionRequired),
+		errors.Is(err, service.ErrNoteImageTooLarge), errors.Is(err, service.ErrNoteImageFormat),
+		errors.Is(err, service.ErrInvalidRoom):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNoteNotFound), errors.Is(err, service.ErrNoteRevisionNotFound),
+		errors.Is(err, service.ErrRoomNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrNoteForbidden), errors.Is(err, service.ErrRoomForbidden):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrNoteConflict):
+		response.Fail(c, http.StatusConflict, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, message)
+		slog.Error("[MarkHandler] "+message, "error", err)
+	}
+}
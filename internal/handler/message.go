@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+	"video-platform/pkg/ws"
+
+	"github.com/gin-gonic/gin"
+)
+
+// imFrameTimeout 处理单个WebSocket消息帧的超时时间
+const imFrameTimeout = 10 * time.Second
+
+// imFrame 客户端通过WebSocket发送的消息帧
+type imFrame struct {
+	Type string          `json:"type"` // send/ack/read/sync
+	Data json.RawMessage `json:"data"`
+}
+
+// imSyncRequest 客户端补齐缺失消息的请求
+type imSyncRequest struct {
+	ConversationID string `json:"conversationId"`
+	model.MessageQuery
+}
+
+type MessageHandler struct {
+	messageService service.MessageService
+}
+
+func NewMessageHandler(messageService service.MessageService) *MessageHandler {
+	if messageService == nil {
+		messageService = service.NewMessageService(nil)
+	}
+	return &MessageHandler{
+		messageService: messageService,
+	}
+}
+
+// ListConversations 获取会话列表
+func (h *MessageHandler) ListConversations(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	items, err := h.messageService.ListConversations(c.Request.Context(), userID.(string), page, size)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[ListConversations] 获取会话列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, items)
+}
+
+// OpenConversation 打开与某个用户的会话
+func (h *MessageHandler) OpenConversation(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var req model.OpenConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[OpenConversation] 无效的请求参数", "error", err)
+		return
+	}
+
+	conv, err := h.messageService.GetOrCreateConversation(c.Request.Context(), userID.(string), req.UserID)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[OpenConversation] 打开会话失败", "error", err, "peerId", req.UserID)
+		return
+	}
+
+	response.Success(c, conv)
+}
+
+// Send 发送私信（WebSocket不可用时的备用接口）
+func (h *MessageHandler) Send(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var req model.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[SendMessage] 无效的请求参数", "error", err)
+		return
+	}
+
+	msg, err := h.messageService.Send(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[SendMessage] 发送私信失败", "error", err)
+		return
+	}
+
+	response.Success(c, msg)
+}
+
+// ListMessages 获取会话消息
+func (h *MessageHandler) ListMessages(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	conversationID := c.Param("conversationId")
+
+	var query model.MessageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[ListMessages] 无效的请求参数", "error", err)
+		return
+	}
+
+	messages, err := h.messageService.ListMessages(c.Request.Context(), userID.(string), conversationID, query)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListMessages] 获取消息失败", "error", err, "conversationId", conversationID)
+		return
+	}
+
+	response.Success(c, messages)
+}
+
+// MarkRead 标记会话消息已读
+func (h *MessageHandler) MarkRead(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	conversationID := c.Param("conversationId")
+
+	var req model.MessageAckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[MarkMessagesRead] 无效的请求参数", "error", err)
+		return
+	}
+
+	if err := h.messageService.MarkRead(c.Request.Context(), userID.(string), conversationID, req.Seq); err != nil {
+		h.fail(c, err)
+		slog.Error("[MarkMessagesRead] 标记已读失败", "error", err, "conversationId", conversationID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Gateway 私信WebSocket网关：推送新消息和回执，接收发送、回执和同步请求
+func (h *MessageHandler) Gateway(c *gin.Context) {
+	userID := c.GetString("userId")
+
+	conn, err := ws.Upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade失败时已经写入了错误响应
+		slog.Error("[IMGateway] WebSocket升级失败", "error", err, "userId", userID)
+		return
+	}
+
+	client := ws.NewClient(conn)
+	h.messageService.Subscribe(userID, client)
+	defer h.messageService.Unsubscribe(userID, client)
+
+	// 补发离线期间未送达的消息，客户端收到后回复ack
+	offline, err := h.messageService.SyncOffline(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("[IMGateway] 同步离线消息失败", "error", err, "userId", userID)
+	}
+	client.Send(ws.Message{Type: "sync", Data: offline})
+
+	client.Run(func(payload []byte) {
+		h.handleFrame(userID, client, payload)
+	})
+}
+
+// handleFrame 处理客户端发送的消息帧
+func (h *MessageHandler) handleFrame(userID string, client *ws.Client, payload []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), imFrameTimeout)
+	defer cancel()
+
+	var frame imFrame
+	if err := json.Unmarshal(payload, &frame); err != nil {
+		client.Send(imError("", "无效的消息格式"))
+		return
+	}
+
+	switch frame.Type {
+	case "send":
+		var req model.SendMessageRequest
+		if err := json.Unmarshal(frame.Data, &req); err != nil || req.ToUserID == "" {
+			client.Send(imError(req.ClientMsgID, "无效的消息"))
+			return
+		}
+		msg, err := h.messageService.Send(ctx, userID, &req)
+		if err != nil {
+			client.Send(imError(req.ClientMsgID, err.Error()))
+			return
+		}
+		client.Send(ws.Message{Type: "sent", Data: gin.H{"clientMsgId": req.ClientMsgID, "message": msg}})
+
+	case "ack", "read":
+		var req model.MessageAckRequest
+		if err := json.Unmarshal(frame.Data, &req); err != nil {
+			client.Send(imError("", "无效的回执"))
+			return
+		}
+		var err error
+		if frame.Type == "read" {
+			err = h.messageService.MarkRead(ctx, userID, req.ConversationID, req.Seq)
+		} else {
+			err = h.messageService.MarkDelivered(ctx, userID, req.ConversationID, req.Seq)
+		}
+		if err != nil {
+			client.Send(imError("", err.Error()))
+		}
+
+	case "sync":
+		var req imSyncRequest
+		if err := json.Unmarshal(frame.Data, &req); err != nil {
+			client.Send(imError("", "无效的同步请求"))
+			return
+		}
+		messages, err := h.messageService.ListMessages(ctx, userID, req.ConversationID, req.MessageQuery)
+		if err != nil {
+			client.Send(imError("", err.Error()))
+			return
+		}
+		client.Send(ws.Message{Type: "messages", Data: gin.H{"conversationId": req.ConversationID, "messages": messages}})
+
+	default:
+		client.Send(imError("", "不支持的消息类型"))
+	}
+}
+
+// imError 构造推送给客户端的错误消息
+func imError(clientMsgID, message string) ws.Message {
+	return ws.Message{Type: "error", Data: gin.H{"clientMsgId": clientMsgID, "message": message}}
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *MessageHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrConversationNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrBlocked), errors.Is(err, service.ErrBlockingTarget):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidMessage), errors.Is(err, service.ErrInvalidPeer):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
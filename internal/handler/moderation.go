@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	moderationService service.ModerationService
+}
+
+func NewModerationHandler(moderationService service.ModerationService) *ModerationHandler {
+	if moderationService == nil {
+		moderationService = service.NewModerationService()
+	}
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// Reasons 获取举报原因分类
+func (h *ModerationHandler) Reasons(c *gin.Context) {
+	response.Success(c, model.ReportReasons)
+}
+
+// Report 举报视频、评论、用户或标记
+func (h *ModerationHandler) Report(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var req model.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[Report] 无效的请求参数", "error", err)
+		return
+	}
+
+	report, err := h.moderationService.Report(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[Report] 举报失败", "error", err, "targetType", req.TargetType, "targetId", req.TargetID)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// ListCases 获取审核队列
+func (h *ModerationHandler) ListCases(c *gin.Context) {
+	var query model.ModerationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的查询参数")
+		slog.Error("[ListModerationCases] 无效的查询参数", "error", err)
+		return
+	}
+
+	result, err := h.moderationService.ListCases(c.Request.Context(), query)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListModerationCases] 获取审核队列失败", "error", err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetCase 获取审核工单详情
+func (h *ModerationHandler) GetCase(c *gin.Context) {
+	caseID := c.Param("caseId")
+
+	detail, err := h.moderationService.GetCase(c.Request.Context(), caseID)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[GetModerationCase] 获取审核工单失败", "error", err, "caseId", caseID)
+		return
+	}
+
+	response.Success(c, detail)
+}
+
+// Claim 领取审核工单
+func (h *ModerationHandler) Claim(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	caseID := c.Param("caseId")
+
+	mc, err := h.moderationService.Claim(c.Request.Context(), userID.(string), caseID)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ClaimModerationCase] 领取审核工单失败", "error", err, "caseId", caseID)
+		return
+	}
+
+	response.Success(c, mc)
+}
+
+// Resolve 确认违规并处理
+func (h *ModerationHandler) Resolve(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	caseID := c.Param("caseId")
+
+	var req model.ResolveCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[ResolveModerationCase] 无效的请求参数", "error", err)
+		return
+	}
+
+	mc, err := h.moderationService.Resolve(c.Request.Context(), userID.(string), caseID, &req)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ResolveModerationCase] 处理审核工单失败", "error", err, "caseId", caseID)
+		return
+	}
+
+	response.Success(c, mc)
+}
+
+// Dismiss 驳回举报
+func (h *ModerationHandler) Dismiss(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	caseID := c.Param("caseId")
+
+	// 驳回说明可选
+	var req model.DismissCaseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+			slog.Error("[DismissModerationCase] 无效的请求参数", "error", err)
+			return
+		}
+	}
+
+	mc, err := h.moderationService.Dismiss(c.Request.Context(), userID.(string), caseID, req.Note)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[DismissModerationCase] 驳回举报失败", "error", err, "caseId", caseID)
+		return
+	}
+
+	response.Success(c, mc)
+}
+
+// ListLogs 获取审核记录
+func (h *ModerationHandler) ListLogs(c *gin.Context) {
+	var query model.ModerationLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的查询参数")
+		slog.Error("[ListModerationLogs] 无效的查询参数", "error", err)
+		return
+	}
+
+	result, err := h.moderationService.ListLogs(c.Request.Context(), query)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListModerationLogs] 获取审核记录失败", "error", err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *ModerationHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrModerationCaseNotFound), errors.Is(err, service.ErrReportTargetNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrAlreadyReported), errors.Is(err, service.ErrModerationCaseClaimed),
+		errors.Is(err, service.ErrModerationCaseClosed):
+		response.Fail(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrModerationCaseNotClaimed):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidReport):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
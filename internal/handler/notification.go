@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+	"video-platform/pkg/ws"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	if notificationService == nil {
+		notificationService = service.NewNotificationService(nil)
+	}
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// List 获取通知列表
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var query model.NotificationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[ListNotifications] 无效的请求参数", "error", err)
+		return
+	}
+
+	result, err := h.notificationService.List(c.Request.Context(), userID.(string), query)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[ListNotifications] 获取通知列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// UnreadCount 获取未读通知数
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	count, err := h.notificationService.UnreadCount(c.Request.Context(), userID.(string))
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[UnreadNotificationCount] 获取未读通知数失败", "error", err)
+		return
+	}
+
+	response.Success(c, gin.H{"unread": count})
+}
+
+// MarkRead 标记通知为已读
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var req model.MarkNotificationsReadRequest
+	// 请求体为空时全部标记为已读
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+			slog.Error("[MarkNotificationsRead] 无效的请求参数", "error", err)
+			return
+		}
+	}
+
+	updated, err := h.notificationService.MarkRead(c.Request.Context(), userID.(string), req.IDs)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, err.Error())
+		slog.Error("[MarkNotificationsRead] 标记已读失败", "error", err)
+		return
+	}
+
+	response.Success(c, gin.H{"updated": updated})
+}
+
+// Delete 删除通知
+func (h *NotificationHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	notificationID := c.Param("notificationId")
+
+	if err := h.notificationService.Delete(c.Request.Context(), userID.(string), notificationID); err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			response.Fail(c, http.StatusNotFound, err.Error())
+		} else {
+			response.Fail(c, http.StatusInternalServerError, err.Error())
+		}
+		slog.Error("[DeleteNotification] 删除通知失败", "error", err, "notificationId", notificationID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Live 实时通知推送（WebSocket）
+func (h *NotificationHandler) Live(c *gin.Context) {
+	userID := c.GetString("userId")
+
+	conn, err := ws.Upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade失败时已经写入了错误响应
+		slog.Error("[LiveNotifications] WebSocket升级失败", "error", err, "userId", userID)
+		return
+	}
+
+	client := ws.NewClient(conn)
+	h.notificationService.Subscribe(userID, client)
+	defer h.notificationService.Unsubscribe(userID, client)
+
+	// 连接建立后先推送一次未读数，之后只推送新通知
+	if count, err := h.notificationService.UnreadCount(c.Request.Context(), userID); err == nil {
+		client.Send(ws.Message{Type: "unread", Data: gin.H{"unread": count}})
+	}
+
+	client.Run(nil)
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PlaylistHandler struct {
+	playlistService service.PlaylistService
+}
+
+func NewPlaylistHandler(playlistService service.PlaylistService) *PlaylistHandler {
+	if playlistService == nil {
+		playlistService = service.NewPlaylistService()
+	}
+	return &PlaylistHandler{
+		playlistService: playlistService,
+	}
+}
+
+// Create 创建播放列表
+func (h *PlaylistHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var req model.CreatePlaylistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[CreatePlaylist] 无效的请求参数", "error", err)
+		return
+	}
+
+	playlist, err := h.playlistService.Create(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[CreatePlaylist] 创建播放列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, playlist)
+}
+
+// Update 更新播放列表信息
+func (h *PlaylistHandler) Update(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	playlistID := c.Param("playlistId")
+
+	var req model.UpdatePlaylistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[UpdatePlaylist] 无效的请求参数", "error", err)
+		return
+	}
+
+	playlist, err := h.playlistService.Update(c.Request.Context(), userID.(string), playlistID, &req)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[UpdatePlaylist] 更新播放列表失败", "error", err, "playlistId", playlistID)
+		return
+	}
+
+	response.Success(c, playlist)
+}
+
+// Delete 删除播放列表
+func (h *PlaylistHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	playlistID := c.Param("playlistId")
+
+	if err := h.playlistService.Delete(c.Request.Context(), userID.(string), playlistID); err != nil {
+		h.fail(c, err)
+		slog.Error("[DeletePlaylist] 删除播放列表失败", "error", err, "playlistId", playlistID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Get 获取播放列表详情
+func (h *PlaylistHandler) Get(c *gin.Context) {
+	userID := c.GetString("userId")
+	playlistID := c.Param("playlistId")
+
+	playlist, err := h.playlistService.Get(c.Request.Context(), userID, playlistID)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[GetPlaylist] 获取播放列表失败", "error", err, "playlistId", playlistID)
+		return
+	}
+
+	response.Success(c, playlist)
+}
+
+// ListByUser 获取用户创建的播放列表
+func (h *PlaylistHandler) ListByUser(c *gin.Context) {
+	viewerID := c.GetString("userId")
+	ownerID := c.Param("userId")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	playlists, err := h.playlistService.ListByUser(c.Request.Context(), viewerID, ownerID, page, size)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListPlaylists] 获取播放列表失败", "error", err, "userId", ownerID)
+		return
+	}
+
+	response.Success(c, playlists)
+}
+
+// AddItem 向播放列表添加视频
+func (h *PlaylistHandler) AddItem(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	playlistID := c.Param("playlistId")
+
+	var req model.AddPlaylistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[AddPlaylistItem] 无效的请求参数", "error", err)
+		return
+	}
+
+	item, err := h.playlistService.AddItem(c.Request.Context(), userID.(string), playlistID, req.VideoID)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[AddPlaylistItem] 添加视频失败", "error", err, "playlistId", playlistID, "videoId", req.VideoID)
+		return
+	}
+
+	response.Success(c, item)
+}
+
+// RemoveItem 从播放列表移除视频
+func (h *PlaylistHandler) RemoveItem(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	playlistID := c.Param("playlistId")
+	videoID := c.Param("videoId")
+
+	if err := h.playlistService.RemoveItem(c.Request.Context(), userID.(string), playlistID, videoID); err != nil {
+		h.fail(c, err)
+		slog.Error("[RemovePlaylistItem] 移除视频失败", "error", err, "playlistId", playlistID, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Reorder 调整播放列表中视频的顺序
+func (h *PlaylistHandler) Reorder(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	playlistID := c.Param("playlistId")
+
+	var req model.ReorderPlaylistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[ReorderPlaylist] 无效的请求参数", "error", err)
+		return
+	}
+
+	if err := h.playlistService.Reorder(c.Request.Context(), userID.(string), playlistID, req.VideoIDs); err != nil {
+		h.fail(c, err)
+		slog.Error("[ReorderPlaylist] 调整顺序失败", "error", err, "playlistId", playlistID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *PlaylistHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPlaylistNotFound), errors.Is(err, service.ErrPlaylistItemNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrPlaylistForbidden):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrPlaylistItemExists):
+		response.Fail(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrPlaylistFull), errors.Is(err, service.ErrInvalidPlaylistOrder):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
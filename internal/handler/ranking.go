@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RankingHandler struct {
+	rankingService service.RankingService
+}
+
+func NewRankingHandler(rankingService service.RankingService) *RankingHandler {
+	if rankingService == nil {
+		rankingService = service.NewRankingService()
+	}
+	return &RankingHandler{
+		rankingService: rankingService,
+	}
+}
+
+// Get 获取热门排行榜（小时榜/日榜/周榜，可按标签筛选）
+func (h *RankingHandler) Get(c *gin.Context) {
+	userID := c.GetString("userId")
+	period := c.Param("period")
+
+	var query model.RankingQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[GetRanking] 无效的请求参数", "error", err)
+		return
+	}
+
+	ranking, err := h.rankingService.Get(c.Request.Context(), userID, period, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRankingPeriod):
+			response.Fail(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrRankingUnavailable):
+			response.Fail(c, http.StatusServiceUnavailable, err.Error())
+		default:
+			response.Fail(c, http.StatusInternalServerError, err.Error())
+		}
+		slog.Error("[GetRanking] 获取排行榜失败", "error", err, "period", period, "tag", query.Tag)
+		return
+	}
+
+	response.Success(c, ranking)
+}
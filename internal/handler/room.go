@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+	"video-platform/pkg/ws"
+
+	"github.com/gin-gonic/gin"
+)
+
+// roomFrameTimeout 处理单个房间消息帧的超时时间
+const roomFrameTimeout = 5 * time.Second
+
+// roomFrame 客户端通过WebSocket发送的房间消息帧
+type roomFrame struct {
+	Type string          `json:"type"` // presence
+	Data json.RawMessage `json:"data"`
+}
+
+// roomPresenceRequest 客户端上报的播放位置
+type roomPresenceRequest struct {
+	Timestamp float64 `json:"timestamp"`
+	Playing   bool    `json:"playing"`
+}
+
+type RoomHandler struct {
+	roomService service.RoomService
+}
+
+func NewRoomHandler(roomService service.RoomService) *RoomHandler {
+	if roomService == nil {
+		roomService = service.NewRoomService(nil)
+	}
+	return &RoomHandler{
+		roomService: roomService,
+	}
+}
+
+// Create 创建协作标注房间
+func (h *RoomHandler) Create(c *gin.Context) {
+	userID := c.GetString("userId")
+
+	var req model.CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[CreateRoom] 无效的请求参数", "error", err)
+		return
+	}
+
+	room, err := h.roomService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[CreateRoom] 创建房间失败", "error", err, "videoId", req.VideoID)
+		return
+	}
+	response.Success(c, room)
+}
+
+// List 获取自己加入的房间
+func (h *RoomHandler) List(c *gin.Context) {
+	userID := c.GetString("userId")
+
+	rooms, err := h.roomService.List(c.Request.Context(), userID, c.Query("videoId"))
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListRooms] 获取房间列表失败", "error", err)
+		return
+	}
+	response.Success(c, rooms)
+}
+
+// Get 获取房间详情
+func (h *RoomHandler) Get(c *gin.Context) {
+	userID := c.GetString("userId")
+	roomID := c.Param("roomId")
+
+	room, err := h.roomService.Get(c.Request.Context(), userID, roomID)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[GetRoom] 获取房间详情失败", "error", err, "roomId", roomID)
+		return
+	}
+	response.Success(c, room)
+}
+
+// AddMembers 邀请成员
+func (h *RoomHandler) AddMembers(c *gin.Context) {
+	userID := c.GetString("userId")
+	roomID := c.Param("roomId")
+
+	var req model.RoomMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[AddRoomMembers] 无效的请求参数", "error", err)
+		return
+	}
+
+	room, err := h.roomService.AddMembers(c.Request.Context(), userID, roomID, req.UserIDs)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[AddRoomMembers] 邀请成员失败", "error", err, "roomId", roomID)
+		return
+	}
+	response.Success(c, room)
+}
+
+// RemoveMember 移除成员或退出房间
+func (h *RoomHandler) RemoveMember(c *gin.Context) {
+	userID := c.GetString("userId")
+	roomID := c.Param("roomId")
+
+	if err := h.roomService.RemoveMember(c.Request.Context(), userID, roomID, c.Param("userId")); err != nil {
+		h.fail(c, err)
+		slog.Error("[RemoveRoomMember] 移除成员失败", "error", err, "roomId", roomID)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Delete 删除房间
+func (h *RoomHandler) Delete(c *gin.Context) {
+	userID := c.GetString("userId")
+	roomID := c.Param("roomId")
+
+	if err := h.roomService.Delete(c.Request.Context(), userID, roomID); err != nil {
+		h.fail(c, err)
+		slog.Error("[DeleteRoom] 删除房间失败", "error", err, "roomId", roomID)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Live 房间实时推送（WebSocket）：推送成员的标记、笔记变化和在线状态，接收成员上报的播放位置
+func (h *RoomHandler) Live(c *gin.Context) {
+	userID := c.GetString("userId")
+	roomID := c.Param("roomId")
+
+	conn, err := ws.Upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade失败时已经写入了错误响应
+		slog.Error("[LiveRoom] WebSocket升级失败", "error", err, "roomId", roomID)
+		return
+	}
+
+	client := ws.NewClient(conn)
+	presence, err := h.roomService.Join(c.Request.Context(), userID, roomID, client)
+	if err != nil {
+		// 连接已升级，通过消息告知原因后关闭
+		client.Send(ws.Message{Type: "error", Data: gin.H{"message": err.Error()}})
+		client.Close()
+		client.Run(nil)
+		return
+	}
+	defer h.roomService.Leave(context.Background(), userID, roomID, client)
+
+	client.Send(ws.Message{Type: "sync", Data: gin.H{"presence": presence}})
+	client.Run(func(payload []byte) {
+		h.handleFrame(userID, roomID, client, payload)
+	})
+}
+
+// handleFrame 处理客户端发送的房间消息帧
+func (h *RoomHandler) handleFrame(userID, roomID string, client *ws.Client, payload []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), roomFrameTimeout)
+	defer cancel()
+
+	var frame roomFrame
+	if err := json.Unmarshal(payload, &frame); err != nil {
+		client.Send(ws.Message{Type: "error", Data: gin.H{"message": "无效的消息格式"}})
+		return
+	}
+
+	switch frame.Type {
+	case "presence":
+		var req roomPresenceRequest
+		if err := json.Unmarshal(frame.Data, &req); err != nil {
+			client.Send(ws.Message{Type: "error", Data: gin.H{"message": "无效的播放位置"}})
+			return
+		}
+		if err := h.roomService.UpdatePresence(ctx, userID, roomID, req.Timestamp, req.Playing); err != nil {
+			client.Send(ws.Message{Type: "error", Data: gin.H{"message": err.Error()}})
+			// 已被移出房间或房间已删除时关闭连接
+			if errors.Is(err, service.ErrRoomForbidden) || errors.Is(err, service.ErrRoomNotFound) {
+				client.Close()
+			}
+		}
+
+	default:
+		client.Send(ws.Message{Type: "error", Data: gin.H{"message": "不支持的消息类型"}})
+	}
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *RoomHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoomNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrRoomForbidden), errors.Is(err, service.ErrBlocked), errors.Is(err, service.ErrBlockingTarget):
+		response.Fail(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidRoom):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"video-platform/config"
 	"video-platform/internal/middleware"
 	"video-platform/internal/service"
+	"video-platform/pkg/ws"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,12 +26,52 @@ func InitRoutes(r *gin.Engine) {
 		userService := service.NewUserService()
 		markService := service.NewMarkService()
 		videoService := service.NewVideoService()
+		followService := service.NewFollowService()
+		commentService := service.NewCommentService()
+		danmakuService := service.NewDanmakuService(ws.NewHub())
+		shareService := service.NewShareService()
+		notificationService := service.NewNotificationService(nil)
+		messageService := service.NewMessageService(nil)
+		feedService := service.NewFeedService()
+		recommendService := service.NewRecommendService()
+		rankingService := service.NewRankingService()
+		playlistService := service.NewPlaylistService()
+		favoriteFolderService := service.NewFavoriteFolderService()
+		watchLaterService := service.NewWatchLaterService()
+		blockService := service.NewBlockService()
+		moderationService := service.NewModerationService()
+		exportService := service.NewExportService(markService)
+		importService := service.NewImportService()
+		searchService := service.NewSearchService()
+		roomService := service.NewRoomService(nil)
+		chapterService := service.NewChapterService()
 		// codeService := service.NewCodeSerivce(nil)
 
 		// 创建 handler 实例
 		userHandler := NewUserHandler(userService)
 		markHandler := NewMarkHandler(markService)
 		videoHandler := NewVideoHandler(videoService)
+		followHandler := NewFollowHandler(followService)
+		commentHandler := NewCommentHandler(commentService)
+		danmakuHandler := NewDanmakuHandler(danmakuService)
+		shareHandler := NewShareHandler(shareService)
+		notificationHandler := NewNotificationHandler(notificationService)
+		messageHandler := NewMessageHandler(messageService)
+		feedHandler := NewFeedHandler(feedService, recommendService)
+		rankingHandler := NewRankingHandler(rankingService)
+		playlistHandler := NewPlaylistHandler(playlistService)
+		favoriteFolderHandler := NewFavoriteFolderHandler(favoriteFolderService)
+		watchLaterHandler := NewWatchLaterHandler(watchLaterService)
+		blockHandler := NewBlockHandler(blockService)
+		moderationHandler := NewModerationHandler(moderationService)
+		exportHandler := NewExportHandler(exportService)
+		importHandler := NewImportHandler(importService)
+		searchHandler := NewSearchHandler(searchService)
+		roomHandler := NewRoomHandler(roomService)
+		chapterHandler := NewChapterHandler(chapterService)
+
+		// 分享短链接跳转
+		r.GET("/s/:code", shareHandler.Redirect)
 
 		// 用户相关路由（无需认证）
 		users := v1.Group("/users")
@@ -43,19 +84,62 @@ func InitRoutes(r *gin.Engine) {
 			users.GET("/:userId/favorites", middleware.Auth(), userHandler.GetFavorites)
 			users.POST("/send_sms_code", userHandler.SendSMSCode)
 			users.POST("/login/sms", userHandler.LoginBySms)
+			users.POST("/:userId/follow", middleware.Auth(), followHandler.Follow)              // 关注用户
+			users.DELETE("/:userId/follow", middleware.Auth(), followHandler.Unfollow)          // 取消关注
+			users.GET("/:userId/followers", followHandler.GetFollowers)                         // 粉丝列表
+			users.GET("/:userId/following", followHandler.GetFollowing)                         // 关注列表
+			users.GET("/:userId/relation", middleware.Auth(), followHandler.GetRelation)        // 关注关系
+			users.GET("/:userId/playlists", middleware.SetUserId(), playlistHandler.ListByUser) // 播放列表
+			users.POST("/:userId/block", middleware.Auth(), blockHandler.Block)                 // 拉黑用户
+			users.DELETE("/:userId/block", middleware.Auth(), blockHandler.Unblock)             // 取消拉黑
+			users.POST("/:userId/mute", middleware.Auth(), blockHandler.Mute)                   // 静音用户
+			users.DELETE("/:userId/mute", middleware.Auth(), blockHandler.Unmute)               // 取消静音
 		}
 
 		// 公开接口（无需认证）
 		videos := v1.Group("/videos")
 		{
 			videos.GET("/public", videoHandler.GetPublicVideoList)                                  // 获取公开视频列表
-			videos.GET("/:videoId/stream", videoHandler.Stream)                                     // 视频流式播放
+			videos.GET("/:videoId/stream", middleware.SetUserId(), videoHandler.Stream)             // 视频流式播放
 			videos.GET("/:videoId", middleware.SetUserId(), videoHandler.GetByID)                   // 获取视频详情
 			videos.POST("/:videoId/favorite", middleware.Auth(), userHandler.AddToFavorites)        // 添加收藏
 			videos.DELETE("/:videoId/favorite", middleware.Auth(), userHandler.RemoveFromFavorites) // 取消收藏
+			videos.PUT("/:videoId/favorite", middleware.Auth(), favoriteFolderHandler.MoveFavorite) // 移动到其他收藏夹
 			videos.POST("/:videoId/watch", middleware.Auth(), userHandler.RecordWatchHistory)       // 记录观看历史
+			videos.POST("/:videoId/like", middleware.Auth(), userHandler.LikeVideo)                 // 点赞
+			videos.DELETE("/:videoId/like", middleware.Auth(), userHandler.UnlikeVideo)             // 取消点赞
+			videos.GET("/:videoId/comments", middleware.SetUserId(), commentHandler.List)           // 获取评论列表
+			videos.POST("/:videoId/comments", middleware.Auth(), commentHandler.Create)             // 发表评论
+			videos.GET("/:videoId/danmaku", middleware.SetUserId(), danmakuHandler.List)            // 获取时间窗口内的弹幕
+			videos.POST("/:videoId/danmaku", middleware.Auth(), danmakuHandler.Send)                // 发送弹幕
+			videos.GET("/:videoId/danmaku/live", middleware.SetUserId(), danmakuHandler.Live)       // 实时弹幕（WebSocket）
+			videos.POST("/:videoId/share", middleware.SetUserId(), shareHandler.Create)             // 创建分享链接
+			videos.GET("/:videoId/notes", middleware.SetUserId(), markHandler.GetCommunityNotes)    // 社区笔记
+			videos.GET("/:videoId/chapters", middleware.SetUserId(), chapterHandler.Get)            // 章节（?format=vtt|mp4 下载章节文件）
+		}
+
+		// 播放列表详情（私有列表仅创建者可见）
+		v1.GET("/playlists/:playlistId", middleware.SetUserId(), playlistHandler.Get)
+
+		// 评论相关路由
+		comments := v1.Group("/comments")
+		{
+			comments.GET("/:commentId/replies", middleware.SetUserId(), commentHandler.ListReplies) // 获取回复列表
+			comments.PUT("/:commentId", middleware.Auth(), commentHandler.Update)                   // 编辑评论
+			comments.DELETE("/:commentId", middleware.Auth(), commentHandler.Delete)                // 删除评论
+			comments.POST("/:commentId/like", middleware.Auth(), commentHandler.Like)               // 点赞评论
+			comments.DELETE("/:commentId/like", middleware.Auth(), commentHandler.Unlike)           // 取消点赞
 		}
 
+		// 推荐视频（登录用户个性化推荐，未登录返回热门）
+		v1.GET("/feed/recommend", middleware.SetUserId(), feedHandler.Recommend)
+
+		// 热门排行榜（hourly/daily/weekly）
+		v1.GET("/rankings/:period", middleware.SetUserId(), rankingHandler.Get)
+
+		// 举报原因分类
+		v1.GET("/reports/reasons", moderationHandler.Reasons)
+
 		// 需要认证的路由
 		auth := v1.Group("")
 		auth.Use(middleware.Auth())
@@ -72,11 +156,15 @@ func InitRoutes(r *gin.Engine) {
 				authVideos.POST("/batch", videoHandler.BatchOperation)               // 批量操作
 				authVideos.POST("/:videoId/thumbnail", videoHandler.UpdateThumbnail) // 更新缩略图
 				authVideos.GET("/:videoId/stats", videoHandler.GetStats)             // 获取统计信息
+				authVideos.GET("/:videoId/share-stats", shareHandler.GetStats)       // 获取分享渠道统计
+				authVideos.PUT("/:videoId/chapters", chapterHandler.Set)             // 设置章节
 			}
 
 			// 标记相关路由
 			marks := auth.Group("/marks")
 			{
+				marks.GET("/categories", markHandler.MarkCategories)                     // 标记分类
+				marks.GET("/search", searchHandler.Search)                               // 搜索标记、注释和笔记
 				marks.POST("", markHandler.AddMark)                                      // 添加标记
 				marks.GET("", markHandler.GetMarks)                                      // 获取标记列表
 				marks.PUT("/:markId", markHandler.UpdateMark)                            // 更新标记
@@ -90,14 +178,105 @@ func InitRoutes(r *gin.Engine) {
 			// 笔记相关路由
 			notes := auth.Group("/notes")
 			{
-				notes.POST("", markHandler.AddNote)              // 添加笔记
-				notes.GET("", markHandler.GetNotes)              // 获取笔记列表
-				notes.PUT("/:noteId", markHandler.UpdateNote)    // 更新笔记
-				notes.DELETE("/:noteId", markHandler.DeleteNote) // 删除笔记
+				notes.POST("", markHandler.AddNote)                                                // 添加笔记
+				notes.GET("", markHandler.GetNotes)                                                // 获取笔记列表
+				notes.POST("/images", markHandler.UploadNoteImage)                                 // 上传笔记图片
+				notes.PUT("/:noteId", markHandler.UpdateNote)                                      // 更新笔记
+				notes.DELETE("/:noteId", markHandler.DeleteNote)                                   // 删除笔记
+				notes.GET("/:noteId/revisions", markHandler.ListNoteRevisions)                     // 笔记历史版本
+				notes.GET("/:noteId/revisions/:version", markHandler.GetNoteRevision)              // 查看历史版本
+				notes.POST("/:noteId/revisions/:version/restore", markHandler.RestoreNoteRevision) // 恢复到历史版本
+			}
+
+			// 协作标注房间相关路由
+			rooms := auth.Group("/rooms")
+			{
+				rooms.POST("", roomHandler.Create)                                 // 创建房间
+				rooms.GET("", roomHandler.List)                                    // 获取加入的房间
+				rooms.GET("/:roomId", roomHandler.Get)                             // 房间详情
+				rooms.DELETE("/:roomId", roomHandler.Delete)                       // 删除房间
+				rooms.POST("/:roomId/members", roomHandler.AddMembers)             // 邀请成员
+				rooms.DELETE("/:roomId/members/:userId", roomHandler.RemoveMember) // 移除成员或退出房间
+				rooms.GET("/:roomId/live", roomHandler.Live)                       // 房间实时推送（WebSocket）
+			}
+
+			// 通知相关路由
+			notifications := auth.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.List)                      // 获取通知列表
+				notifications.GET("/unread-count", notificationHandler.UnreadCount)  // 获取未读数
+				notifications.POST("/read", notificationHandler.MarkRead)            // 标记已读
+				notifications.DELETE("/:notificationId", notificationHandler.Delete) // 删除通知
+				notifications.GET("/live", notificationHandler.Live)                 // 实时通知（WebSocket）
+			}
+
+			// 播放列表相关路由
+			playlists := auth.Group("/playlists")
+			{
+				playlists.POST("", playlistHandler.Create)                                  // 创建播放列表
+				playlists.PUT("/:playlistId", playlistHandler.Update)                       // 更新播放列表
+				playlists.DELETE("/:playlistId", playlistHandler.Delete)                    // 删除播放列表
+				playlists.POST("/:playlistId/items", playlistHandler.AddItem)               // 添加视频
+				playlists.DELETE("/:playlistId/items/:videoId", playlistHandler.RemoveItem) // 移除视频
+				playlists.PUT("/:playlistId/order", playlistHandler.Reorder)                // 调整顺序
+			}
+
+			// 收藏夹相关路由
+			folders := auth.Group("/favorite-folders")
+			{
+				folders.GET("", favoriteFolderHandler.List)                // 获取收藏夹列表
+				folders.POST("", favoriteFolderHandler.Create)             // 创建收藏夹
+				folders.PUT("/:folderId", favoriteFolderHandler.Rename)    // 重命名收藏夹
+				folders.DELETE("/:folderId", favoriteFolderHandler.Delete) // 删除收藏夹
+			}
+
+			// 稍后再看相关路由
+			watchLater := auth.Group("/watch-later")
+			{
+				watchLater.GET("", watchLaterHandler.List)                   // 获取稍后再看列表
+				watchLater.POST("/:videoId", watchLaterHandler.Add)          // 添加到稍后再看
+				watchLater.DELETE("/:videoId", watchLaterHandler.Remove)     // 从稍后再看移除
+				watchLater.PUT("/:videoId/position", watchLaterHandler.Move) // 拖动排序
+			}
+
+			// 屏蔽列表（?mode=block|mute）
+			auth.GET("/blocks", blockHandler.List)
+
+			// 举报视频、评论、用户或标记
+			auth.POST("/reports", moderationHandler.Report)
+
+			// 审核相关路由（仅审核员）
+			moderation := auth.Group("/moderation")
+			moderation.Use(middleware.Moderator())
+			{
+				moderation.GET("/cases", moderationHandler.ListCases)                // 审核队列
+				moderation.GET("/cases/:caseId", moderationHandler.GetCase)          // 工单详情
+				moderation.POST("/cases/:caseId/claim", moderationHandler.Claim)     // 领取工单
+				moderation.POST("/cases/:caseId/resolve", moderationHandler.Resolve) // 确认违规
+				moderation.POST("/cases/:caseId/dismiss", moderationHandler.Dismiss) // 驳回举报
+				moderation.GET("/logs", moderationHandler.ListLogs)                  // 审核记录
+			}
+
+			// 动态流相关路由
+			feed := auth.Group("/feed")
+			{
+				feed.GET("/following", feedHandler.Following) // 关注动态
+			}
+
+			// 私信相关路由
+			im := auth.Group("/im")
+			{
+				im.GET("/conversations", messageHandler.ListConversations)                     // 获取会话列表
+				im.POST("/conversations", messageHandler.OpenConversation)                     // 打开与某用户的会话
+				im.GET("/conversations/:conversationId/messages", messageHandler.ListMessages) // 获取会话消息
+				im.POST("/conversations/:conversationId/read", messageHandler.MarkRead)        // 标记已读
+				im.POST("/messages", messageHandler.Send)                                      // 发送私信
+				im.GET("/ws", messageHandler.Gateway)                                          // 私信网关（WebSocket）
 			}
 
-			// 导出相关路由
-			authVideos.GET("/export", markHandler.ExportMarks) // 导出标记、注释和笔记
+			// 导入导出相关路由
+			authVideos.GET("/export", exportHandler.Export)           // 导出标记、注释和笔记
+			authVideos.POST("/:videoId/import", importHandler.Import) // 导入标记、注释和笔记
 		}
 	}
 }
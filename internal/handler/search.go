@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	if searchService == nil {
+		searchService = service.NewSearchService()
+	}
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search 搜索自己的标记、注释和笔记
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, _ := c.Get("userId")
+
+	var query model.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的搜索参数")
+		return
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		response.Fail(c, http.StatusBadRequest, "结束日期不能早于开始日期")
+		return
+	}
+
+	result, err := h.searchService.Search(c.Request.Context(), userID.(string), &query)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, "搜索失败")
+		slog.Error("[Search] 搜索标记和笔记失败", "error", err, "keyword", query.Keyword)
+		return
+	}
+	response.Success(c, result)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShareHandler struct {
+	shareService service.ShareService
+}
+
+func NewShareHandler(shareService service.ShareService) *ShareHandler {
+	if shareService == nil {
+		shareService = service.NewShareService()
+	}
+	return &ShareHandler{
+		shareService: shareService,
+	}
+}
+
+// Create 创建分享链接
+func (h *ShareHandler) Create(c *gin.Context) {
+	videoID := c.Param("videoId")
+
+	var req model.CreateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[CreateShare] 无效的请求参数", "error", err)
+		return
+	}
+
+	// 获取当前用户ID（游客也可以分享）
+	currentUserID, _ := c.Get("userId")
+	userID, _ := currentUserID.(string)
+
+	link, err := h.shareService.Create(c.Request.Context(), userID, videoID, req.Channel)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, err.Error())
+		slog.Error("[CreateShare] 创建分享链接失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, link)
+}
+
+// Redirect 访问短链接，记录点击并跳转到视频
+func (h *ShareHandler) Redirect(c *gin.Context) {
+	code := c.Param("code")
+
+	link, err := h.shareService.Resolve(c.Request.Context(), code)
+	if err != nil {
+		if errors.Is(err, service.ErrShareLinkNotFound) || errors.Is(err, service.ErrShareVideoUnavailable) {
+			response.Fail(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[ShareRedirect] 解析分享链接失败", "error", err, "code", code)
+		return
+	}
+
+	c.Redirect(http.StatusFound, service.ShareLandingURL(link))
+}
+
+// GetStats 获取视频的分享统计（按渠道）
+func (h *ShareHandler) GetStats(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	videoID := c.Param("videoId")
+
+	stats, err := h.shareService.GetStats(c.Request.Context(), userID.(string), videoID)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, err.Error())
+		slog.Error("[GetShareStats] 获取分享统计失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, stats)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"video-platform/config"
@@ -266,7 +267,10 @@ func (h *UserHandler) GetFavorites(c *gin.Context) {
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "12"))
 
 	// 获取收藏列表
-	favorites, err := h.userService.GetFavorites(c.Request.Context(), userID, page, size)
+	// 按收藏夹筛选，default表示默认收藏夹，不传返回全部
+	folderID := c.Query("folderId")
+
+	favorites, err := h.userService.GetFavorites(c.Request.Context(), userID, folderID, page, size)
 	if err != nil {
 		response.Fail(c, http.StatusInternalServerError, err.Error())
 		slog.Error("[GetFavorites] 获取收藏列表失败", "error", err)
@@ -294,8 +298,23 @@ func (h *UserHandler) AddToFavorites(c *gin.Context) {
 		return
 	}
 
+	// 可选的收藏夹，不传时放入默认收藏夹
+	var req model.AddFavoriteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+			slog.Error("[AddToFavorites] 无效的请求参数", "error", err)
+			return
+		}
+	}
+
 	// 添加到收藏
-	err := h.userService.AddToFavorites(c.Request.Context(), userID.(string), videoID)
+	err := h.userService.AddToFavorites(c.Request.Context(), userID.(string), videoID, req.FolderID)
+	if errors.Is(err, service.ErrFavoriteFolderNotFound) {
+		response.Fail(c, http.StatusNotFound, err.Error())
+		slog.Error("[AddToFavorites] 收藏夹不存在", "folderId", req.FolderID)
+		return
+	}
 	if err != nil {
 		response.Fail(c, http.StatusInternalServerError, err.Error())
 		slog.Error("[AddToFavorites] 添加收藏失败", "error", err)
@@ -352,8 +371,22 @@ func (h *UserHandler) RecordWatchHistory(c *gin.Context) {
 		return
 	}
 
+	// 可选的观看进度，不传表示已看完
+	var req model.RecordWatchRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+			slog.Error("[RecordWatchHistory] 无效的请求参数", "error", err)
+			return
+		}
+	}
+	progress := -1.0
+	if req.Progress != nil {
+		progress = *req.Progress
+	}
+
 	// 记录观看历史
-	err := h.userService.RecordWatchHistory(c.Request.Context(), userID.(string), videoID)
+	err := h.userService.RecordWatchHistory(c.Request.Context(), userID.(string), videoID, progress)
 	if err != nil {
 		response.Fail(c, http.StatusInternalServerError, err.Error())
 		slog.Error("[RecordWatchHistory] 记录观看历史失败", "error", err)
@@ -362,3 +395,61 @@ func (h *UserHandler) RecordWatchHistory(c *gin.Context) {
 
 	response.Success(c, gin.H{"message": "记录观看历史成功"})
 }
+
+// LikeVideo 点赞视频
+func (h *UserHandler) LikeVideo(c *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := c.Get("userId")
+	if !exists {
+		response.Fail(c, http.StatusUnauthorized, "用户未登录")
+		slog.Error("[LikeVideo] 用户未登录")
+		return
+	}
+
+	// 获取视频ID
+	videoID := c.Param("videoId")
+	if videoID == "" {
+		response.Fail(c, http.StatusBadRequest, "视频ID不能为空")
+		slog.Error("[LikeVideo] 视频ID为空")
+		return
+	}
+
+	// 点赞
+	err := h.userService.LikeVideo(c.Request.Context(), userID.(string), videoID)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[LikeVideo] 点赞失败", "error", err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "点赞成功"})
+}
+
+// UnlikeVideo 取消点赞
+func (h *UserHandler) UnlikeVideo(c *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := c.Get("userId")
+	if !exists {
+		response.Fail(c, http.StatusUnauthorized, "用户未登录")
+		slog.Error("[UnlikeVideo] 用户未登录")
+		return
+	}
+
+	// 获取视频ID
+	videoID := c.Param("videoId")
+	if videoID == "" {
+		response.Fail(c, http.StatusBadRequest, "视频ID不能为空")
+		slog.Error("[UnlikeVideo] 视频ID为空")
+		return
+	}
+
+	// 取消点赞
+	err := h.userService.UnlikeVideo(c.Request.Context(), userID.(string), videoID)
+	if err != nil {
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+		slog.Error("[UnlikeVideo] 取消点赞失败", "error", err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "取消点赞成功"})
+}
@@ -58,13 +58,13 @@ func (m *MockUserService) GetWatchHistory(ctx context.Context, id string, page,
 	return args.Get(0).(*model.WatchHistoryResponse), args.Error(1)
 }
 
-func (m *MockUserService) GetFavorites(ctx context.Context, id string, page, size int) (*model.FavoriteResponse, error) {
-	args := m.Called(ctx, id, page, size)
+func (m *MockUserService) GetFavorites(ctx context.Context, id, folderID string, page, size int) (*model.FavoriteResponse, error) {
+	args := m.Called(ctx, id, folderID, page, size)
 	return args.Get(0).(*model.FavoriteResponse), args.Error(1)
 }
 
-func (m *MockUserService) AddToFavorites(ctx context.Context, userID, videoID string) error {
-	args := m.Called(ctx, userID, videoID)
+func (m *MockUserService) AddToFavorites(ctx context.Context, userID, videoID, folderID string) error {
+	args := m.Called(ctx, userID, videoID, folderID)
 	return args.Error(0)
 }
 
@@ -73,8 +73,8 @@ func (m *MockUserService) RemoveFromFavorites(ctx context.Context, userID, video
 	return args.Error(0)
 }
 
-func (m *MockUserService) RecordWatchHistory(ctx context.Context, userID, videoID string) error {
-	args := m.Called(ctx, userID, videoID)
+func (m *MockUserService) RecordWatchHistory(ctx context.Context, userID, videoID string, progress float64) error {
+	args := m.Called(ctx, userID, videoID, progress)
 	return args.Error(0)
 }
 
@@ -83,6 +83,21 @@ func (m *MockUserService) CheckFavoriteStatus(ctx context.Context, userID, video
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockUserService) LikeVideo(ctx context.Context, userID, videoID string) error {
+	args := m.Called(ctx, userID, videoID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UnlikeVideo(ctx context.Context, userID, videoID string) error {
+	args := m.Called(ctx, userID, videoID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) CheckLikeStatus(ctx context.Context, userID, videoID string) (bool, error) {
+	args := m.Called(ctx, userID, videoID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockUserService) LoginOrRegisterByPhone(ctx context.Context, phone string) (*model.User, string, error) {
 	args := m.Called(ctx, phone)
 	return args.Get(0).(*model.User), args.String(1), args.Error(2)
@@ -220,7 +235,7 @@ func TestAddToFavorites(t *testing.T) {
 	// 执行服务前确保Request不会被覆盖
 
 	// 模拟服务层响应
-	mockService.On("AddToFavorites", mock.Anything, userId, videoId).Return(nil)
+	mockService.On("AddToFavorites", mock.Anything, userId, videoId, "").Return(nil)
 
 	// 执行测试
 	handler.AddToFavorites(c)
@@ -251,7 +266,7 @@ func TestRecordWatchHistory(t *testing.T) {
 	// 执行服务前确保Request不会被覆盖
 
 	// 模拟服务层响应
-	mockService.On("RecordWatchHistory", mock.Anything, userId, videoId).Return(nil)
+	mockService.On("RecordWatchHistory", mock.Anything, userId, videoId, -1.0).Return(nil)
 
 	// 执行测试
 	handler.RecordWatchHistory(c)
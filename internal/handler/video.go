@@ -119,7 +119,7 @@ func (h *VideoHandler) GetByID(c *gin.Context) {
 	// 获取用户服务实例 - 使用依赖注入方式，易于测试
 	userSvc := getUserService()
 
-	// 检查用户是否已收藏视频
+	// 检查用户是否已收藏、点赞视频
 	userID, exists := c.Get("userId")
 	if exists && userID.(string) != "" && userSvc != nil {
 		// 查询收藏状态
@@ -128,6 +128,22 @@ func (h *VideoHandler) GetByID(c *gin.Context) {
 			// 只在无错误时添加是否收藏信息
 			result["isFavorite"] = isFavorite
 		}
+
+		// 查询点赞状态
+		isLiked, err := userSvc.CheckLikeStatus(c.Request.Context(), userID.(string), videoID)
+		if err == nil {
+			result["isLiked"] = isLiked
+		}
+	}
+
+	// 从播放列表进入时返回播放位置及前后视频，用于顺序播放
+	if playlistID := c.Query("playlistId"); playlistID != "" {
+		nav, err := getPlaylistService().Navigate(c.Request.Context(), c.GetString("userId"), playlistID, videoID)
+		if err == nil {
+			result["playlist"] = nav
+		} else {
+			slog.Warn("[GetByID] 获取播放列表位置失败", "error", err, "playlistId", playlistID)
+		}
 	}
 
 	response.Success(c, result)
@@ -138,6 +154,16 @@ var getUserService = func() service.UserService {
 	return service.NewUserService()
 }
 
+// getPlaylistService 获取播放列表服务实例，提供依赖注入点，方便测试
+var getPlaylistService = func() service.PlaylistService {
+	return service.NewPlaylistService()
+}
+
+// getShareService 获取分享服务实例，提供依赖注入点，方便测试
+var getShareService = func() service.ShareService {
+	return service.NewShareService()
+}
+
 // Update 更新视频信息
 func (h *VideoHandler) Update(c *gin.Context) {
 	// 获取当前用户ID
@@ -220,19 +246,24 @@ func (h *VideoHandler) Stream(c *gin.Context) {
 		return
 	}
 
-	// 权限检查：非公开视频只有作者可以观看
-	// if video.Status != model.VideoStatusPublic {
-	// 	// 获取当前用户ID
-	// 	userID, exists := c.Get("userId")
-	// 	if !exists || userID.(string) != video.UserID {
-	// 		response.Fail(c, http.StatusForbidden, "无权观看此视频")
-	// 		return
-	// 	}
-	// }
+	// 权限检查：已下架的视频不能播放，其他非公开视频只有作者可以观看
+	if video.Status == model.VideoStatusBlocked {
+		response.Fail(c, http.StatusForbidden, "视频已下架")
+		return
+	}
+	if video.Status != model.VideoStatusPublic && c.GetString("userId") != video.UserID {
+		response.Fail(c, http.StatusForbidden, "无权观看此视频")
+		return
+	}
 
 	// 增加观看次数
 	go h.videoService.IncrementStats(context.Background(), videoId, "views")
 
+	// 通过分享链接进入的播放，归因到对应的分享链接
+	if shareCode := c.Query("share"); shareCode != "" {
+		go getShareService().RecordView(context.Background(), shareCode, videoId)
+	}
+
 	filePath := filepath.Join(config.GlobalConfig.Storage.UploadDir, video.FileName)
 	file, err := os.Open(filePath)
 	if err != nil {
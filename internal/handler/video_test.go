@@ -142,6 +142,7 @@ func TestGetVideoByIDWithFavoriteStatus(t *testing.T) {
 	
 	// 模拟CheckFavoriteStatus调用
 	mockUserService.On("CheckFavoriteStatus", mock.Anything, userID, videoID).Return(true, nil)
+	mockUserService.On("CheckLikeStatus", mock.Anything, userID, videoID).Return(false, nil)
 
 	// 执行测试
 	handler.GetByID(c)
@@ -163,6 +164,9 @@ func TestGetVideoByIDWithFavoriteStatus(t *testing.T) {
 	isFavorite, hasFavorite := responseData["isFavorite"]
 	assert.True(t, hasFavorite)
 	assert.Equal(t, true, isFavorite)
+	isLiked, hasLiked := responseData["isLiked"]
+	assert.True(t, hasLiked)
+	assert.Equal(t, false, isLiked)
 
 	// 验证调用次数
 	mockService.AssertExpectations(t)
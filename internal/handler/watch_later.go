@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"video-platform/internal/model"
+	"video-platform/internal/service"
+	"video-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WatchLaterHandler struct {
+	watchLaterService service.WatchLaterService
+}
+
+func NewWatchLaterHandler(watchLaterService service.WatchLaterService) *WatchLaterHandler {
+	if watchLaterService == nil {
+		watchLaterService = service.NewWatchLaterService()
+	}
+	return &WatchLaterHandler{
+		watchLaterService: watchLaterService,
+	}
+}
+
+// List 获取稍后再看列表
+func (h *WatchLaterHandler) List(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	items, err := h.watchLaterService.List(c.Request.Context(), userID.(string), page, size)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[ListWatchLater] 获取稍后再看列表失败", "error", err)
+		return
+	}
+
+	response.Success(c, items)
+}
+
+// Add 添加到稍后再看
+func (h *WatchLaterHandler) Add(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	videoID := c.Param("videoId")
+
+	item, err := h.watchLaterService.Add(c.Request.Context(), userID.(string), videoID)
+	if err != nil {
+		h.fail(c, err)
+		slog.Error("[AddWatchLater] 添加稍后再看失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, item)
+}
+
+// Remove 从稍后再看中移除
+func (h *WatchLaterHandler) Remove(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	videoID := c.Param("videoId")
+
+	if err := h.watchLaterService.Remove(c.Request.Context(), userID.(string), videoID); err != nil {
+		h.fail(c, err)
+		slog.Error("[RemoveWatchLater] 移除稍后再看失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Move 拖动调整稍后再看中视频的位置
+func (h *WatchLaterHandler) Move(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	videoID := c.Param("videoId")
+
+	var req model.MoveWatchLaterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "无效的请求参数")
+		slog.Error("[MoveWatchLater] 无效的请求参数", "error", err)
+		return
+	}
+
+	if err := h.watchLaterService.Move(c.Request.Context(), userID.(string), videoID, req.Index); err != nil {
+		h.fail(c, err)
+		slog.Error("[MoveWatchLater] 调整位置失败", "error", err, "videoId", videoID)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// fail 根据错误类型返回对应的状态码
+func (h *WatchLaterHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrWatchLaterNotFound):
+		response.Fail(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrWatchLaterExists):
+		response.Fail(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrWatchLaterFull):
+		response.Fail(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Fail(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"video-platform/internal/service"
 	"video-platform/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -20,6 +22,10 @@ func Auth() gin.HandlerFunc {
 		}
 
 		authHeader := c.GetHeader("Authorization")
+		// 浏览器的 WebSocket 无法设置请求头，允许通过 token 查询参数传递
+		if authHeader == "" && c.IsWebsocket() && c.Query("token") != "" {
+			authHeader = "Bearer " + c.Query("token")
+		}
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code": 1,
@@ -52,6 +58,21 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
+		// token在过期前始终有效，需要检查账号是否已被禁用
+		if err := service.CheckUserActive(c.Request.Context(), claims.UserID); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, service.ErrUserDisabled) {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, gin.H{
+				"code": 1,
+				"msg":  err.Error(),
+				"data": nil,
+			})
+			c.Abort()
+			return
+		}
+
 		// 将用户信息保存到上下文
 		c.Set("userId", claims.UserID)
 		c.Set("username", claims.Username)
@@ -86,6 +107,11 @@ func SetUserId() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		// 被禁用的账号按未登录处理
+		if service.CheckUserActive(c.Request.Context(), claims.UserID) != nil {
+			c.Next()
+			return
+		}
 
 		// 将用户信息保存到上下文
 		c.Set("userId", claims.UserID)
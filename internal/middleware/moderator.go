@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"video-platform/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Moderator 审核员权限中间件，需要在 Auth 之后使用
+func Moderator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userId")
+		for _, id := range config.GlobalConfig.Moderation.ModeratorIDs {
+			if id = strings.TrimSpace(id); id != "" && id == userID {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": 1,
+			"msg":  "无审核权限",
+			"data": nil,
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 屏蔽方式
+const (
+	BlockModeBlock = "block" // 拉黑：对方不能评论、发弹幕、私信或关注，且对方的内容对自己隐藏
+	BlockModeMute  = "mute"  // 静音：只隐藏对方的内容，不限制互动
+)
+
+// Block 用户屏蔽关系
+type Block struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"user_id" json:"userId"`     // 屏蔽者ID
+	TargetID  string             `bson:"target_id" json:"targetId"` // 被屏蔽者ID
+	Mode      string             `bson:"mode" json:"mode"`          // 屏蔽方式：block/mute
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// BlockUser 屏蔽列表项
+type BlockUser struct {
+	UserBrief
+	Mode      string    `json:"mode"`
+	BlockedAt time.Time `json:"blockedAt"` // 屏蔽时间
+}
+
+// BlockListResponse 屏蔽列表响应
+type BlockListResponse struct {
+	Users []BlockUser `json:"users"`
+	Total int64       `json:"total"`
+	Page  int         `json:"page"`
+	Size  int         `json:"size"`
+}
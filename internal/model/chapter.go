@@ -0,0 +1,35 @@
+package model
+
+// 章节导出格式
+const (
+	ChapterFormatJSON = "json" // 章节列表
+	ChapterFormatVTT  = "vtt"  // WebVTT 章节轨道（<track kind="chapters">）
+	ChapterFormatMP4  = "mp4"  // MP4Box、mp4chaps 等工具可写入MP4的章节列表（CHAPTERxx=时间）
+)
+
+// ChapterContentTypes 章节文件导出格式对应的Content-Type
+var ChapterContentTypes = map[string]string{
+	ChapterFormatVTT: "text/vtt; charset=utf-8",
+	ChapterFormatMP4: "text/plain; charset=utf-8",
+}
+
+// Chapter 视频的官方章节，由作者设置，按开始时间排序且互不重叠
+type Chapter struct {
+	Title  string  `bson:"title" json:"title"`
+	Start  float64 `bson:"start" json:"start"`                        // 开始时间（秒）
+	End    float64 `bson:"end,omitempty" json:"end,omitempty"`        // 结束时间（秒），视频时长未知时最后一章为0
+	MarkID string  `bson:"mark_id,omitempty" json:"markId,omitempty"` // 由哪个标记提升而来
+}
+
+// ChapterInput 设置章节时的单个章节。指定 MarkID 时使用标记的时间范围，标题为空时使用标记内容
+type ChapterInput struct {
+	MarkID string  `json:"markId"`
+	Title  string  `json:"title"`
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"` // 可选，为0时到下一章节开始或视频结束
+}
+
+// SetChaptersRequest 设置视频章节请求，整体替换原有章节，为空时清除章节
+type SetChaptersRequest struct {
+	Chapters []ChapterInput `json:"chapters"`
+}
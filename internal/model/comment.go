@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 评论排序方式
+const (
+	CommentSortHot = "hot" // 按热度（点赞数）
+	CommentSortNew = "new" // 按时间
+)
+
+// Comment 视频评论（两级结构：一级评论及其回复）
+type Comment struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VideoID       string             `bson:"video_id" json:"videoId"`                   // 视频ID
+	UserID        string             `bson:"user_id" json:"userId"`                     // 评论者ID
+	RootID        primitive.ObjectID `bson:"root_id,omitempty" json:"rootId,omitempty"` // 所属一级评论ID，一级评论为空
+	ParentID      primitive.ObjectID `bson:"parent_id,omitempty" json:"parentId,omitempty"`
+	ReplyToUserID string             `bson:"reply_to_user_id,omitempty" json:"replyToUserId,omitempty"` // 被回复的用户ID
+	Content       string             `bson:"content" json:"content"`                                    // 评论内容
+	Likes         int64              `bson:"likes" json:"likes"`                                        // 点赞数
+	ReplyCount    int64              `bson:"reply_count" json:"replyCount"`                             // 回复数（仅一级评论）
+	Hidden        bool               `bson:"hidden,omitempty" json:"-"`                                 // 因举报被隐藏
+	CreatedAt     time.Time          `bson:"created_at" json:"createdAt"`                               // 创建时间
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updatedAt"`                               // 更新时间
+}
+
+// CommentLike 评论点赞记录
+type CommentLike struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CommentID primitive.ObjectID `bson:"comment_id" json:"commentId"`
+	UserID    string             `bson:"user_id" json:"userId"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// CreateCommentRequest 发表评论请求
+type CreateCommentRequest struct {
+	Content  string `json:"content" binding:"required,min=1,max=500"`
+	ParentID string `json:"parentId"` // 可选，回复的评论ID
+}
+
+// UpdateCommentRequest 编辑评论请求
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required,min=1,max=500"`
+}
+
+// CommentItem 评论列表项
+type CommentItem struct {
+	Comment
+	User    UserBrief     `json:"user"`              // 评论者信息
+	IsLiked bool          `json:"isLiked"`           // 当前用户是否已点赞
+	Replies []CommentItem `json:"replies,omitempty"` // 回复预览（仅一级评论）
+}
+
+// CommentListResponse 评论列表响应（游标分页）
+type CommentListResponse struct {
+	Comments   []CommentItem `json:"comments"`
+	NextCursor string        `json:"nextCursor"` // 下一页游标，为空表示没有更多
+	HasMore    bool          `json:"hasMore"`
+}
+
+// CommentQuery 评论查询参数
+type CommentQuery struct {
+	Sort   string `form:"sort" binding:"omitempty,oneof=hot new"`
+	Cursor string `form:"cursor"`
+	Size   int    `form:"size"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 弹幕显示模式
+const (
+	DanmakuModeScroll = "scroll" // 滚动
+	DanmakuModeTop    = "top"    // 顶部固定
+	DanmakuModeBottom = "bottom" // 底部固定
+)
+
+// IsValidDanmakuMode 验证弹幕显示模式
+func IsValidDanmakuMode(mode string) bool {
+	switch mode {
+	case DanmakuModeScroll, DanmakuModeTop, DanmakuModeBottom:
+		return true
+	default:
+		return false
+	}
+}
+
+// Danmaku 弹幕模型
+type Danmaku struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VideoID   string             `bson:"video_id" json:"videoId"`     // 视频ID
+	UserID    string             `bson:"user_id" json:"userId"`       // 发送者ID
+	Content   string             `bson:"content" json:"content"`      // 弹幕内容
+	Time      float64            `bson:"time" json:"time"`            // 相对视频开头的时间（秒）
+	Color     string             `bson:"color" json:"color"`          // 颜色，如 #FFFFFF
+	Mode      string             `bson:"mode" json:"mode"`            // 显示模式
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"` // 发送时间
+}
+
+// SendDanmakuRequest 发送弹幕请求
+type SendDanmakuRequest struct {
+	Content string  `json:"content" binding:"required,min=1,max=100"`
+	Time    float64 `json:"time" binding:"min=0"`
+	Color   string  `json:"color" binding:"omitempty,hexcolor"`
+	Mode    string  `json:"mode" binding:"omitempty,oneof=scroll top bottom"`
+}
+
+// DanmakuQuery 弹幕查询参数，按时间窗口渐进加载
+type DanmakuQuery struct {
+	Start float64 `form:"start" binding:"min=0"` // 窗口开始时间（秒）
+	End   float64 `form:"end" binding:"min=0"`   // 窗口结束时间（秒），为0时默认加载60秒
+	Limit int     `form:"limit"`                 // 最大返回条数
+}
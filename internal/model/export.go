@@ -0,0 +1,79 @@
+package model
+
+// 导出格式
+const (
+	ExportFormatJSON     = "json" // 原始数据
+	ExportFormatVTT      = "vtt"  // WebVTT 字幕
+	ExportFormatSRT      = "srt"  // SRT 字幕
+	ExportFormatMarkdown = "md"   // Markdown 文档
+	ExportFormatCSV      = "csv"  // CSV 表格
+)
+
+// ExportContentTypes 各导出格式对应的Content-Type和文件扩展名
+var ExportContentTypes = map[string]string{
+	ExportFormatJSON:     "application/json; charset=utf-8",
+	ExportFormatVTT:      "text/vtt; charset=utf-8",
+	ExportFormatSRT:      "application/x-subrip; charset=utf-8",
+	ExportFormatMarkdown: "text/markdown; charset=utf-8",
+	ExportFormatCSV:      "text/csv; charset=utf-8",
+}
+
+// IsValidExportFormat 验证导出格式
+func IsValidExportFormat(format string) bool {
+	_, ok := ExportContentTypes[format]
+	return ok
+}
+
+// ExportQuery 导出参数，指定多个视频时打包为zip
+type ExportQuery struct {
+	VideoIDs []string `form:"videoId" binding:"required,min=1"`
+	Format   string   `form:"format"` // 默认为 json
+}
+
+// ExportVideo 单个视频的导出数据
+type ExportVideo struct {
+	VideoID string `json:"videoId"`
+	Title   string `json:"title"`
+	Marks   []Mark `json:"marks"`
+	Notes   []Note `json:"notes"`
+}
+
+// ExportFile 导出生成的文件
+type ExportFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// 导入模式
+const (
+	ImportModeMerge   = "merge"   // 合并，跳过与已有记录相同的标记和笔记
+	ImportModeReplace = "replace" // 替换，先删除自己在该视频上的标记和笔记
+)
+
+// ImportRequest 导入参数，文件通过 multipart 的 file 字段上传
+type ImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=vtt srt json"` // 为空时根据文件扩展名和内容识别
+	Mode   string `form:"mode" binding:"omitempty,oneof=merge replace"`  // 默认为 merge
+	DryRun bool   `form:"dryRun"`                                        // 只校验不写入
+}
+
+// ImportError 单条记录的导入错误
+type ImportError struct {
+	Cue     int    `json:"cue"`            // 记录序号，从1开始
+	Line    int    `json:"line,omitempty"` // 字幕文件中的行号
+	Message string `json:"message"`
+}
+
+// ImportResult 导入结果，存在错误时不写入任何记录
+type ImportResult struct {
+	Format      string        `json:"format"`
+	Mode        string        `json:"mode"`
+	DryRun      bool          `json:"dryRun"`
+	Applied     bool          `json:"applied"`     // 是否已写入
+	Marks       int           `json:"marks"`       // 导入（或将导入）的标记数
+	Annotations int           `json:"annotations"` // 导入（或将导入）的注释数
+	Notes       int           `json:"notes"`       // 导入（或将导入）的笔记数
+	Skipped     int           `json:"skipped"`     // 合并时与已有记录重复而跳过的数量
+	Errors      []ImportError `json:"errors"`
+}
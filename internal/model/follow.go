@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Follow 关注关系
+type Follow struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FollowerID string             `bson:"follower_id" json:"followerId"` // 关注者ID
+	FolloweeID string             `bson:"followee_id" json:"followeeId"` // 被关注者ID
+	CreatedAt  time.Time          `bson:"created_at" json:"createdAt"`   // 关注时间
+}
+
+// FollowStats 关注计数
+type FollowStats struct {
+	UserID    string `bson:"_id" json:"userId"`
+	Following int64  `bson:"following" json:"following"` // 关注数
+	Followers int64  `bson:"followers" json:"followers"` // 粉丝数
+}
+
+// UserBrief 用户简要信息，用于列表展示
+type UserBrief struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+}
+
+// FollowUser 关注/粉丝列表项
+type FollowUser struct {
+	UserBrief
+	FollowedAt time.Time `json:"followedAt"` // 关注时间
+	IsMutual   bool      `json:"isMutual"`   // 是否互相关注
+}
+
+// FollowListResponse 关注/粉丝列表响应
+type FollowListResponse struct {
+	Users []FollowUser `json:"users"`
+	Total int64        `json:"total"`
+	Page  int          `json:"page"`
+	Size  int          `json:"size"`
+}
+
+// FollowRelation 两个用户之间的关注关系
+type FollowRelation struct {
+	IsFollowing bool `json:"isFollowing"` // 当前用户是否关注了对方
+	IsFollowed  bool `json:"isFollowed"`  // 对方是否关注了当前用户
+	IsMutual    bool `json:"isMutual"`    // 是否互相关注
+}
@@ -6,16 +6,67 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Mark 用户自定义标记模型
+// Mark 用户自定义标记模型，EndTime大于Timestamp时为区间标记
 type Mark struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID      string             `bson:"user_id" json:"userId"`          // 用户ID
-	VideoID     string             `bson:"video_id" json:"videoId"`        // 视频ID
-	Timestamp   float64            `bson:"timestamp" json:"timestamp"`     // 时间戳
-	Content     string             `bson:"content" json:"content"`         // 标记内容
-	Annotations []Annotation       `bson:"annotations" json:"annotations"` // 关联的注释
-	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`    // 创建时间
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`    // 更新时间
+	UserID      string             `bson:"user_id" json:"userId"`                        // 用户ID
+	VideoID     string             `bson:"video_id" json:"videoId"`                      // 视频ID
+	Timestamp   float64            `bson:"timestamp" json:"timestamp"`                   // 时间戳，区间标记的开始时间
+	EndTime     float64            `bson:"end_time,omitempty" json:"endTime,omitempty"`  // 区间标记的结束时间，为0时为时间点标记
+	Category    string             `bson:"category,omitempty" json:"category,omitempty"` // 标记分类
+	Color       string             `bson:"color,omitempty" json:"color,omitempty"`       // 显示颜色（#RRGGBB），为空时使用分类的默认颜色
+	Content     string             `bson:"content" json:"content"`                       // 标记内容
+	Annotations []Annotation       `bson:"annotations" json:"annotations"`               // 关联的注释
+	Hidden      bool               `bson:"hidden,omitempty" json:"hidden,omitempty"`     // 因举报被隐藏
+	RoomID      string             `bson:"room_id,omitempty" json:"roomId,omitempty"`    // 所属的协作标注房间
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`                  // 创建时间
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`                  // 更新时间
+}
+
+// IsRange 是否为区间标记
+func (m *Mark) IsRange() bool {
+	return m.EndTime > m.Timestamp
+}
+
+// 标记分类
+const (
+	MarkCategoryKeyPoint = "key_point" // 重点
+	MarkCategoryQuestion = "question"  // 疑问
+	MarkCategoryMistake  = "mistake"   // 易错
+	MarkCategoryOther    = "other"     // 其他
+)
+
+// MarkCategory 标记分类及其默认颜色
+type MarkCategory struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+	Color string `json:"color"`
+}
+
+// MarkCategories 可选的标记分类，按展示顺序排列
+var MarkCategories = []MarkCategory{
+	{MarkCategoryKeyPoint, "重点", "#F5A623"},
+	{MarkCategoryQuestion, "疑问", "#4A90E2"},
+	{MarkCategoryMistake, "易错", "#D0021B"},
+	{MarkCategoryOther, "其他", "#9B9B9B"},
+}
+
+// FindMarkCategory 根据分类代码查找标记分类
+func FindMarkCategory(code string) (MarkCategory, bool) {
+	for _, c := range MarkCategories {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return MarkCategory{}, false
+}
+
+// MarkQuery 标记查询参数，Start和End为时间窗口，返回与窗口有重叠的标记
+type MarkQuery struct {
+	VideoID  string  `form:"videoId"`
+	Category string  `form:"category"`              // 按分类过滤
+	Start    float64 `form:"start" binding:"min=0"` // 窗口开始时间（秒）
+	End      float64 `form:"end" binding:"min=0"`   // 窗口结束时间（秒），为0时不限制
 }
 
 // Annotation 注释模型
@@ -23,18 +74,90 @@ type Annotation struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	UserID    string             `bson:"user_id" json:"userId"`       // 用户ID
 	MarkID    primitive.ObjectID `bson:"mark_id" json:"markId"`       // 关联的标记ID
+	VideoID   string             `bson:"video_id" json:"videoId"`     // 标记所在的视频ID，用于删除视频时级联删除
 	Content   string             `bson:"content" json:"content"`      // 注释内容
 	CreatedAt time.Time          `bson:"created_at" json:"createdAt"` // 创建时间
 	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"` // 更新时间
 }
 
+// 笔记可见范围
+const (
+	NoteVisibilityPrivate = "private" // 仅自己可见
+	NoteVisibilityShared  = "shared"  // 分享给指定用户
+	NoteVisibilityPublic  = "public"  // 公开，出现在视频的社区笔记中
+)
+
+// IsValidNoteVisibility 验证笔记可见范围
+func IsValidNoteVisibility(visibility string) bool {
+	switch visibility {
+	case NoteVisibilityPrivate, NoteVisibilityShared, NoteVisibilityPublic:
+		return true
+	default:
+		return false
+	}
+}
+
 // Note 笔记模型
 type Note struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      string             `bson:"user_id" json:"userId"`                             // 用户ID
+	VideoID     string             `bson:"video_id" json:"videoId"`                           // 视频ID
+	Timestamp   float64            `bson:"timestamp" json:"timestamp"`                        // 时间戳
+	Content     string             `bson:"content" json:"content"`                            // 笔记内容（Markdown）
+	ContentHTML string             `bson:"-" json:"contentHtml"`                              // 渲染并过滤后的HTML
+	Visibility  string             `bson:"visibility" json:"visibility"`                      // 可见范围，旧数据为空时视为仅自己可见
+	SharedWith  []string           `bson:"shared_with,omitempty" json:"sharedWith,omitempty"` // 分享的用户ID（可见范围为 shared 时）
+	Version     int                `bson:"version" json:"version"`                            // 版本号，每次修改加1，旧数据为0
+	RoomID      string             `bson:"room_id,omitempty" json:"roomId,omitempty"`         // 所属的协作标注房间，房间成员都可以编辑
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`                       // 创建时间
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`                       // 更新时间
+}
+
+// NoteItem 社区笔记列表项
+type NoteItem struct {
+	Note
+	Author UserBrief `json:"author"` // 笔记作者
+}
+
+// CommunityNoteResponse 社区笔记列表响应
+type CommunityNoteResponse struct {
+	Notes []NoteItem `json:"notes"`
+	Total int64      `json:"total"`
+	Page  int        `json:"page"`
+	Size  int        `json:"size"`
+}
+
+// 笔记历史版本的产生方式
+const (
+	NoteRevisionCreate  = "create"  // 创建笔记
+	NoteRevisionUpdate  = "update"  // 修改笔记
+	NoteRevisionRestore = "restore" // 恢复到历史版本
+	NoteRevisionInitial = "initial" // 首次修改没有历史记录的旧笔记时补记的原始内容
+)
+
+// NoteRevision 笔记历史版本，保存修改后的完整快照
+type NoteRevision struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	NoteID       primitive.ObjectID `bson:"note_id" json:"noteId"`
+	UserID       string             `bson:"user_id" json:"userId"`                         // 笔记作者
+	EditedBy     string             `bson:"edited_by,omitempty" json:"editedBy,omitempty"` // 房间成员修改他人笔记时的修改人
+	VideoID      string             `bson:"video_id" json:"videoId"`                       // 笔记所在的视频
+	Version      int                `bson:"version" json:"version"`
+	Action       string             `bson:"action" json:"action"`
+	RestoredFrom int                `bson:"restored_from,omitempty" json:"restoredFrom,omitempty"` // 恢复自哪个版本
+	Content      string             `bson:"content" json:"content,omitempty"`                      // 列表中不返回
+	ContentHTML  string             `bson:"-" json:"contentHtml,omitempty"`                        // 渲染并过滤后的HTML
+	Timestamp    float64            `bson:"timestamp" json:"timestamp"`
+	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// NoteImage 笔记中插入的图片，保存笔记时关联到笔记，删除笔记时一并删除
+type NoteImage struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID    string             `bson:"user_id" json:"userId"`       // 用户ID
-	VideoID   string             `bson:"video_id" json:"videoId"`     // 视频ID
-	Timestamp float64            `bson:"timestamp" json:"timestamp"`  // 时间戳
-	Content   string             `bson:"content" json:"content"`      // 笔记内容
-	CreatedAt time.Time          `bson:"created_at" json:"createdAt"` // 创建时间
-	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"` // 更新时间
+	UserID    string             `bson:"user_id" json:"userId"`
+	NoteID    primitive.ObjectID `bson:"note_id,omitempty" json:"-"` // 未保存到笔记前为空
+	FileName  string             `bson:"file_name" json:"-"`         // 上传目录中的文件名
+	URL       string             `bson:"url" json:"url"`
+	Size      int64              `bson:"size" json:"size"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
 }
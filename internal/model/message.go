@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 私信消息类型
+const (
+	MessageTypeText  = "text"  // 文本
+	MessageTypeEmoji = "emoji" // 表情
+)
+
+// IsValidMessageType 验证私信消息类型
+func IsValidMessageType(t string) bool {
+	switch t {
+	case MessageTypeText, MessageTypeEmoji:
+		return true
+	default:
+		return false
+	}
+}
+
+// Conversation 一对一会话
+type Conversation struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Key          string             `bson:"key" json:"-"`                              // 会话唯一键，由双方ID排序后拼接
+	Members      []string           `bson:"members" json:"members"`                    // 会话双方ID
+	LastSeq      int64              `bson:"last_seq" json:"lastSeq"`                   // 最新消息序号
+	LastMessage  *Message           `bson:"last_message,omitempty" json:"lastMessage"` // 最新一条消息
+	DeliveredSeq map[string]int64   `bson:"delivered_seq" json:"deliveredSeq"`         // 各成员已送达的消息序号
+	ReadSeq      map[string]int64   `bson:"read_seq" json:"readSeq"`                   // 各成员已读的消息序号
+	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// Message 私信消息
+type Message struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id" json:"conversationId"`
+	Seq            int64              `bson:"seq" json:"seq"`                                       // 会话内递增序号，用于排序和缺失检测
+	SenderID       string             `bson:"sender_id" json:"senderId"`                            // 发送者ID
+	ReceiverID     string             `bson:"receiver_id" json:"receiverId"`                        // 接收者ID
+	Type           string             `bson:"type" json:"type"`                                     // 消息类型
+	Content        string             `bson:"content" json:"content"`                               // 消息内容
+	ClientMsgID    string             `bson:"client_msg_id,omitempty" json:"clientMsgId,omitempty"` // 客户端消息ID，用于重发去重
+	CreatedAt      time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// OpenConversationRequest 打开会话请求
+type OpenConversationRequest struct {
+	UserID string `json:"userId" binding:"required"` // 对方ID
+}
+
+// SendMessageRequest 发送私信请求
+type SendMessageRequest struct {
+	ToUserID    string `json:"toUserId" binding:"required"`
+	Type        string `json:"type"` // 默认为text
+	Content     string `json:"content" binding:"required,min=1,max=1000"`
+	ClientMsgID string `json:"clientMsgId" binding:"max=64"`
+}
+
+// MessageQuery 消息查询参数
+type MessageQuery struct {
+	AfterSeq  int64 `form:"afterSeq"`  // 获取该序号之后的消息（同步/补齐缺失）
+	BeforeSeq int64 `form:"beforeSeq"` // 获取该序号之前的消息（向上翻页）
+	Limit     int   `form:"limit"`
+}
+
+// MessageAckRequest 送达/已读回执
+type MessageAckRequest struct {
+	ConversationID string `json:"conversationId"`
+	Seq            int64  `json:"seq" binding:"min=1"`
+}
+
+// ConversationItem 会话列表项
+type ConversationItem struct {
+	ID          primitive.ObjectID `json:"id"`
+	Peer        UserBrief          `json:"peer"`        // 对方信息
+	LastSeq     int64              `json:"lastSeq"`     // 最新消息序号
+	LastMessage *Message           `json:"lastMessage"` // 最新一条消息
+	ReadSeq     int64              `json:"readSeq"`     // 当前用户已读的序号
+	PeerReadSeq int64              `json:"peerReadSeq"` // 对方已读的序号
+	Unread      int64              `json:"unread"`      // 未读数
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
+
+// MessageReceipt 回执推送
+type MessageReceipt struct {
+	ConversationID string `json:"conversationId"`
+	UserID         string `json:"userId"`                 // 回执发出者
+	DeliveredSeq   int64  `json:"deliveredSeq,omitempty"` // 已送达的序号
+	ReadSeq        int64  `json:"readSeq,omitempty"`      // 已读的序号
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 举报对象类型
+const (
+	ReportTargetVideo   = "video"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+	ReportTargetMark    = "mark"
+)
+
+// 举报原因
+const (
+	ReportReasonSpam       = "spam"       // 垃圾广告
+	ReportReasonAbuse      = "abuse"      // 辱骂攻击
+	ReportReasonSexual     = "sexual"     // 色情低俗
+	ReportReasonViolence   = "violence"   // 暴力血腥
+	ReportReasonIllegal    = "illegal"    // 违法违规
+	ReportReasonCopyright  = "copyright"  // 侵犯版权
+	ReportReasonMisleading = "misleading" // 虚假误导
+	ReportReasonOther      = "other"      // 其他
+)
+
+// ReportReason 举报原因分类
+type ReportReason struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// ReportReasons 可选的举报原因，按展示顺序排列
+var ReportReasons = []ReportReason{
+	{ReportReasonSpam, "垃圾广告"},
+	{ReportReasonAbuse, "辱骂攻击"},
+	{ReportReasonSexual, "色情低俗"},
+	{ReportReasonViolence, "暴力血腥"},
+	{ReportReasonIllegal, "违法违规"},
+	{ReportReasonCopyright, "侵犯版权"},
+	{ReportReasonMisleading, "虚假误导"},
+	{ReportReasonOther, "其他"},
+}
+
+// IsValidReportTarget 验证举报对象类型
+func IsValidReportTarget(t string) bool {
+	switch t {
+	case ReportTargetVideo, ReportTargetComment, ReportTargetUser, ReportTargetMark:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidReportReason 验证举报原因
+func IsValidReportReason(reason string) bool {
+	for _, r := range ReportReasons {
+		if r.Code == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// 审核工单状态
+const (
+	ModerationStatusPending   = "pending"   // 待领取
+	ModerationStatusClaimed   = "claimed"   // 已领取，处理中
+	ModerationStatusResolved  = "resolved"  // 已处理（违规）
+	ModerationStatusDismissed = "dismissed" // 已驳回（不违规）
+)
+
+// 审核操作
+const (
+	ModerationActionAutoHide = "auto_hide" // 举报数达到阈值自动隐藏
+	ModerationActionClaim    = "claim"     // 领取工单
+	ModerationActionRemove   = "remove"    // 下架视频、隐藏评论或标记、禁用用户
+	ModerationActionWarn     = "warn"      // 警告作者，保留内容
+	ModerationActionDismiss  = "dismiss"   // 驳回举报，恢复自动隐藏的内容
+)
+
+// ModerationActorSystem 自动操作在审核记录中的操作者
+const ModerationActorSystem = "system"
+
+// Report 举报记录
+type Report struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CaseID      primitive.ObjectID `bson:"case_id" json:"caseId"`         // 所属审核工单ID
+	ReporterID  string             `bson:"reporter_id" json:"reporterId"` // 举报者ID
+	TargetType  string             `bson:"target_type" json:"targetType"` // 举报对象类型
+	TargetID    string             `bson:"target_id" json:"targetId"`     // 举报对象ID
+	Reason      string             `bson:"reason" json:"reason"`          // 举报原因
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// ModerationCase 审核工单，同一对象未处理的举报合并到一个工单
+type ModerationCase struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TargetType  string             `bson:"target_type" json:"targetType"`
+	TargetID    string             `bson:"target_id" json:"targetId"`
+	OwnerID     string             `bson:"owner_id" json:"ownerId"`         // 被举报内容的作者（举报用户时为该用户）
+	Open        bool               `bson:"open" json:"-"`                   // 是否未处理，用于保证同一对象只有一个未处理的工单
+	Status      string             `bson:"status" json:"status"`            // 工单状态
+	ReportCount int64              `bson:"report_count" json:"reportCount"` // 举报次数
+	Reasons     map[string]int64   `bson:"reasons" json:"reasons"`          // 各举报原因的次数
+	Hidden      bool               `bson:"hidden" json:"hidden"`            // 内容当前是否已被隐藏
+	PrevStatus  string             `bson:"prev_status,omitempty" json:"-"`  // 视频被下架前的状态，驳回时恢复
+	ClaimedBy   string             `bson:"claimed_by,omitempty" json:"claimedBy,omitempty"`
+	ClaimedAt   *time.Time         `bson:"claimed_at,omitempty" json:"claimedAt,omitempty"`
+	Action      string             `bson:"action,omitempty" json:"action,omitempty"` // 处理结果
+	Note        string             `bson:"note,omitempty" json:"note,omitempty"`     // 处理说明
+	ResolvedBy  string             `bson:"resolved_by,omitempty" json:"resolvedBy,omitempty"`
+	ResolvedAt  *time.Time         `bson:"resolved_at,omitempty" json:"resolvedAt,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// ModerationLog 审核记录，记录审核员和系统对工单的每一次操作
+type ModerationLog struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CaseID      primitive.ObjectID `bson:"case_id" json:"caseId"`
+	TargetType  string             `bson:"target_type" json:"targetType"`
+	TargetID    string             `bson:"target_id" json:"targetId"`
+	ModeratorID string             `bson:"moderator_id" json:"moderatorId"` // 审核员ID，自动操作为 system
+	Action      string             `bson:"action" json:"action"`
+	Note        string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// CreateReportRequest 举报请求
+type CreateReportRequest struct {
+	TargetType  string `json:"targetType" binding:"required"`
+	TargetID    string `json:"targetId" binding:"required"`
+	Reason      string `json:"reason" binding:"required"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// ResolveCaseRequest 处理审核工单请求
+type ResolveCaseRequest struct {
+	Action string `json:"action" binding:"required,oneof=remove warn"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// DismissCaseRequest 驳回审核工单请求
+type DismissCaseRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// ModerationQuery 审核队列查询参数
+type ModerationQuery struct {
+	Status     string `form:"status"` // 默认为待领取
+	TargetType string `form:"targetType"`
+	Page       int    `form:"page"`
+	Size       int    `form:"size"`
+}
+
+// ModerationLogQuery 审核记录查询参数
+type ModerationLogQuery struct {
+	ModeratorID string `form:"moderatorId"`
+	CaseID      string `form:"caseId"`
+	Page        int    `form:"page"`
+	Size        int    `form:"size"`
+}
+
+// ModerationCaseListResponse 审核队列响应
+type ModerationCaseListResponse struct {
+	Cases []ModerationCase `json:"cases"`
+	Total int64            `json:"total"`
+	Page  int              `json:"page"`
+	Size  int              `json:"size"`
+}
+
+// ModerationCaseDetail 审核工单详情
+type ModerationCaseDetail struct {
+	ModerationCase
+	Reports []Report        `json:"reports"` // 最近的举报记录
+	Logs    []ModerationLog `json:"logs"`    // 审核记录
+}
+
+// ModerationLogListResponse 审核记录列表响应
+type ModerationLogListResponse struct {
+	Logs  []ModerationLog `json:"logs"`
+	Total int64           `json:"total"`
+	Page  int             `json:"page"`
+	Size  int             `json:"size"`
+}
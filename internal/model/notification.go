@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 通知类型
+const (
+	NotificationTypeLike       = "like"        // 点赞视频
+	NotificationTypeFavorite   = "favorite"    // 收藏视频
+	NotificationTypeComment    = "comment"     // 评论视频或回复评论
+	NotificationTypeAnnotation = "annotation"  // 注释了标记
+	NotificationTypeFollow     = "follow"      // 关注
+	NotificationTypeMention    = "mention"     // @提及
+	NotificationTypeSystem     = "system"      // 系统通知
+	NotificationTypeRoomInvite = "room_invite" // 邀请加入协作标注房间
+)
+
+// 通知关联的对象类型
+const (
+	NotificationTargetVideo   = "video"
+	NotificationTargetComment = "comment"
+	NotificationTargetMark    = "mark"
+	NotificationTargetUser    = "user"
+	NotificationTargetRoom    = "room"
+)
+
+// IsValidNotificationType 验证通知类型
+func IsValidNotificationType(t string) bool {
+	switch t {
+	case NotificationTypeLike, NotificationTypeFavorite, NotificationTypeComment,
+		NotificationTypeAnnotation, NotificationTypeFollow, NotificationTypeMention,
+		NotificationTypeSystem, NotificationTypeRoomInvite:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsAggregatedNotification 是否为聚合通知（同一对象的未读通知合并为一条）
+func IsAggregatedNotification(t string) bool {
+	switch t {
+	case NotificationTypeLike, NotificationTypeFavorite, NotificationTypeFollow:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification 通知
+type Notification struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     string             `bson:"user_id" json:"userId"`                      // 接收者ID
+	Type       string             `bson:"type" json:"type"`                           // 通知类型
+	TargetType string             `bson:"target_type,omitempty" json:"targetType"`    // 关联对象类型
+	TargetID   string             `bson:"target_id,omitempty" json:"targetId"`        // 关联对象ID
+	ActorIDs   []string           `bson:"actor_ids" json:"actorIds"`                  // 最近的触发者ID（聚合通知最多保留若干个）
+	ActorCount int64              `bson:"actor_count" json:"actorCount"`              // 触发者人数
+	Content    string             `bson:"content,omitempty" json:"content,omitempty"` // 内容摘要，如视频标题、评论内容
+	Read       bool               `bson:"read" json:"read"`                           // 是否已读
+	Aggregated bool               `bson:"aggregated,omitempty" json:"-"`              // 是否为聚合通知，未读聚合通知的唯一索引只包含这类通知
+	CreatedAt  time.Time          `bson:"created_at" json:"createdAt"`                // 创建时间
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updatedAt"`                // 最近一次触发时间
+}
+
+// NotificationItem 通知列表项
+type NotificationItem struct {
+	Notification
+	Actors  []UserBrief `json:"actors"`  // 触发者信息
+	Message string      `json:"message"` // 展示文案，如"张三等12人收藏了你的视频"
+}
+
+// NotificationQuery 通知查询参数
+type NotificationQuery struct {
+	Type   string `form:"type"`
+	Unread bool   `form:"unread"` // 只看未读
+	Page   int    `form:"page"`
+	Size   int    `form:"size"`
+}
+
+// NotificationListResponse 通知列表响应
+type NotificationListResponse struct {
+	Notifications []NotificationItem `json:"notifications"`
+	Total         int64              `json:"total"`
+	Unread        int64              `json:"unread"` // 未读总数
+	Page          int                `json:"page"`
+	Size          int                `json:"size"`
+}
+
+// MarkNotificationsReadRequest 标记已读请求
+type MarkNotificationsReadRequest struct {
+	IDs []string `json:"ids"` // 为空时全部标记为已读
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 播放列表可见性
+const (
+	PlaylistVisibilityPublic  = "public"  // 公开
+	PlaylistVisibilityPrivate = "private" // 仅自己可见
+)
+
+// Playlist 播放列表（如系列课程），视频按 Position 顺序播放
+type Playlist struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      string             `bson:"user_id" json:"userId"`          // 创建者ID
+	Title       string             `bson:"title" json:"title"`             // 标题
+	Description string             `bson:"description" json:"description"` // 简介
+	Visibility  string             `bson:"visibility" json:"visibility"`   // 可见性
+	ItemCount   int64              `bson:"item_count" json:"itemCount"`    // 视频数
+	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// PlaylistItem 播放列表中的视频
+type PlaylistItem struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PlaylistID    string             `bson:"playlist_id" json:"playlistId"`
+	VideoID       string             `bson:"video_id" json:"videoId"`
+	Position      int64              `bson:"position" json:"position"` // 排序位置，越小越靠前
+	VideoTitle    string             `bson:"video_title" json:"videoTitle"`
+	CoverURL      string             `bson:"cover_url" json:"coverUrl"`
+	VideoDuration float64            `bson:"video_duration" json:"videoDuration"`
+	AddedAt       time.Time          `bson:"added_at" json:"addedAt"`
+}
+
+// CreatePlaylistRequest 创建播放列表请求
+type CreatePlaylistRequest struct {
+	Title       string `json:"title" binding:"required,min=1,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public private"` // 默认公开
+}
+
+// UpdatePlaylistRequest 更新播放列表请求，只更新非空字段
+type UpdatePlaylistRequest struct {
+	Title       string  `json:"title" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	Visibility  string  `json:"visibility" binding:"omitempty,oneof=public private"`
+}
+
+// AddPlaylistItemRequest 向播放列表添加视频请求
+type AddPlaylistItemRequest struct {
+	VideoID string `json:"videoId" binding:"required"`
+}
+
+// ReorderPlaylistRequest 调整播放列表顺序请求，需包含列表中的全部视频
+type ReorderPlaylistRequest struct {
+	VideoIDs []string `json:"videoIds" binding:"required,min=1"`
+}
+
+// PlaylistDetail 播放列表详情
+type PlaylistDetail struct {
+	Playlist
+	Items []PlaylistItem `json:"items"`
+}
+
+// PlaylistListResponse 播放列表分页响应
+type PlaylistListResponse struct {
+	Playlists []Playlist `json:"playlists"`
+	Total     int64      `json:"total"`
+	Page      int        `json:"page"`
+	Size      int        `json:"size"`
+}
+
+// PlaylistNavigation 从播放列表进入视频详情时的播放位置
+type PlaylistNavigation struct {
+	PlaylistID string        `json:"playlistId"`
+	Title      string        `json:"title"`
+	Index      int           `json:"index"` // 当前视频在列表中的序号，从1开始
+	Total      int           `json:"total"`
+	Prev       *PlaylistItem `json:"prev"` // 上一个视频，已是第一个时为空
+	Next       *PlaylistItem `json:"next"` // 下一个视频，已是最后一个时为空
+}
+
+// FavoriteFolderDefault 查询收藏列表时表示默认收藏夹（未归入任何收藏夹的收藏）
+const FavoriteFolderDefault = "default"
+
+// FavoriteFolder 收藏夹，收藏记录可以归入不同的收藏夹，未归入的属于默认收藏夹
+type FavoriteFolder struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"user_id" json:"userId"`
+	Name      string             `bson:"name" json:"name"`
+	Count     int64              `bson:"-" json:"count"` // 收藏数，查询时统计
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// FavoriteFolderRequest 创建/重命名收藏夹请求
+type FavoriteFolderRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+// FavoriteFolderListResponse 收藏夹列表响应
+type FavoriteFolderListResponse struct {
+	DefaultCount int64            `json:"defaultCount"` // 默认收藏夹中的收藏数
+	Folders      []FavoriteFolder `json:"folders"`
+}
+
+// AddFavoriteRequest 添加收藏请求，FolderID为空表示放入默认收藏夹
+type AddFavoriteRequest struct {
+	FolderID string `json:"folderId"`
+}
+
+// MoveFavoriteRequest 将收藏移动到其他收藏夹，FolderID为空表示移回默认收藏夹
+type MoveFavoriteRequest struct {
+	FolderID string `json:"folderId"`
+}
@@ -0,0 +1,49 @@
+package model
+
+// 排行榜周期
+const (
+	RankingPeriodHourly = "hourly" // 小时榜
+	RankingPeriodDaily  = "daily"  // 日榜
+	RankingPeriodWeekly = "weekly" // 周榜
+)
+
+// 计入排行榜的互动事件
+const (
+	RankingEventView    = "view"
+	RankingEventLike    = "like"
+	RankingEventComment = "comment"
+	RankingEventShare   = "share"
+)
+
+// IsValidRankingPeriod 检查排行榜周期是否有效
+func IsValidRankingPeriod(period string) bool {
+	switch period {
+	case RankingPeriodHourly, RankingPeriodDaily, RankingPeriodWeekly:
+		return true
+	}
+	return false
+}
+
+// RankingQuery 排行榜查询参数
+type RankingQuery struct {
+	Tag  string `form:"tag"` // 按标签筛选，为空表示全站榜
+	Page int    `form:"page"`
+	Size int    `form:"size"`
+}
+
+// RankingItem 排行榜中的视频
+type RankingItem struct {
+	Rank  int64   `json:"rank"`  // 名次，从1开始
+	Score float64 `json:"score"` // 热度分（已按时间衰减）
+	FeedItem
+}
+
+// RankingResponse 排行榜响应
+type RankingResponse struct {
+	Period string        `json:"period"`
+	Tag    string        `json:"tag,omitempty"`
+	Items  []RankingItem `json:"items"`
+	Total  int64         `json:"total"` // 榜单中的视频总数
+	Page   int           `json:"page"`
+	Size   int           `json:"size"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// RecommendCandidate 推荐候选视频，由离线任务定期刷新
+type RecommendCandidate struct {
+	VideoID     string    `bson:"_id" json:"videoId"`
+	UserID      string    `bson:"user_id" json:"userId"`           // 作者ID
+	Tags        []string  `bson:"tags" json:"tags"`                // 视频标签
+	Popularity  float64   `bson:"popularity" json:"popularity"`    // 热度分，由互动数据计算
+	CreatedAt   time.Time `bson:"created_at" json:"createdAt"`     // 视频发布时间
+	RefreshedAt time.Time `bson:"refreshed_at" json:"refreshedAt"` // 候选池刷新时间
+}
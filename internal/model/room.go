@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AnnotationRoom 协作标注房间：成员在同一个视频上添加到房间的标记和笔记彼此可见并实时同步
+type AnnotationRoom struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	VideoID   string             `bson:"video_id" json:"videoId"`
+	OwnerID   string             `bson:"owner_id" json:"ownerId"` // 创建者，可以邀请和移除成员
+	Name      string             `bson:"name" json:"name"`
+	Members   []string           `bson:"members" json:"members"` // 成员ID，包含创建者
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+// IsMember 用户是否为房间成员
+func (r *AnnotationRoom) IsMember(userID string) bool {
+	for _, id := range r.Members {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateRoomRequest 创建协作标注房间请求
+type CreateRoomRequest struct {
+	VideoID string   `json:"videoId" binding:"required"`
+	Name    string   `json:"name" binding:"required,min=1,max=50"`
+	Members []string `json:"members"` // 邀请的用户ID
+}
+
+// RoomMembersRequest 邀请房间成员请求
+type RoomMembersRequest struct {
+	UserIDs []string `json:"userIds" binding:"required,min=1"`
+}
+
+// RoomPresence 房间成员的在线状态和播放位置
+type RoomPresence struct {
+	UserID    string    `json:"userId"`
+	Timestamp float64   `json:"timestamp"` // 当前播放位置（秒）
+	Playing   bool      `json:"playing"`   // 是否正在播放
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RoomDetail 房间详情，包含成员信息、房间内的标记和笔记以及在线成员
+type RoomDetail struct {
+	AnnotationRoom
+	MemberInfo []UserBrief    `json:"memberInfo"`
+	Marks      []Mark         `json:"marks"`
+	Notes      []Note         `json:"notes"`
+	Presence   []RoomPresence `json:"presence"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// 搜索结果类型
+const (
+	SearchTypeMark       = "mark"
+	SearchTypeAnnotation = "annotation"
+	SearchTypeNote       = "note"
+)
+
+// SearchQuery 标记、注释和笔记搜索参数
+type SearchQuery struct {
+	Keyword string    `form:"q" binding:"required"`
+	Type    string    `form:"type" binding:"omitempty,oneof=mark annotation note"` // 只搜索某一类
+	VideoID string    `form:"videoId"`                                             // 只搜索某个视频
+	From    time.Time `form:"from" time_format:"2006-01-02"`                       // 创建日期不早于
+	To      time.Time `form:"to" time_format:"2006-01-02"`                         // 创建日期不晚于（含当天）
+	Page    int       `form:"page"`
+	Size    int       `form:"size"`
+}
+
+// SearchHit 搜索结果，Timestamp 为标记或笔记所在的视频时间，注释使用所属标记的时间
+type SearchHit struct {
+	Type       string    `json:"type"`
+	ID         string    `json:"id"`
+	MarkID     string    `json:"markId,omitempty"` // 注释所属的标记
+	VideoID    string    `json:"videoId"`
+	VideoTitle string    `json:"videoTitle"`
+	Timestamp  float64   `json:"timestamp"`
+	EndTime    float64   `json:"endTime,omitempty"`
+	Content    string    `json:"content"`
+	Highlight  string    `json:"highlight"` // 命中片段，已转义HTML，命中词用<em>包裹
+	Score      int       `json:"score"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SearchResponse 搜索结果列表
+type SearchResponse struct {
+	Hits      []SearchHit `json:"hits"`
+	Terms     []string    `json:"terms"` // 查询分词结果
+	Total     int         `json:"total"`
+	Page      int         `json:"page"`
+	Size      int         `json:"size"`
+	Truncated bool        `json:"truncated"` // 某类记录的匹配数超过候选上限，只有最近的候选参与排序和分页
+}
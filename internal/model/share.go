@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 分享渠道
+const (
+	ShareChannelWechat   = "wechat"    // 微信
+	ShareChannelMoments  = "moments"   // 朋友圈
+	ShareChannelWeibo    = "weibo"     // 微博
+	ShareChannelQQ       = "qq"        // QQ
+	ShareChannelCopyLink = "copy-link" // 复制链接
+	ShareChannelOther    = "other"     // 其他
+)
+
+// IsValidShareChannel 验证分享渠道
+func IsValidShareChannel(channel string) bool {
+	switch channel {
+	case ShareChannelWechat, ShareChannelMoments, ShareChannelWeibo,
+		ShareChannelQQ, ShareChannelCopyLink, ShareChannelOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// ShareLink 分享短链接
+type ShareLink struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Code      string             `bson:"code" json:"code"`            // 短链接码
+	VideoID   string             `bson:"video_id" json:"videoId"`     // 视频ID
+	UserID    string             `bson:"user_id" json:"userId"`       // 分享者ID，游客分享为空
+	Channel   string             `bson:"channel" json:"channel"`      // 分享渠道
+	Shares    int64              `bson:"shares" json:"shares"`        // 计入视频分享数的次数，同一用户每个渠道只计一次，游客分享不计
+	Clicks    int64              `bson:"clicks" json:"clicks"`        // 点击次数
+	Views     int64              `bson:"views" json:"views"`          // 通过该链接带来的播放次数
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"` // 创建时间
+	UpdatedAt time.Time          `bson:"updated_at" json:"updatedAt"` // 最近一次分享时间
+}
+
+// ShareEvent 一次计入分享数的分享，按时间记录用于重建热门排行榜，过期后自动删除
+type ShareEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	VideoID   string             `bson:"video_id"`   // 视频ID
+	UserID    string             `bson:"user_id"`    // 分享者ID
+	Channel   string             `bson:"channel"`    // 分享渠道
+	CreatedAt time.Time          `bson:"created_at"` // 分享时间
+}
+
+// CreateShareRequest 创建分享链接请求
+type CreateShareRequest struct {
+	Channel string `json:"channel" binding:"required"`
+}
+
+// ShareLinkResponse 创建分享链接响应
+type ShareLinkResponse struct {
+	Code    string `json:"code"`    // 短链接码
+	URL     string `json:"url"`     // 完整短链接
+	Channel string `json:"channel"` // 分享渠道
+}
+
+// ShareChannelStats 单个渠道的分享统计
+type ShareChannelStats struct {
+	Channel string `bson:"_id" json:"channel"`
+	Links   int64  `bson:"links" json:"links"`   // 链接数
+	Shares  int64  `bson:"shares" json:"shares"` // 分享次数
+	Clicks  int64  `bson:"clicks" json:"clicks"` // 点击次数
+	Views   int64  `bson:"views" json:"views"`   // 带来的播放次数
+}
+
+// ShareStatsResponse 视频分享统计
+type ShareStatsResponse struct {
+	VideoID  string              `json:"videoId"`
+	Shares   int64               `json:"shares"` // 总分享次数
+	Clicks   int64               `json:"clicks"` // 总点击次数
+	Views    int64               `json:"views"`  // 分享带来的总播放次数
+	Channels []ShareChannelStats `json:"channels"`
+}
@@ -6,6 +6,12 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// 用户状态
+const (
+	UserStatusNormal   = 1 // 正常
+	UserStatusDisabled = 2 // 禁用
+)
+
 // User 用户模型
 type User struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -56,6 +62,9 @@ type UserStats struct {
 	UploadedVideos int64 `json:"uploadedVideos" bson:"uploaded_videos"`
 	TotalWatchTime int64 `json:"totalWatchTime" bson:"total_watch_time"` // 单位：分钟
 	TotalLikes     int64 `json:"totalLikes" bson:"total_likes"`
+	Following      int64 `json:"following" bson:"following"`    // 关注数
+	Followers      int64 `json:"followers" bson:"followers"`    // 粉丝数
+	WatchLater     int64 `json:"watchLater" bson:"watch_later"` // 稍后再看数
 }
 
 // UpdateProfileRequest 更新用户资料请求
@@ -96,6 +105,7 @@ type Favorite struct {
 	CoverURL      string             `bson:"cover_url" json:"coverUrl"`
 	AddedAt       time.Time          `bson:"added_at" json:"addedAt"`
 	VideoDuration float64            `bson:"video_duration" json:"videoDuration"`
+	FolderID      string             `bson:"folder_id,omitempty" json:"folderId,omitempty"` // 所属收藏夹，为空表示默认收藏夹
 }
 
 // FavoriteResponse 收藏列表响应
@@ -104,4 +114,12 @@ type FavoriteResponse struct {
 	Total     int64      `json:"total"`
 	Page      int        `json:"page"`
 	Size      int        `json:"size"`
-}
\ No newline at end of file
+}
+
+// VideoLike 用户点赞
+type VideoLike struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"user_id" json:"userId"`
+	VideoID   string             `bson:"video_id" json:"videoId"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
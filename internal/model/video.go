@@ -11,9 +11,10 @@ const (
 	VideoStatusPublic  = "public"  // 公开
 	VideoStatusPrivate = "private" // 私有
 	VideoStatusDraft   = "draft"   // 草稿
+	VideoStatusBlocked = "blocked" // 已下架（违规被审核员下架或被举报自动隐藏），作者不能修改
 )
 
-// 添加状态验证函数
+// 添加状态验证函数（只包含作者可以设置的状态）
 func IsValidVideoStatus(status string) bool {
 	switch status {
 	case VideoStatusPublic, VideoStatusPrivate, VideoStatusDraft:
@@ -26,28 +27,30 @@ func IsValidVideoStatus(status string) bool {
 // Video 视频模型
 type Video struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID       string             `bson:"user_id" json:"userId"`             // 作者ID
-	Title        string             `bson:"title" json:"title"`                // 视频标题
-	Description  string             `bson:"description" json:"description"`    // 视频描述
-	FileName     string             `bson:"file_name" json:"fileName"`         // 文件名
-	FileSize     int64              `bson:"file_size" json:"fileSize"`         // 文件大小（字节）
-	Duration     float64            `bson:"duration" json:"duration"`          // 视频时长（秒）
-	Format       string             `bson:"format" json:"format"`              // 视频格式
-	Status       string             `bson:"status" json:"status"`              // 视频状态
-	Tags         []string           `bson:"tags" json:"tags"`                  // 视频标签
-	ThumbnailURL string             `bson:"thumbnail_url" json:"thumbnailUrl"` // 缩略图URL
-	CoverURL     string             `bson:"cover_url" json:"coverUrl"`         // 封面图URL
-	Stats        VideoStats         `bson:"stats" json:"stats"`                // 视频统计信息
-	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`       // 创建时间
-	UpdatedAt    time.Time          `bson:"updated_at" json:"updatedAt"`       // 更新时间
+	UserID       string             `bson:"user_id" json:"userId"`                        // 作者ID
+	Title        string             `bson:"title" json:"title"`                           // 视频标题
+	Description  string             `bson:"description" json:"description"`               // 视频描述
+	FileName     string             `bson:"file_name" json:"fileName"`                    // 文件名
+	FileSize     int64              `bson:"file_size" json:"fileSize"`                    // 文件大小（字节）
+	Duration     float64            `bson:"duration" json:"duration"`                     // 视频时长（秒）
+	Format       string             `bson:"format" json:"format"`                         // 视频格式
+	Status       string             `bson:"status" json:"status"`                         // 视频状态
+	Tags         []string           `bson:"tags" json:"tags"`                             // 视频标签
+	ThumbnailURL string             `bson:"thumbnail_url" json:"thumbnailUrl"`            // 缩略图URL
+	CoverURL     string             `bson:"cover_url" json:"coverUrl"`                    // 封面图URL
+	Stats        VideoStats         `bson:"stats" json:"stats"`                           // 视频统计信息
+	Chapters     []Chapter          `bson:"chapters,omitempty" json:"chapters,omitempty"` // 章节，按开始时间排序，未设置时不返回
+	CreatedAt    time.Time          `bson:"created_at" json:"createdAt"`                  // 创建时间
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updatedAt"`                  // 更新时间
 }
 
 // VideoStats 视频统计信息
 type VideoStats struct {
-	Views    int64 `bson:"views" json:"views"`       // 观看次数
-	Likes    int64 `bson:"likes" json:"likes"`       // 点赞数
-	Comments int64 `bson:"comments" json:"comments"` // 评论数
-	Shares   int64 `bson:"shares" json:"shares"`     // 分享次数
+	Views     int64 `bson:"views" json:"views"`         // 观看次数
+	Likes     int64 `bson:"likes" json:"likes"`         // 点赞数
+	Favorites int64 `bson:"favorites" json:"favorites"` // 收藏数
+	Comments  int64 `bson:"comments" json:"comments"`   // 评论数
+	Shares    int64 `bson:"shares" json:"shares"`       // 分享次数
 }
 
 // VideoList 视频列表响应结构
@@ -58,23 +61,23 @@ type VideoList struct {
 
 // VideoQuery 视频查询参数
 type VideoQuery struct {
-	Page      int    `form:"page" binding:"min=1"`                                              // 页码
-	PageSize  int    `form:"pageSize" binding:"min=1,max=50"`                                   // 每页数量
-	Keyword   string `form:"keyword"`                                                           // 关键词搜索（标题、描述）
-	Status    string `form:"status"`                                                            // 视频状态
-	StartDate string `form:"startDate"`                                                         // 开始日期
-	EndDate   string `form:"endDate"`                                                           // 结束日期
-	Tags      string `form:"tags"`                                                              // 标签（逗号分隔）
-	SortBy    string `form:"sortBy" binding:"omitempty,oneof=created_at views likes file_size"` // 排序字段
-	SortOrder string `form:"sortOrder" binding:"omitempty,oneof=asc desc"`                      // 排序方向
+	Page      int    `form:"page" binding:"min=1"`                                                        // 页码
+	PageSize  int    `form:"pageSize" binding:"min=1,max=50"`                                             // 每页数量
+	Keyword   string `form:"keyword"`                                                                     // 关键词搜索（标题、描述）
+	Status    string `form:"status"`                                                                      // 视频状态
+	StartDate string `form:"startDate"`                                                                   // 开始日期
+	EndDate   string `form:"endDate"`                                                                     // 结束日期
+	Tags      string `form:"tags"`                                                                        // 标签（逗号分隔）
+	SortBy    string `form:"sortBy" binding:"omitempty,oneof=created_at views likes favorites file_size"` // 排序字段
+	SortOrder string `form:"sortOrder" binding:"omitempty,oneof=asc desc"`                                // 排序方向
 }
 
 // BatchOperationRequest 批量操作请求
 type BatchOperationRequest struct {
-	IDs     []string `json:"ids" binding:"required"`
-	Action  string   `json:"action" binding:"required"`
-	Status  string   `json:"status"`
-	UserID  string   `json:"-"` // 用于权限检查，不从请求中获取
+	IDs    []string `json:"ids" binding:"required"`
+	Action string   `json:"action" binding:"required"`
+	Status string   `json:"status"`
+	UserID string   `json:"-"` // 用于权限检查，不从请求中获取
 }
 
 // BatchOperationResult 批量操作结果
@@ -92,3 +95,24 @@ type VideoUpdateRequest struct {
 	Tags         []string `json:"tags"`
 	ThumbnailURL string   `json:"thumbnail"`
 }
+
+// FeedQuery 动态流查询参数
+type FeedQuery struct {
+	Cursor string `form:"cursor"` // 上一页返回的游标，首页为空
+	Size   int    `form:"size"`
+}
+
+// FeedItem 动态流中的视频
+type FeedItem struct {
+	Video      Video     `json:"video"`
+	Author     UserBrief `json:"author"`     // 作者信息
+	IsLiked    bool      `json:"isLiked"`    // 当前用户是否已点赞
+	IsFavorite bool      `json:"isFavorite"` // 当前用户是否已收藏
+}
+
+// FeedResponse 动态流响应（游标分页）
+type FeedResponse struct {
+	Items      []FeedItem `json:"items"`
+	NextCursor string     `json:"nextCursor"` // 下一页游标，为空表示没有更多
+	HasMore    bool       `json:"hasMore"`
+}
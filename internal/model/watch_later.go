@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WatchLater 稍后再看，与收藏一样冗余保存视频标题和封面，视频信息变更时由 VideoChanged 事件同步更新
+type WatchLater struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        string             `bson:"user_id" json:"userId"`
+	VideoID       string             `bson:"video_id" json:"videoId"`
+	VideoTitle    string             `bson:"video_title" json:"videoTitle"`
+	CoverURL      string             `bson:"cover_url" json:"coverUrl"`
+	VideoDuration float64            `bson:"video_duration" json:"videoDuration"`
+	Position      int64              `bson:"position" json:"position"` // 排序位置，越小越靠前
+	AddedAt       time.Time          `bson:"added_at" json:"addedAt"`
+}
+
+// WatchLaterResponse 稍后再看列表响应
+type WatchLaterResponse struct {
+	Items []WatchLater `json:"items"`
+	Total int64        `json:"total"`
+	Page  int          `json:"page"`
+	Size  int          `json:"size"`
+}
+
+// MoveWatchLaterRequest 拖动排序请求，将视频移动到列表中的指定位置
+type MoveWatchLaterRequest struct {
+	Index int `json:"index" binding:"min=0"` // 目标位置，从0开始，超出列表长度时移到末尾
+}
+
+// RecordWatchRequest 记录观看历史请求
+type RecordWatchRequest struct {
+	Progress *float64 `json:"progress" binding:"omitempty,min=0"` // 观看进度(秒)，不传表示已看完
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 屏蔽相关错误
+var (
+	ErrBlockNotFound = errors.New("尚未屏蔽该用户")
+	ErrInvalidBlock  = errors.New("无效的屏蔽操作")
+)
+
+// BlockService 拉黑/静音服务接口
+type BlockService interface {
+	Block(ctx context.Context, userID, targetID, mode string) error
+	Unblock(ctx context.Context, userID, targetID, mode string) error
+	List(ctx context.Context, userID, mode string, page, size int) (*model.BlockListResponse, error)
+}
+
+type blockService struct {
+	collection string
+}
+
+// NewBlockService 创建拉黑/静音服务实例
+func NewBlockService() BlockService {
+	return &blockService{
+		collection: "blocks",
+	}
+}
+
+// Block 拉黑或静音用户，已屏蔽时更新屏蔽方式。拉黑后解除双方的关注关系
+func (s *blockService) Block(ctx context.Context, userID, targetID, mode string) error {
+	if mode != model.BlockModeBlock && mode != model.BlockModeMute {
+		return fmt.Errorf("%w: 无效的屏蔽方式", ErrInvalidBlock)
+	}
+	if userID == targetID {
+		return fmt.Errorf("%w: 不能屏蔽自己", ErrInvalidBlock)
+	}
+
+	// 检查被屏蔽用户是否存在
+	objectID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return fmt.Errorf("%w: 无效的用户ID", ErrInvalidBlock)
+	}
+	count, err := database.GetCollection("users").CountDocuments(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("用户不存在")
+	}
+
+	_, err = database.GetCollection(s.collection).UpdateOne(ctx,
+		bson.M{"user_id": userID, "target_id": targetID},
+		bson.M{
+			"$set":         bson.M{"mode": mode},
+			"$setOnInsert": bson.M{"created_at": time.Now()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+	invalidateBlockRelations(ctx, userID)
+
+	if mode != model.BlockModeBlock {
+		return nil
+	}
+	if err := unfollowIfFollowing(ctx, userID, targetID); err != nil {
+		return err
+	}
+	return unfollowIfFollowing(ctx, targetID, userID)
+}
+
+// Unblock 取消拉黑或静音，mode 需与当前屏蔽方式一致
+func (s *blockService) Unblock(ctx context.Context, userID, targetID, mode string) error {
+	result, err := database.GetCollection(s.collection).DeleteOne(ctx, bson.M{
+		"user_id":   userID,
+		"target_id": targetID,
+		"mode":      mode,
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrBlockNotFound
+	}
+
+	invalidateBlockRelations(ctx, userID)
+	return nil
+}
+
+// List 获取屏蔽列表，mode 为空时返回全部
+func (s *blockService) List(ctx context.Context, userID, mode string, page, size int) (*model.BlockListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	collection := database.GetCollection(s.collection)
+	filter := bson.M{"user_id": userID}
+	if mode != "" {
+		filter["mode"] = mode
+	}
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []model.Block
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+
+	targetIDs := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		targetIDs = append(targetIDs, b.TargetID)
+	}
+	briefs, err := loadUserBriefs(ctx, targetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]model.BlockUser, 0, len(blocks))
+	for _, b := range blocks {
+		users = append(users, model.BlockUser{
+			UserBrief: briefs[b.TargetID],
+			Mode:      b.Mode,
+			BlockedAt: b.CreatedAt,
+		})
+	}
+
+	return &model.BlockListResponse{
+		Users: users,
+		Total: total,
+		Page:  page,
+		Size:  size,
+	}, nil
+}
+
+// unfollowIfFollowing 存在关注关系时取消关注，同时更新双方的关注计数
+func unfollowIfFollowing(ctx context.Context, followerID, followeeID string) error {
+	count, err := database.GetCollection("follows").CountDocuments(ctx, bson.M{
+		"follower_id": followerID,
+		"followee_id": followeeID,
+	})
+	if err != nil || count == 0 {
+		return err
+	}
+	return NewFollowService().Unfollow(ctx, followerID, followeeID)
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 章节相关错误
+var (
+	ErrInvalidChapter       = errors.New("无效的章节")
+	ErrChapterForbidden     = errors.New("只有视频作者可以设置章节")
+	ErrChapterVideoNotFound = errors.New("视频不存在")
+	ErrInvalidChapterFormat = errors.New("不支持的章节格式")
+)
+
+const (
+	// chapterMaxCount 每个视频最多的章节数
+	chapterMaxCount = 100
+	// chapterTitleLimit 章节标题的最大字数
+	chapterTitleLimit = 100
+	// chapterMinLength 章节的最短时长（秒）
+	chapterMinLength = 1.0
+	// chapterOpenDuration 视频时长未知时最后一章在WebVTT中的时长（秒），播放器会在视频结束处截断
+	chapterOpenDuration = 3600.0
+)
+
+// ChapterService 视频章节服务接口
+type ChapterService interface {
+	SetChapters(ctx context.Context, userID, videoID string, inputs []model.ChapterInput) ([]model.Chapter, error)
+	GetChapters(ctx context.Context, viewerID, videoID string) ([]model.Chapter, error)
+	ExportChapters(ctx context.Context, viewerID, videoID, format string) (*model.ExportFile, error)
+}
+
+type chapterService struct {
+	collection string
+}
+
+// NewChapterService 创建章节服务实例
+func NewChapterService() ChapterService {
+	return &chapterService{
+		collection: "videos",
+	}
+}
+
+// SetChapters 整体替换视频的章节。章节可以由作者自己的标记提升而来，
+// 保存前按开始时间排序，并检查时间范围、重叠和最短时长，结束时间为空的章节到下一章节开始或视频结束
+func (s *chapterService) SetChapters(ctx context.Context, userID, videoID string, inputs []model.ChapterInput) ([]model.Chapter, error) {
+	video, err := s.findVideo(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.UserID != userID {
+		return nil, ErrChapterForbidden
+	}
+	if len(inputs) > chapterMaxCount {
+		return nil, fmt.Errorf("%w: 最多%d个章节", ErrInvalidChapter, chapterMaxCount)
+	}
+
+	marks, err := loadChapterMarks(ctx, userID, videoID, inputs)
+	if err != nil {
+		return nil, err
+	}
+	chapters := make([]model.Chapter, 0, len(inputs))
+	for i, input := range inputs {
+		chapter, err := buildChapter(input, marks)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第%d个章节%s", ErrInvalidChapter, i+1, err.Error())
+		}
+		chapters = append(chapters, chapter)
+	}
+	if err := normalizeChapters(chapters, video.Duration); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+	if len(chapters) > 0 {
+		update["chapters"] = chapters
+	}
+	op := bson.M{"$set": update}
+	if len(chapters) == 0 {
+		op["$unset"] = bson.M{"chapters": ""}
+	}
+	if _, err := database.GetCollection(s.collection).UpdateOne(ctx, bson.M{"_id": video.ID}, op); err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
+
+// GetChapters 获取视频的章节，非公开视频只有作者可以查看
+func (s *chapterService) GetChapters(ctx context.Context, viewerID, videoID string) ([]model.Chapter, error) {
+	video, err := s.findVideo(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != viewerID {
+		return nil, ErrChapterVideoNotFound
+	}
+	if video.Chapters == nil {
+		return []model.Chapter{}, nil
+	}
+	return video.Chapters, nil
+}
+
+// ExportChapters 导出视频的章节文件
+func (s *chapterService) ExportChapters(ctx context.Context, viewerID, videoID, format string) (*model.ExportFile, error) {
+	contentType, ok := model.ChapterContentTypes[format]
+	if !ok {
+		return nil, ErrInvalidChapterFormat
+	}
+	video, err := s.findVideo(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != viewerID {
+		return nil, ErrChapterVideoNotFound
+	}
+
+	file := &model.ExportFile{ContentType: contentType}
+	if format == model.ChapterFormatVTT {
+		file.Filename = exportFilename(video.Title) + ".chapters.vtt"
+		file.Data = renderVTTChapters(video.Chapters)
+	} else {
+		file.Filename = exportFilename(video.Title) + ".chapters.txt"
+		file.Data = renderMP4Chapters(video.Chapters)
+	}
+	return file, nil
+}
+
+func (s *chapterService) findVideo(ctx context.Context, videoID string) (*model.Video, error) {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, ErrChapterVideoNotFound
+	}
+	var video model.Video
+	if err := database.GetCollection(s.collection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		return nil, ErrChapterVideoNotFound
+	}
+	return &video, nil
+}
+
+// loadChapterMarks 加载章节引用的标记，只能使用作者自己在该视频上的标记
+func loadChapterMarks(ctx context.Context, userID, videoID string, inputs []model.ChapterInput) (map[string]model.Mark, error) {
+	var ids []primitive.ObjectID
+	for _, input := range inputs {
+		if input.MarkID == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(input.MarkID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 无效的标记ID", ErrInvalidChapter)
+		}
+		ids = append(ids, id)
+	}
+	marks := make(map[string]model.Mark, len(ids))
+	if len(ids) == 0 {
+		return marks, nil
+	}
+
+	cursor, err := database.GetCollection("marks").Find(ctx, bson.M{
+		"_id":      bson.M{"$in": ids},
+		"user_id":  userID,
+		"video_id": videoID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var found []model.Mark
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, m := range found {
+		marks[m.ID.Hex()] = m
+	}
+	return marks, nil
+}
+
+// buildChapter 根据输入生成章节，引用标记时使用标记的时间范围，标题为空时使用标记内容的第一行或分类名称
+func buildChapter(input model.ChapterInput, marks map[string]model.Mark) (model.Chapter, error) {
+	chapter := model.Chapter{
+		Title: strings.TrimSpace(input.Title),
+		Start: input.Start,
+		End:   input.End,
+	}
+	if input.MarkID != "" {
+		mark, ok := marks[input.MarkID]
+		if !ok {
+			return chapter, errors.New("引用的标记不存在")
+		}
+		chapter.MarkID = input.MarkID
+		chapter.Start = mark.Timestamp
+		chapter.End = 0
+		if mark.IsRange() {
+			chapter.End = mark.EndTime
+		}
+		if chapter.Title == "" {
+			chapter.Title = strings.TrimSpace(strings.SplitN(strings.TrimSpace(mark.Content), "\n", 2)[0])
+		}
+		if chapter.Title == "" {
+			if category, ok := model.FindMarkCategory(mark.Category); ok {
+				chapter.Title = category.Label
+			}
+		}
+	}
+
+	// 标题会写入WebVTT和章节文件，不能换行
+	chapter.Title = strings.Join(strings.Fields(chapter.Title), " ")
+	if chapter.Title == "" {
+		return chapter, errors.New("标题不能为空")
+	}
+	if runes := []rune(chapter.Title); len(runes) > chapterTitleLimit {
+		chapter.Title = string(runes[:chapterTitleLimit])
+	}
+	return chapter, nil
+}
+
+// normalizeChapters 按开始时间排序并检查章节：开始时间不能为负数或超出视频时长，
+// 相邻章节不能重叠，每章至少 chapterMinLength 秒。校验通过后补齐每章的结束时间
+func normalizeChapters(chapters []model.Chapter, duration float64) error {
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].Start < chapters[j].Start
+	})
+
+	for i := range chapters {
+		c := &chapters[i]
+		if c.Start < 0 || c.End < 0 {
+			return fmt.Errorf("%w: 「%s」的时间不能为负数", ErrInvalidChapter, c.Title)
+		}
+		if duration > 0 && c.Start >= duration {
+			return fmt.Errorf("%w: 「%s」的开始时间超出视频时长", ErrInvalidChapter, c.Title)
+		}
+
+		end := duration
+		if i+1 < len(chapters) {
+			next := chapters[i+1]
+			if next.Start == c.Start {
+				return fmt.Errorf("%w: 「%s」和「%s」的开始时间相同", ErrInvalidChapter, c.Title, next.Title)
+			}
+			if c.End > next.Start {
+				return fmt.Errorf("%w: 「%s」和「%s」的时间重叠", ErrInvalidChapter, c.Title, next.Title)
+			}
+			end = next.Start
+		}
+		if c.End > 0 {
+			if c.End <= c.Start {
+				return fmt.Errorf("%w: 「%s」的结束时间需要大于开始时间", ErrInvalidChapter, c.Title)
+			}
+			if duration > 0 && c.End > duration {
+				return fmt.Errorf("%w: 「%s」的结束时间超出视频时长", ErrInvalidChapter, c.Title)
+			}
+			end = c.End
+		}
+		if end > 0 && end-c.Start < chapterMinLength {
+			return fmt.Errorf("%w: 「%s」短于%g秒", ErrInvalidChapter, c.Title, chapterMinLength)
+		}
+		c.End = end
+	}
+	return nil
+}
+
+// renderVTTChapters 渲染 WebVTT 章节轨道
+func renderVTTChapters(chapters []model.Chapter) []byte {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for i, c := range chapters {
+		end := c.End
+		if end <= c.Start {
+			end = c.Start + chapterOpenDuration
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, formatCueTime(c.Start, "."), formatCueTime(end, "."), cueText(c.Title))
+	}
+	return []byte(b.String())
+}
+
+// renderMP4Chapters 渲染 MP4Box、mp4chaps 等工具使用的章节列表（OGM格式）：
+// 每章两行 CHAPTERxx=开始时间 和 CHAPTERxxNAME=标题。MP4章节只有开始时间，
+// 两章之间有空档时插入一个无标题章节，使后一章在正确的时间开始
+func renderMP4Chapters(chapters []model.Chapter) []byte {
+	var b strings.Builder
+	n := 0
+	write := func(start float64, title string) {
+		n++
+		fmt.Fprintf(&b, "CHAPTER%02d=%s\nCHAPTER%02dNAME=%s\n", n, formatCueTime(start, "."), n, title)
+	}
+	for i, c := range chapters {
+		if i == 0 && c.Start > 0 {
+			write(0, "")
+		}
+		write(c.Start, c.Title)
+		if c.End > 0 && i+1 < len(chapters) && chapters[i+1].Start > c.End {
+			write(c.End, "")
+		}
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 测试章节排序、结束时间补齐和校验
+func TestNormalizeChapters(t *testing.T) {
+	chapters := []model.Chapter{
+		{Title: "练习", Start: 300},
+		{Title: "开场", Start: 0},
+		{Title: "讲解", Start: 60, End: 240},
+	}
+	assert.NoError(t, normalizeChapters(chapters, 600))
+	assert.Equal(t, []model.Chapter{
+		{Title: "开场", Start: 0, End: 60},
+		{Title: "讲解", Start: 60, End: 240},
+		{Title: "练习", Start: 300, End: 600},
+	}, chapters)
+
+	// 视频时长未知时最后一章没有结束时间
+	chapters = []model.Chapter{{Title: "开场", Start: 0}, {Title: "讲解", Start: 60}}
+	assert.NoError(t, normalizeChapters(chapters, 0))
+	assert.Equal(t, 60.0, chapters[0].End)
+	assert.Equal(t, 0.0, chapters[1].End)
+
+	invalid := map[string][]model.Chapter{
+		"重叠":     {{Title: "a", Start: 0, End: 90}, {Title: "b", Start: 60}},
+		"开始时间相同": {{Title: "a", Start: 60}, {Title: "b", Start: 60}},
+		"超出时长":   {{Title: "a", Start: 600}},
+		"结束超出时长": {{Title: "a", Start: 0, End: 601}},
+		"负数":     {{Title: "a", Start: -1}},
+		"过短":     {{Title: "a", Start: 0}, {Title: "b", Start: 0.5}},
+		"结束早于开始": {{Title: "a", Start: 30, End: 20}},
+	}
+	for name, chapters := range invalid {
+		assert.ErrorIs(t, normalizeChapters(chapters, 600), ErrInvalidChapter, name)
+	}
+	assert.NoError(t, normalizeChapters(nil, 600))
+}
+
+// 测试从标记生成章节
+func TestBuildChapter(t *testing.T) {
+	pointID, rangeID, emptyID := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	marks := map[string]model.Mark{
+		pointID.Hex(): {ID: pointID, Timestamp: 30, Content: "  第一节：变量\n详细说明"},
+		rangeID.Hex(): {ID: rangeID, Timestamp: 60, EndTime: 120, Content: "循环"},
+		emptyID.Hex(): {ID: emptyID, Timestamp: 200, Category: model.MarkCategoryKeyPoint},
+	}
+
+	chapter, err := buildChapter(model.ChapterInput{MarkID: pointID.Hex(), Start: 999}, marks)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Chapter{Title: "第一节：变量", Start: 30, MarkID: pointID.Hex()}, chapter)
+
+	chapter, err = buildChapter(model.ChapterInput{MarkID: rangeID.Hex(), Title: "自定义\n标题"}, marks)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Chapter{Title: "自定义 标题", Start: 60, End: 120, MarkID: rangeID.Hex()}, chapter)
+
+	chapter, err = buildChapter(model.ChapterInput{MarkID: emptyID.Hex()}, marks)
+	assert.NoError(t, err)
+	assert.Equal(t, "重点", chapter.Title)
+
+	_, err = buildChapter(model.ChapterInput{MarkID: primitive.NewObjectID().Hex(), Title: "x"}, marks)
+	assert.Error(t, err)
+	_, err = buildChapter(model.ChapterInput{Title: "  ", Start: 10}, marks)
+	assert.Error(t, err)
+}
+
+// 测试渲染 WebVTT 章节轨道
+func TestRenderVTTChapters(t *testing.T) {
+	chapters := []model.Chapter{
+		{Title: "开场", Start: 0, End: 65.5},
+		{Title: "讲解 --> 练习", Start: 65.5},
+	}
+	assert.Equal(t, "WEBVTT\n\n"+
+		"1\n00:00:00.000 --> 00:01:05.500\n开场\n\n"+
+		"2\n00:01:05.500 --> 01:01:05.500\n讲解 -> 练习\n\n",
+		string(renderVTTChapters(chapters)))
+}
+
+// 测试渲染MP4章节列表，空档处插入无标题章节
+func TestRenderMP4Chapters(t *testing.T) {
+	chapters := []model.Chapter{
+		{Title: "讲解", Start: 60, End: 240},
+		{Title: "练习", Start: 300, End: 600},
+	}
+	assert.Equal(t, ""+
+		"CHAPTER01=00:00:00.000\nCHAPTER01NAME=\n"+
+		"CHAPTER02=00:01:00.000\nCHAPTER02NAME=讲解\n"+
+		"CHAPTER03=00:04:00.000\nCHAPTER03NAME=\n"+
+		"CHAPTER04=00:05:00.000\nCHAPTER04NAME=练习\n",
+		string(renderMP4Chapters(chapters)))
+	assert.Empty(t, renderMP4Chapters(nil))
+}
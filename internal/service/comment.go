@@ -0,0 +1,682 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 评论相关错误
+var (
+	ErrCommentNotFound  = errors.New("评论不存在")
+	ErrCommentForbidden = errors.New("无权操作该评论")
+	// ErrCommentVideoNotFound 视频不存在，或非公开视频被作者以外的用户访问
+	ErrCommentVideoNotFound = errors.New("视频不存在")
+)
+
+// 每条一级评论附带的回复预览数量
+const commentReplyPreviewSize = 3
+
+// CommentService 评论服务接口
+type CommentService interface {
+	Create(ctx context.Context, userID, videoID string, req *model.CreateCommentRequest) (*model.CommentItem, error)
+	List(ctx context.Context, videoID, viewerID string, query model.CommentQuery) (*model.CommentListResponse, error)
+	ListReplies(ctx context.Context, commentID, viewerID string, query model.CommentQuery) (*model.CommentListResponse, error)
+	Update(ctx context.Context, userID, commentID, content string) (*model.Comment, error)
+	Delete(ctx context.Context, userID, commentID string) error
+	Like(ctx context.Context, userID, commentID string) error
+	Unlike(ctx context.Context, userID, commentID string) error
+}
+
+type commentService struct {
+	collection string
+}
+
+// NewCommentService 创建评论服务实例
+func NewCommentService() CommentService {
+	return &commentService{
+		collection: "comments",
+	}
+}
+
+// Create 发表评论或回复
+func (s *commentService) Create(ctx context.Context, userID, videoID string, req *model.CreateCommentRequest) (*model.CommentItem, error) {
+	// 检查视频是否存在且可评论
+	videoObjectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, errors.New("无效的视频ID")
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": videoObjectID}).Decode(&video); err != nil {
+		return nil, errors.New("视频不存在")
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != userID {
+		return nil, errors.New("无权评论该视频")
+	}
+	if err := checkInteraction(ctx, userID, video.UserID); err != nil {
+		return nil, err
+	}
+
+	collection := database.GetCollection(s.collection)
+	now := time.Now()
+	comment := model.Comment{
+		ID:        primitive.NewObjectID(),
+		VideoID:   videoID,
+		UserID:    userID,
+		Content:   strings.TrimSpace(req.Content),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if comment.Content == "" {
+		return nil, errors.New("评论内容不能为空")
+	}
+
+	// 回复评论：统一挂到一级评论下
+	if req.ParentID != "" {
+		parent, err := s.findComment(ctx, req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.VideoID != videoID {
+			return nil, errors.New("回复的评论不属于该视频")
+		}
+		if err := checkInteraction(ctx, userID, parent.UserID); err != nil {
+			return nil, err
+		}
+		comment.ParentID = parent.ID
+		comment.ReplyToUserID = parent.UserID
+		if parent.RootID.IsZero() {
+			comment.RootID = parent.ID
+		} else {
+			comment.RootID = parent.RootID
+		}
+	}
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中执行插入评论和更新计数
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// 1. 插入评论
+		if _, err := collection.InsertOne(sessCtx, comment); err != nil {
+			return nil, err
+		}
+
+		// 2. 回复时更新一级评论的回复数
+		if !comment.RootID.IsZero() {
+			if _, err := collection.UpdateOne(
+				sessCtx,
+				bson.M{"_id": comment.RootID},
+				bson.M{"$inc": bson.M{"reply_count": 1}},
+			); err != nil {
+				return nil, err
+			}
+		}
+
+		// 3. 更新视频评论数
+		return nil, adjustVideoCommentCount(sessCtx, videoObjectID, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyCreated(&video, &comment)
+	recordTrendingAsync(video, model.RankingEventComment)
+
+	briefs, err := loadUserBriefs(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CommentItem{
+		Comment: comment,
+		User:    briefs[userID],
+	}, nil
+}
+
+// notifyCreated 发表评论后通知视频作者、被回复者和被@的用户，同一用户只通知一次
+func (s *commentService) notifyCreated(video *model.Video, comment *model.Comment) {
+	notified := map[string]bool{comment.UserID: true}
+
+	if comment.ReplyToUserID != "" {
+		notifyAsync(model.Notification{
+			UserID:     comment.ReplyToUserID,
+			Type:       model.NotificationTypeComment,
+			TargetType: model.NotificationTargetComment,
+			TargetID:   comment.ID.Hex(),
+			ActorIDs:   []string{comment.UserID},
+			Content:    comment.Content,
+		})
+		notified[comment.ReplyToUserID] = true
+	}
+
+	if !notified[video.UserID] {
+		notifyAsync(model.Notification{
+			UserID:     video.UserID,
+			Type:       model.NotificationTypeComment,
+			TargetType: model.NotificationTargetVideo,
+			TargetID:   comment.VideoID,
+			ActorIDs:   []string{comment.UserID},
+			Content:    comment.Content,
+		})
+		notified[video.UserID] = true
+	}
+
+	notifyMentions(comment.UserID, comment.Content, model.NotificationTargetComment, comment.ID.Hex(), notified)
+}
+
+// List 获取视频的一级评论列表
+func (s *commentService) List(ctx context.Context, videoID, viewerID string, query model.CommentQuery) (*model.CommentListResponse, error) {
+	if err := checkCommentVideoVisible(ctx, videoID, viewerID); err != nil {
+		return nil, err
+	}
+
+	size := normalizeCommentPageSize(query.Size)
+	hidden, err := hiddenUserIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	filter := hideAuthors(bson.M{
+		"video_id": videoID,
+		"root_id":  bson.M{"$exists": false},
+	}, hidden)
+
+	// 设置排序和游标
+	var sort bson.D
+	if query.Sort == model.CommentSortHot {
+		sort = bson.D{{Key: "likes", Value: -1}, {Key: "_id", Value: -1}}
+	} else {
+		sort = bson.D{{Key: "_id", Value: -1}}
+	}
+	if query.Cursor != "" {
+		likes, lastID, err := decodeCommentCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if query.Sort == model.CommentSortHot {
+			filter["$or"] = []bson.M{
+				{"likes": bson.M{"$lt": likes}},
+				{"likes": likes, "_id": bson.M{"$lt": lastID}},
+			}
+		} else {
+			filter["_id"] = bson.M{"$lt": lastID}
+		}
+	}
+
+	comments, hasMore, err := s.findPage(ctx, filter, sort, size)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.buildItems(ctx, comments, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 附带回复预览
+	for i := range items {
+		if items[i].ReplyCount == 0 {
+			continue
+		}
+		replies, _, err := s.findPage(ctx,
+			hideAuthors(bson.M{"root_id": items[i].ID}, hidden),
+			bson.D{{Key: "_id", Value: 1}},
+			commentReplyPreviewSize,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if items[i].Replies, err = s.buildItems(ctx, replies, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &model.CommentListResponse{
+		Comments: items,
+		HasMore:  hasMore,
+	}
+	if hasMore {
+		last := comments[len(comments)-1]
+		result.NextCursor = encodeCommentCursor(last.Likes, last.ID, query.Sort == model.CommentSortHot)
+	}
+	return result, nil
+}
+
+// ListReplies 获取一级评论下的回复列表，按时间正序
+func (s *commentService) ListReplies(ctx context.Context, commentID, viewerID string, query model.CommentQuery) (*model.CommentListResponse, error) {
+	root, err := s.findComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCommentVideoVisible(ctx, root.VideoID, viewerID); err != nil {
+		return nil, err
+	}
+	rootID := root.ID
+
+	size := normalizeCommentPageSize(query.Size)
+	hidden, err := hiddenUserIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	filter := hideAuthors(bson.M{"root_id": rootID}, hidden)
+	if query.Cursor != "" {
+		_, lastID, err := decodeCommentCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter["_id"] = bson.M{"$gt": lastID}
+	}
+
+	replies, hasMore, err := s.findPage(ctx, filter, bson.D{{Key: "_id", Value: 1}}, size)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.buildItems(ctx, replies, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.CommentListResponse{
+		Comments: items,
+		HasMore:  hasMore,
+	}
+	if hasMore {
+		result.NextCursor = encodeCommentCursor(0, replies[len(replies)-1].ID, false)
+	}
+	return result, nil
+}
+
+// Update 编辑评论，仅评论作者可操作
+func (s *commentService) Update(ctx context.Context, userID, commentID, content string) (*model.Comment, error) {
+	comment, err := s.findComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrCommentForbidden
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("评论内容不能为空")
+	}
+
+	comment.Content = content
+	comment.UpdatedAt = time.Now()
+	_, err = database.GetCollection(s.collection).UpdateOne(
+		ctx,
+		bson.M{"_id": comment.ID},
+		bson.M{"$set": bson.M{
+			"content":    comment.Content,
+			"updated_at": comment.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// Delete 删除评论，评论作者和视频作者均可操作；删除一级评论会同时删除其下所有回复
+func (s *commentService) Delete(ctx context.Context, userID, commentID string) error {
+	comment, err := s.findComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+
+	videoObjectID, err := primitive.ObjectIDFromHex(comment.VideoID)
+	if err != nil {
+		return errors.New("无效的视频ID")
+	}
+
+	if comment.UserID != userID {
+		var video model.Video
+		err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": videoObjectID}).Decode(&video)
+		if err != nil || video.UserID != userID {
+			return ErrCommentForbidden
+		}
+	}
+
+	collection := database.GetCollection(s.collection)
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中执行删除评论、点赞记录并更新计数
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		ids := []primitive.ObjectID{comment.ID}
+
+		if comment.RootID.IsZero() {
+			// 1. 一级评论：收集所有回复
+			cursor, err := collection.Find(sessCtx,
+				bson.M{"root_id": comment.ID},
+				options.Find().SetProjection(bson.M{"_id": 1}),
+			)
+			if err != nil {
+				return nil, err
+			}
+			var replies []model.Comment
+			if err = cursor.All(sessCtx, &replies); err != nil {
+				return nil, err
+			}
+			for _, r := range replies {
+				ids = append(ids, r.ID)
+			}
+		} else {
+			// 1. 回复：更新一级评论的回复数
+			if _, err := collection.UpdateOne(
+				sessCtx,
+				bson.M{"_id": comment.RootID, "reply_count": bson.M{"$gt": 0}},
+				bson.M{"$inc": bson.M{"reply_count": -1}},
+			); err != nil {
+				return nil, err
+			}
+		}
+
+		// 2. 删除评论及其点赞记录
+		result, err := collection.DeleteMany(sessCtx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := database.GetCollection("comment_likes").DeleteMany(
+			sessCtx,
+			bson.M{"comment_id": bson.M{"$in": ids}},
+		); err != nil {
+			return nil, err
+		}
+
+		// 3. 更新视频评论数
+		return nil, adjustVideoCommentCount(sessCtx, videoObjectID, -result.DeletedCount)
+	})
+
+	return err
+}
+
+// Like 点赞评论
+func (s *commentService) Like(ctx context.Context, userID, commentID string) error {
+	comment, err := s.findComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if err := checkCommentVideoVisible(ctx, comment.VideoID, userID); err != nil {
+		return err
+	}
+
+	likeCollection := database.GetCollection("comment_likes")
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中执行添加点赞记录和更新点赞数
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// (comment_id, user_id) 唯一索引保证并发点赞只计一次
+		like := model.CommentLike{
+			ID:        primitive.NewObjectID(),
+			CommentID: comment.ID,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+		}
+		if _, err := likeCollection.InsertOne(sessCtx, like); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, errors.New("已经点赞过该评论")
+			}
+			return nil, err
+		}
+
+		_, err := database.GetCollection(s.collection).UpdateOne(
+			sessCtx,
+			bson.M{"_id": comment.ID},
+			bson.M{"$inc": bson.M{"likes": 1}},
+		)
+		return nil, err
+	})
+
+	return err
+}
+
+// Unlike 取消点赞评论
+func (s *commentService) Unlike(ctx context.Context, userID, commentID string) error {
+	comment, err := s.findComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if err := checkCommentVideoVisible(ctx, comment.VideoID, userID); err != nil {
+		return err
+	}
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中执行删除点赞记录和更新点赞数
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		result, err := database.GetCollection("comment_likes").DeleteOne(sessCtx, bson.M{
+			"comment_id": comment.ID,
+			"user_id":    userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result.DeletedCount == 0 {
+			return nil, errors.New("尚未点赞该评论")
+		}
+
+		// 条件更新，确保likes不会小于0
+		_, err = database.GetCollection(s.collection).UpdateOne(
+			sessCtx,
+			bson.M{"_id": comment.ID, "likes": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"likes": -1}},
+		)
+		return nil, err
+	})
+
+	return err
+}
+
+// findComment 根据ID获取评论
+func (s *commentService) findComment(ctx context.Context, commentID string) (*model.Comment, error) {
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, ErrCommentNotFound
+	}
+
+	var comment model.Comment
+	err = database.GetCollection(s.collection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// checkCommentVideoVisible 检查视频是否存在，非公开视频的评论只有作者可以查看和点赞
+func checkCommentVideoVisible(ctx context.Context, videoID, viewerID string) error {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return ErrCommentVideoNotFound
+	}
+	var video model.Video
+	err = database.GetCollection("videos").FindOne(ctx, bson.M{"_id": objectID},
+		options.FindOne().SetProjection(bson.M{"user_id": 1, "status": 1}),
+	).Decode(&video)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrCommentVideoNotFound
+		}
+		return err
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != viewerID {
+		return ErrCommentVideoNotFound
+	}
+	return nil
+}
+
+// findPage 多查询一条用于判断是否还有下一页
+func (s *commentService) findPage(ctx context.Context, filter bson.M, sort bson.D, size int) ([]model.Comment, bool, error) {
+	cursor, err := database.GetCollection(s.collection).Find(ctx, filter,
+		options.Find().SetSort(sort).SetLimit(int64(size+1)),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := make([]model.Comment, 0, size+1)
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(comments) > size
+	if hasMore {
+		comments = comments[:size]
+	}
+	return comments, hasMore, nil
+}
+
+// buildItems 补充评论者信息和当前用户的点赞状态
+func (s *commentService) buildItems(ctx context.Context, comments []model.Comment, viewerID string) ([]model.CommentItem, error) {
+	items := make([]model.CommentItem, 0, len(comments))
+	if len(comments) == 0 {
+		return items, nil
+	}
+
+	userIDs := make([]string, 0, len(comments))
+	commentIDs := make([]primitive.ObjectID, 0, len(comments))
+	for _, c := range comments {
+		userIDs = append(userIDs, c.UserID)
+		commentIDs = append(commentIDs, c.ID)
+	}
+
+	briefs, err := loadUserBriefs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询当前用户的点赞状态
+	liked := make(map[primitive.ObjectID]bool)
+	if viewerID != "" {
+		cursor, err := database.GetCollection("comment_likes").Find(ctx, bson.M{
+			"user_id":    viewerID,
+			"comment_id": bson.M{"$in": commentIDs},
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		var likes []model.CommentLike
+		if err = cursor.All(ctx, &likes); err != nil {
+			return nil, err
+		}
+		for _, l := range likes {
+			liked[l.CommentID] = true
+		}
+	}
+
+	for _, c := range comments {
+		items = append(items, model.CommentItem{
+			Comment: c,
+			User:    briefs[c.UserID],
+			IsLiked: liked[c.ID],
+		})
+	}
+	return items, nil
+}
+
+// hideAuthors 从查询条件中排除因举报被隐藏的评论，以及当前用户拉黑或静音的评论者
+func hideAuthors(filter bson.M, hidden []string) bson.M {
+	filter["hidden"] = bson.M{"$ne": true}
+	if len(hidden) > 0 {
+		filter["user_id"] = bson.M{"$nin": hidden}
+	}
+	return filter
+}
+
+// adjustVideoCommentCount 调整视频评论数，确保不会小于0
+func adjustVideoCommentCount(ctx context.Context, videoID primitive.ObjectID, delta int64) error {
+	_, err := database.GetCollection("videos").UpdateOne(
+		ctx,
+		bson.M{"_id": videoID},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"stats.comments": bson.M{"$max": bson.A{
+					0,
+					bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$stats.comments", 0}}, delta}},
+				}},
+			}}},
+		},
+	)
+	return err
+}
+
+// normalizeCommentPageSize 规范化每页数量
+func normalizeCommentPageSize(size int) int {
+	if size < 1 || size > 50 {
+		return 20
+	}
+	return size
+}
+
+// encodeCommentCursor 生成评论游标；按热度排序时需要同时记录点赞数
+func encodeCommentCursor(likes int64, lastID primitive.ObjectID, byHot bool) string {
+	raw := lastID.Hex()
+	if byHot {
+		raw = fmt.Sprintf("%d:%s", likes, raw)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCommentCursor 解析评论游标
+func decodeCommentCursor(cursor string) (int64, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, primitive.NilObjectID, errors.New("无效的游标")
+	}
+
+	var likes int64
+	idHex := string(raw)
+	if i := strings.Index(idHex, ":"); i >= 0 {
+		likes, err = strconv.ParseInt(idHex[:i], 10, 64)
+		if err != nil {
+			return 0, primitive.NilObjectID, errors.New("无效的游标")
+		}
+		idHex = idHex[i+1:]
+	}
+
+	lastID, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		return 0, primitive.NilObjectID, errors.New("无效的游标")
+	}
+	return likes, lastID, nil
+}
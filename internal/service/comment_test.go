@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 测试按时间排序的评论游标
+func TestCommentCursorByTime(t *testing.T) {
+	lastID := primitive.NewObjectID()
+
+	cursor := encodeCommentCursor(42, lastID, false)
+	likes, decodedID, err := decodeCommentCursor(cursor)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), likes) // 按时间排序时不记录点赞数
+	assert.Equal(t, lastID, decodedID)
+}
+
+// 测试按热度排序的评论游标
+func TestCommentCursorByHot(t *testing.T) {
+	lastID := primitive.NewObjectID()
+
+	cursor := encodeCommentCursor(42, lastID, true)
+	likes, decodedID, err := decodeCommentCursor(cursor)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), likes)
+	assert.Equal(t, lastID, decodedID)
+}
+
+// 测试无效的评论游标
+func TestCommentCursorInvalid(t *testing.T) {
+	_, _, err := decodeCommentCursor("not-a-cursor!")
+	assert.NotNil(t, err)
+
+	_, _, err = decodeCommentCursor("YWJjOmRlZg") // "abc:def"
+	assert.NotNil(t, err)
+}
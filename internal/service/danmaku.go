@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/redis"
+	"video-platform/pkg/ws"
+	"video-platform/script"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 弹幕相关错误
+var (
+	ErrDanmakuRateLimited = errors.New("弹幕发送过于频繁，请稍后再试")
+	// ErrDanmakuVideoNotFound 视频不存在，或非公开视频被作者以外的用户访问
+	ErrDanmakuVideoNotFound = errors.New("视频不存在")
+)
+
+const (
+	danmakuDefaultWindow = 60.0 // 默认加载的时间窗口（秒）
+	danmakuDefaultLimit  = 1000 // 默认最大返回条数
+	danmakuMaxLimit      = 3000 // 最大返回条数上限
+	danmakuDefaultColor  = "#FFFFFF"
+)
+
+// DanmakuService 弹幕服务接口
+type DanmakuService interface {
+	Send(ctx context.Context, userID, videoID string, req *model.SendDanmakuRequest) (*model.Danmaku, error)
+	List(ctx context.Context, videoID, viewerID string, query model.DanmakuQuery) ([]model.Danmaku, error)
+	CheckVisible(ctx context.Context, videoID, viewerID string) error
+	Subscribe(videoID string, client *ws.Client)
+	Unsubscribe(videoID string, client *ws.Client)
+}
+
+type danmakuService struct {
+	collection string
+	hub        *ws.Hub
+}
+
+// NewDanmakuService 创建弹幕服务实例
+func NewDanmakuService(hub *ws.Hub) DanmakuService {
+	if hub == nil {
+		hub = ws.NewHub()
+	}
+	return &danmakuService{
+		collection: "danmaku",
+		hub:        hub,
+	}
+}
+
+// Send 发送弹幕，并实时推送给正在观看该视频的用户
+func (s *danmakuService) Send(ctx context.Context, userID, videoID string, req *model.SendDanmakuRequest) (*model.Danmaku, error) {
+	// 检查视频是否存在且可发送弹幕
+	video, err := s.findVisibleVideo(ctx, videoID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkInteraction(ctx, userID, video.UserID); err != nil {
+		return nil, err
+	}
+	if video.Duration > 0 && req.Time > video.Duration {
+		return nil, errors.New("弹幕时间超出视频时长")
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, errors.New("弹幕内容不能为空")
+	}
+
+	// 限流检查
+	if err := s.checkRateLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	danmaku := model.Danmaku{
+		ID:        primitive.NewObjectID(),
+		VideoID:   videoID,
+		UserID:    userID,
+		Content:   content,
+		Time:      req.Time,
+		Color:     strings.ToUpper(req.Color),
+		Mode:      req.Mode,
+		CreatedAt: time.Now(),
+	}
+	if danmaku.Color == "" {
+		danmaku.Color = danmakuDefaultColor
+	}
+	if danmaku.Mode == "" {
+		danmaku.Mode = model.DanmakuModeScroll
+	} else if !model.IsValidDanmakuMode(danmaku.Mode) {
+		return nil, errors.New("无效的弹幕模式")
+	}
+
+	if _, err := database.GetCollection(s.collection).InsertOne(ctx, danmaku); err != nil {
+		return nil, err
+	}
+
+	// 推送给同一视频的观看者
+	s.hub.Broadcast(danmakuRoom(videoID), ws.Message{Type: "danmaku", Data: danmaku})
+
+	return &danmaku, nil
+}
+
+// List 获取时间窗口内的弹幕，按时间正序。非公开视频的弹幕只有作者可以查看
+func (s *danmakuService) List(ctx context.Context, videoID, viewerID string, query model.DanmakuQuery) ([]model.Danmaku, error) {
+	if err := s.CheckVisible(ctx, videoID, viewerID); err != nil {
+		return nil, err
+	}
+
+	start, end := query.Start, query.End
+	if end <= start {
+		end = start + danmakuDefaultWindow
+	}
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = danmakuDefaultLimit
+	} else if limit > danmakuMaxLimit {
+		limit = danmakuMaxLimit
+	}
+
+	filter := bson.M{
+		"video_id": videoID,
+		"time":     bson.M{"$gte": start, "$lt": end},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "time", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := database.GetCollection(s.collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	danmakus := make([]model.Danmaku, 0)
+	if err = cursor.All(ctx, &danmakus); err != nil {
+		return nil, err
+	}
+	return danmakus, nil
+}
+
+// CheckVisible 检查视频是否存在，非公开视频的弹幕只有作者可以查看和订阅
+func (s *danmakuService) CheckVisible(ctx context.Context, videoID, viewerID string) error {
+	_, err := s.findVisibleVideo(ctx, videoID, viewerID)
+	return err
+}
+
+// findVisibleVideo 获取viewerID可见的视频
+func (s *danmakuService) findVisibleVideo(ctx context.Context, videoID, viewerID string) (*model.Video, error) {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, ErrDanmakuVideoNotFound
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		return nil, ErrDanmakuVideoNotFound
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != viewerID {
+		return nil, ErrDanmakuVideoNotFound
+	}
+	return &video, nil
+}
+
+// Subscribe 订阅视频的实时弹幕
+func (s *danmakuService) Subscribe(videoID string, client *ws.Client) {
+	s.hub.Join(danmakuRoom(videoID), client)
+}
+
+// Unsubscribe 取消订阅视频的实时弹幕
+func (s *danmakuService) Unsubscribe(videoID string, client *ws.Client) {
+	s.hub.Leave(danmakuRoom(videoID), client)
+}
+
+// checkRateLimit 按用户限制弹幕发送频率
+func (s *danmakuService) checkRateLimit(ctx context.Context, userID string) error {
+	cache := redis.GetClient()
+	if cache == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("rate:danmaku:%s", userID)
+	res, err := cache.Eval(ctx, script.LuaRateLimit, []string{key},
+		config.GlobalConfig.Danmaku.RateLimit,
+		config.GlobalConfig.Danmaku.RateWindow,
+	).Int()
+	if err != nil {
+		// 限流组件异常时放行，避免影响正常发送
+		slog.Error("[checkRateLimit] 弹幕限流检查失败", "error", err, "userId", userID)
+		return nil
+	}
+	if res != 0 {
+		return ErrDanmakuRateLimited
+	}
+	return nil
+}
+
+// danmakuRoom 视频弹幕的推送房间
+func danmakuRoom(videoID string) string {
+	return "danmaku:" + videoID
+}
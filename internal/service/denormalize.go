@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// videoCopyCollections 冗余保存了视频标题、封面和时长的集合
+var videoCopyCollections = []string{"favorites", "watch_history", "watch_later", "playlist_items"}
+
+func init() {
+	OnVideoChanged("video-copies", func(ctx context.Context, event VideoChangedEvent) error {
+		if !event.Changed(VideoFieldTitle, VideoFieldCover) {
+			return nil
+		}
+		_, err := syncVideoCopies(ctx, event.Video, false)
+		return err
+	})
+}
+
+// videoCover 冗余保存时使用的封面，没有封面时使用缩略图
+func videoCover(video model.Video) string {
+	if video.CoverURL != "" {
+		return video.CoverURL
+	}
+	return video.ThumbnailURL
+}
+
+// videoCopyDrift 查询与视频当前信息不一致的冗余记录
+func videoCopyDrift(video model.Video) bson.M {
+	return bson.M{
+		"video_id": video.ID.Hex(),
+		"$or": bson.A{
+			bson.M{"video_title": bson.M{"$ne": video.Title}},
+			bson.M{"cover_url": bson.M{"$ne": videoCover(video)}},
+			bson.M{"video_duration": bson.M{"$ne": video.Duration}},
+		},
+	}
+}
+
+// syncVideoCopies 更新所有冗余保存的视频信息，返回不一致（试运行）或已修复的记录数
+func syncVideoCopies(ctx context.Context, video model.Video, dryRun bool) (int64, error) {
+	filter := videoCopyDrift(video)
+	update := bson.M{"$set": bson.M{
+		"video_title":    video.Title,
+		"cover_url":      videoCover(video),
+		"video_duration": video.Duration,
+	}}
+
+	var total int64
+	for _, name := range videoCopyCollections {
+		collection := database.GetCollection(name)
+		if dryRun {
+			count, err := collection.CountDocuments(ctx, filter)
+			if err != nil {
+				return total, err
+			}
+			total += count
+			continue
+		}
+
+		result, err := collection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return total, err
+		}
+		total += result.ModifiedCount
+	}
+	return total, nil
+}
+
+// RepairVideoCopies 逐个扫描视频，修复收藏、观看历史、稍后再看和播放列表中过期的视频标题和封面
+func RepairVideoCopies(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	cursor, err := database.GetCollection("videos").Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{
+			"title": 1, "cover_url": 1, "thumbnail_url": 1, "duration": 1,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := &MigrationResult{}
+	for cursor.Next(ctx) {
+		var video model.Video
+		if err := cursor.Decode(&video); err != nil {
+			return result, err
+		}
+		result.Scanned++
+
+		fixed, err := syncVideoCopies(ctx, video, dryRun)
+		result.Fixed += fixed
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, cursor.Err()
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 测试冗余保存的封面：优先使用封面，没有封面时使用缩略图
+func TestVideoCover(t *testing.T) {
+	assert.Equal(t, "/uploads/cover.jpg", videoCover(model.Video{CoverURL: "/uploads/cover.jpg", ThumbnailURL: "/uploads/thumb.jpg"}))
+	assert.Equal(t, "/uploads/thumb.jpg", videoCover(model.Video{ThumbnailURL: "/uploads/thumb.jpg"}))
+	assert.Equal(t, "", videoCover(model.Video{}))
+}
+
+// 测试不一致记录的查询条件
+func TestVideoCopyDrift(t *testing.T) {
+	video := model.Video{ID: primitive.NewObjectID(), Title: "标题", ThumbnailURL: "/uploads/thumb.jpg", Duration: 60}
+
+	filter := videoCopyDrift(video)
+	assert.Equal(t, video.ID.Hex(), filter["video_id"])
+	assert.Equal(t, bson.A{
+		bson.M{"video_title": bson.M{"$ne": "标题"}},
+		bson.M{"cover_url": bson.M{"$ne": "/uploads/thumb.jpg"}},
+		bson.M{"video_duration": bson.M{"$ne": 60.0}},
+	}, filter["$or"])
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	"video-platform/internal/model"
+)
+
+// 视频变更事件中的字段
+const (
+	VideoFieldTitle       = "title"
+	VideoFieldDescription = "description"
+	VideoFieldStatus      = "status"
+	VideoFieldTags        = "tags"
+	VideoFieldCover       = "cover" // 封面或缩略图
+)
+
+// videoEventTimeout 单个事件处理器的超时时间
+const videoEventTimeout = time.Minute
+
+// VideoChangedEvent 视频信息变更事件，Video 为变更后的完整视频信息
+type VideoChangedEvent struct {
+	Video  model.Video
+	Fields []string // 发生变更的字段
+}
+
+// Changed 检查事件是否包含任一指定字段
+func (e VideoChangedEvent) Changed(fields ...string) bool {
+	for _, changed := range e.Fields {
+		for _, field := range fields {
+			if changed == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VideoChangedHandler 视频变更事件处理器
+type VideoChangedHandler func(ctx context.Context, event VideoChangedEvent) error
+
+type videoChangedSubscriber struct {
+	name    string
+	handler VideoChangedHandler
+}
+
+var (
+	videoChangedMu          sync.RWMutex
+	videoChangedSubscribers []videoChangedSubscriber
+)
+
+// OnVideoChanged 订阅视频变更事件，name 用于日志
+func OnVideoChanged(name string, handler VideoChangedHandler) {
+	videoChangedMu.Lock()
+	defer videoChangedMu.Unlock()
+	videoChangedSubscribers = append(videoChangedSubscribers, videoChangedSubscriber{name: name, handler: handler})
+}
+
+// publishVideoChanged 异步分发视频变更事件，各处理器依次执行，单个处理器失败不影响其他处理器
+func publishVideoChanged(video model.Video, fields ...string) {
+	if len(fields) == 0 {
+		return
+	}
+
+	videoChangedMu.RLock()
+	subscribers := append([]videoChangedSubscriber(nil), videoChangedSubscribers...)
+	videoChangedMu.RUnlock()
+	if len(subscribers) == 0 {
+		return
+	}
+
+	event := VideoChangedEvent{Video: video, Fields: fields}
+	go func() {
+		for _, sub := range subscribers {
+			dispatchVideoChanged(sub, event)
+		}
+	}()
+}
+
+// dispatchVideoChanged 执行单个处理器并记录错误
+func dispatchVideoChanged(sub videoChangedSubscriber, event VideoChangedEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), videoEventTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("[VideoChanged] 事件处理器异常", "handler", sub.name, "panic", r, "videoId", event.Video.ID.Hex())
+		}
+	}()
+
+	if err := sub.handler(ctx, event); err != nil {
+		slog.Error("[VideoChanged] 事件处理失败", "handler", sub.name, "error", err, "videoId", event.Video.ID.Hex())
+	}
+}
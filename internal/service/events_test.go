@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 测试事件字段匹配
+func TestVideoChangedEventChanged(t *testing.T) {
+	event := VideoChangedEvent{Fields: []string{VideoFieldTitle, VideoFieldTags}}
+
+	assert.True(t, event.Changed(VideoFieldTitle))
+	assert.True(t, event.Changed(VideoFieldCover, VideoFieldTags))
+	assert.False(t, event.Changed(VideoFieldStatus))
+	assert.False(t, VideoChangedEvent{}.Changed(VideoFieldTitle))
+}
+
+// 测试事件分发：处理器出错或异常不影响后续处理器
+func TestPublishVideoChanged(t *testing.T) {
+	received := make(chan VideoChangedEvent, 1)
+	OnVideoChanged("test-error", func(ctx context.Context, event VideoChangedEvent) error {
+		if !event.Changed("test") {
+			return nil
+		}
+		return errors.New("处理失败")
+	})
+	OnVideoChanged("test-panic", func(ctx context.Context, event VideoChangedEvent) error {
+		if !event.Changed("test") {
+			return nil
+		}
+		panic("处理异常")
+	})
+	OnVideoChanged("test-receiver", func(ctx context.Context, event VideoChangedEvent) error {
+		if event.Changed("test") {
+			received <- event
+		}
+		return nil
+	})
+
+	video := model.Video{ID: primitive.NewObjectID(), Title: "新标题"}
+	publishVideoChanged(video, "test")
+
+	select {
+	case event := <-received:
+		assert.Equal(t, video.ID, event.Video.ID)
+		assert.Equal(t, []string{"test"}, event.Fields)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到视频变更事件")
+	}
+}
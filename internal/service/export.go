@@ -0,0 +1,378 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 导出相关错误
+var (
+	ErrInvalidExportFormat = errors.New("不支持的导出格式")
+	ErrExportVideoNotFound = errors.New("视频不存在")
+	ErrExportTooMany       = errors.New("一次最多导出20个视频")
+)
+
+const (
+	// exportVideoLimit 一次最多导出的视频数
+	exportVideoLimit = 20
+	// exportCueDuration 时间点标记和笔记在字幕中的显示时长（秒）
+	exportCueDuration = 5.0
+)
+
+// ExportService 标记和笔记导出服务接口
+type ExportService interface {
+	Export(ctx context.Context, userID string, query *model.ExportQuery) (*model.ExportFile, error)
+}
+
+type exportService struct {
+	markService MarkService
+}
+
+// NewExportService 创建导出服务实例
+func NewExportService(markService MarkService) ExportService {
+	if markService == nil {
+		markService = NewMarkService()
+	}
+	return &exportService{
+		markService: markService,
+	}
+}
+
+// Export 导出用户在指定视频上的标记、注释和笔记，多个视频时打包为zip
+func (s *exportService) Export(ctx context.Context, userID string, query *model.ExportQuery) (*model.ExportFile, error) {
+	format := query.Format
+	if format == "" {
+		format = model.ExportFormatJSON
+	}
+	if !model.IsValidExportFormat(format) {
+		return nil, ErrInvalidExportFormat
+	}
+
+	videoIDs := uniqueStrings(query.VideoIDs)
+	if len(videoIDs) == 0 {
+		return nil, ErrExportVideoNotFound
+	}
+	if len(videoIDs) > exportVideoLimit {
+		return nil, ErrExportTooMany
+	}
+
+	titles, err := loadVideoTitles(ctx, videoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*model.ExportFile, 0, len(videoIDs))
+	names := make(map[string]int)
+	for _, videoID := range videoIDs {
+		title, ok := titles[videoID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrExportVideoNotFound, videoID)
+		}
+
+		video := &model.ExportVideo{VideoID: videoID, Title: title}
+		if video.Marks, err = s.markService.GetMarks(ctx, userID, &model.MarkQuery{VideoID: videoID}); err != nil {
+			return nil, err
+		}
+		if video.Notes, err = s.markService.GetNotes(ctx, userID, videoID); err != nil {
+			return nil, err
+		}
+
+		data, err := renderExport(video, format)
+		if err != nil {
+			return nil, err
+		}
+
+		// 同名视频在zip中追加序号，避免文件互相覆盖
+		name := exportFilename(title)
+		names[name]++
+		if n := names[name]; n > 1 {
+			name = fmt.Sprintf("%s (%d)", name, n)
+		}
+		files = append(files, &model.ExportFile{
+			Filename:    name + "." + format,
+			ContentType: model.ExportContentTypes[format],
+			Data:        data,
+		})
+	}
+
+	if len(files) == 1 {
+		return files[0], nil
+	}
+	return zipExportFiles(files)
+}
+
+// loadVideoTitles 批量查询视频标题
+func loadVideoTitles(ctx context.Context, videoIDs []string) (map[string]string, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(videoIDs))
+	for _, id := range videoIDs {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrExportVideoNotFound, id)
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	cursor, err := database.GetCollection("videos").Find(ctx,
+		bson.M{"_id": bson.M{"$in": objectIDs}},
+		options.Find().SetProjection(bson.M{"title": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var videos []model.Video
+	if err := cursor.All(ctx, &videos); err != nil {
+		return nil, err
+	}
+	titles := make(map[string]string, len(videos))
+	for _, v := range videos {
+		titles[v.ID.Hex()] = v.Title
+	}
+	return titles, nil
+}
+
+// uniqueStrings 去掉空值和重复值，保持原有顺序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// exportFilename 根据视频标题生成文件名（不含扩展名），去掉文件系统不允许的字符
+func exportFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if runes := []rune(name); len(runes) > 80 {
+		name = string(runes[:80])
+	}
+	if name == "" {
+		name = "video"
+	}
+	return name
+}
+
+// zipExportFiles 把多个导出文件打包为zip
+func zipExportFiles(files []*model.ExportFile) (*model.ExportFile, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		fw, err := w.Create(f.Filename)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &model.ExportFile{
+		Filename:    "marks.zip",
+		ContentType: "application/zip",
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// exportEntry 导出的一条记录，标记和笔记合并后按时间排序
+type exportEntry struct {
+	Kind        string // mark 或 note
+	Start       float64
+	End         float64 // 区间标记的结束时间，其他记录为0
+	Category    string  // 标记分类名称
+	Content     string
+	Annotations []string
+}
+
+// exportEntries 合并标记和笔记并按开始时间排序，同一时间标记在前
+func exportEntries(video *model.ExportVideo) []exportEntry {
+	entries := make([]exportEntry, 0, len(video.Marks)+len(video.Notes))
+	for _, m := range video.Marks {
+		entry := exportEntry{Kind: "mark", Start: m.Timestamp, Content: m.Content}
+		if m.IsRange() {
+			entry.End = m.EndTime
+		}
+		if category, ok := model.FindMarkCategory(m.Category); ok {
+			entry.Category = category.Label
+		}
+		for _, a := range m.Annotations {
+			entry.Annotations = append(entry.Annotations, a.Content)
+		}
+		entries = append(entries, entry)
+	}
+	for _, n := range video.Notes {
+		entries = append(entries, exportEntry{Kind: "note", Start: n.Timestamp, Content: n.Content})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start < entries[j].Start
+	})
+	return entries
+}
+
+// cueEnd 字幕的结束时间，时间点记录显示 exportCueDuration 秒
+func (e exportEntry) cueEnd() float64 {
+	if e.End > e.Start {
+		return e.End
+	}
+	return e.Start + exportCueDuration
+}
+
+// title 记录的标题，如"标记·重点"
+func (e exportEntry) title() string {
+	label := "标记"
+	if e.Kind == "note" {
+		label = "笔记"
+	}
+	if e.Category != "" {
+		label += "·" + e.Category
+	}
+	return label
+}
+
+// renderExport 按格式渲染单个视频的导出内容
+func renderExport(video *model.ExportVideo, format string) ([]byte, error) {
+	switch format {
+	case model.ExportFormatVTT:
+		return renderSubtitles(video, true), nil
+	case model.ExportFormatSRT:
+		return renderSubtitles(video, false), nil
+	case model.ExportFormatMarkdown:
+		return renderMarkdown(video), nil
+	case model.ExportFormatCSV:
+		return renderCSV(video)
+	case model.ExportFormatJSON:
+		return json.MarshalIndent(video, "", "  ")
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+}
+
+// formatClock 把秒数格式化为 hh:mm:ss
+func formatClock(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	total := int64(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total%3600/60, total%60)
+}
+
+// formatCueTime 把秒数格式化为字幕时间 hh:mm:ss.mmm，SRT 使用逗号分隔毫秒
+func formatCueTime(seconds float64, sep string) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms%3600000/60000, ms%60000/1000, sep, ms%1000)
+}
+
+// cueText 字幕文本不能包含空行和"-->"，否则会被解析为新的字幕块
+func cueText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "-->", "->"), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimRight(line, "\r \t"); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// renderSubtitles 渲染 WebVTT 或 SRT 字幕
+func renderSubtitles(video *model.ExportVideo, vtt bool) []byte {
+	var b strings.Builder
+	sep := ","
+	if vtt {
+		sep = "."
+		b.WriteString("WEBVTT\n\n")
+	}
+	for i, e := range exportEntries(video) {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n", i+1, formatCueTime(e.Start, sep), formatCueTime(e.cueEnd(), sep))
+		fmt.Fprintf(&b, "[%s] %s\n", e.title(), cueText(e.Content))
+		for _, a := range e.Annotations {
+			fmt.Fprintf(&b, "- %s\n", cueText(a))
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// renderMarkdown 渲染 Markdown 文档
+func renderMarkdown(video *model.ExportVideo) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", video.Title)
+	for _, e := range exportEntries(video) {
+		clock := formatClock(e.Start)
+		if e.End > e.Start {
+			clock += " - " + formatClock(e.End)
+		}
+		fmt.Fprintf(&b, "- **%s** `%s` %s\n", e.title(), clock, indentMarkdown(e.Content, "  "))
+		for _, a := range e.Annotations {
+			fmt.Fprintf(&b, "  - %s\n", indentMarkdown(a, "    "))
+		}
+	}
+	return []byte(b.String())
+}
+
+// indentMarkdown 多行内容缩进到列表项下
+func indentMarkdown(text, indent string) string {
+	return strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n"+indent)
+}
+
+// renderCSV 渲染 CSV 表格，注释作为单独的行跟在所属标记之后
+func renderCSV(video *model.ExportVideo) ([]byte, error) {
+	var buf bytes.Buffer
+	// 写入BOM，Excel 打开时才能正确识别 UTF-8 中文
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"type", "start", "end", "start_seconds", "end_seconds", "category", "content"}}
+	for _, e := range exportEntries(video) {
+		end, endSeconds := "", ""
+		if e.End > e.Start {
+			end, endSeconds = formatClock(e.End), strconv.FormatFloat(e.End, 'f', -1, 64)
+		}
+		start, startSeconds := formatClock(e.Start), strconv.FormatFloat(e.Start, 'f', -1, 64)
+		rows = append(rows, []string{e.Kind, start, end, startSeconds, endSeconds, csvCell(e.Category), csvCell(e.Content)})
+		for _, a := range e.Annotations {
+			rows = append(rows, []string{"annotation", start, end, startSeconds, endSeconds, csvCell(e.Category), csvCell(a)})
+		}
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvCell 用户输入以 = + - @ 或制表符、回车开头时加上单引号，防止 Excel 打开时当作公式执行
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
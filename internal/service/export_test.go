@@ -0,0 +1,97 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTestVideo() *model.ExportVideo {
+	return &model.ExportVideo{
+		VideoID: "v1",
+		Title:   "线性代数 第1讲",
+		Marks: []model.Mark{
+			{Timestamp: 3725.5, Content: "特征值", Category: model.MarkCategoryKeyPoint,
+				Annotations: []model.Annotation{{Content: "课后复习"}}},
+			{Timestamp: 10, EndTime: 70, Content: "例题 --> 解答"},
+		},
+		Notes: []model.Note{{Timestamp: 30, Content: "第一段\n\n第二段"}},
+	}
+}
+
+// 测试时间格式化
+func TestFormatClock(t *testing.T) {
+	assert.Equal(t, "00:00:00", formatClock(-1))
+	assert.Equal(t, "01:02:05", formatClock(3725.9))
+	assert.Equal(t, "01:02:05.500", formatCueTime(3725.5, "."))
+	assert.Equal(t, "00:00:10,000", formatCueTime(10, ","))
+}
+
+// 测试标记和笔记按时间合并排序
+func TestExportEntries(t *testing.T) {
+	entries := exportEntries(exportTestVideo())
+	assert.Len(t, entries, 3)
+	assert.Equal(t, []float64{10, 30, 3725.5}, []float64{entries[0].Start, entries[1].Start, entries[2].Start})
+	assert.Equal(t, "note", entries[1].Kind)
+	assert.Equal(t, "标记·重点", entries[2].title())
+}
+
+// 测试WebVTT和SRT字幕渲染
+func TestRenderSubtitles(t *testing.T) {
+	vtt := string(renderSubtitles(exportTestVideo(), true))
+	assert.True(t, strings.HasPrefix(vtt, "WEBVTT\n\n1\n00:00:10.000 --> 00:01:10.000\n[标记] 例题 -> 解答\n"))
+	assert.Contains(t, vtt, "2\n00:00:30.000 --> 00:00:35.000\n[笔记] 第一段\n第二段\n\n")
+	assert.Contains(t, vtt, "[标记·重点] 特征值\n- 课后复习\n")
+
+	srt := string(renderSubtitles(exportTestVideo(), false))
+	assert.True(t, strings.HasPrefix(srt, "1\n00:00:10,000 --> 00:01:10,000\n"))
+}
+
+// 测试CSV渲染，注释单独成行
+func TestRenderCSV(t *testing.T) {
+	data, err := renderCSV(exportTestVideo())
+	assert.NoError(t, err)
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff"))).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 5)
+	assert.Equal(t, []string{"type", "start", "end", "start_seconds", "end_seconds", "category", "content"}, rows[0])
+	assert.Equal(t, []string{"mark", "00:00:10", "00:01:10", "10", "70", "", "例题 --> 解答"}, rows[1])
+	assert.Equal(t, "第一段\n\n第二段", rows[2][6])
+	assert.Equal(t, []string{"annotation", "01:02:05", "", "3725.5", "", "重点", "课后复习"}, rows[4])
+}
+
+// 测试CSV单元格防公式注入
+func TestCSVCell(t *testing.T) {
+	for _, value := range []string{"=1+1", "+1", "-2", "@SUM(A1)", "\tx"} {
+		assert.Equal(t, "'"+value, csvCell(value), value)
+	}
+	assert.Equal(t, "普通内容 = 1", csvCell("普通内容 = 1"))
+	assert.Equal(t, "", csvCell(""))
+}
+
+// 测试文件名清理
+func TestExportFilename(t *testing.T) {
+	assert.Equal(t, "a_b_c", exportFilename(" a/b:c "))
+	assert.Equal(t, "video", exportFilename(""))
+	assert.Len(t, []rune(exportFilename(strings.Repeat("长", 100))), 80)
+}
+
+// 测试多个视频打包为zip
+func TestZipExportFiles(t *testing.T) {
+	file, err := zipExportFiles([]*model.ExportFile{
+		{Filename: "a.md", Data: []byte("# a")},
+		{Filename: "b.md", Data: []byte("# b")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "application/zip", file.ContentType)
+
+	r, err := zip.NewReader(bytes.NewReader(file.Data), int64(len(file.Data)))
+	assert.NoError(t, err)
+	assert.Len(t, r.File, 2)
+	assert.Equal(t, "b.md", r.File[1].Name)
+}
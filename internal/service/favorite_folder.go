@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrFavoriteFolderNotFound = errors.New("收藏夹不存在")
+	ErrFavoriteFolderExists   = errors.New("收藏夹名称已存在")
+	ErrFavoriteFolderLimit    = errors.New("收藏夹数量已达上限")
+	ErrFavoriteNotFound       = errors.New("未收藏该视频")
+)
+
+// favoriteFolderMaxCount 每个用户最多创建的收藏夹数
+const favoriteFolderMaxCount = 50
+
+// FavoriteFolderService 收藏夹服务接口
+type FavoriteFolderService interface {
+	List(ctx context.Context, userID string) (*model.FavoriteFolderListResponse, error)
+	Create(ctx context.Context, userID, name string) (*model.FavoriteFolder, error)
+	Rename(ctx context.Context, userID, folderID, name string) (*model.FavoriteFolder, error)
+	Delete(ctx context.Context, userID, folderID string) error
+	MoveFavorite(ctx context.Context, userID, videoID, folderID string) error
+}
+
+type favoriteFolderService struct {
+	collection string
+}
+
+// NewFavoriteFolderService 创建收藏夹服务实例
+func NewFavoriteFolderService() FavoriteFolderService {
+	return &favoriteFolderService{
+		collection: "favorite_folders",
+	}
+}
+
+// List 获取用户的收藏夹及每个收藏夹中的收藏数
+func (s *favoriteFolderService) List(ctx context.Context, userID string) (*model.FavoriteFolderListResponse, error) {
+	cursor, err := database.GetCollection(s.collection).Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	folders := []model.FavoriteFolder{}
+	if err := cursor.All(ctx, &folders); err != nil {
+		return nil, err
+	}
+
+	// 统计各收藏夹中的收藏数，folder_id为空的属于默认收藏夹
+	cursor, err = database.GetCollection("favorites").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$folder_id", ""}},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		FolderID string `bson:"_id"`
+		Count    int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	resp := &model.FavoriteFolderListResponse{Folders: folders}
+	countMap := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		countMap[c.FolderID] = c.Count
+	}
+	resp.DefaultCount = countMap[""]
+	for i := range resp.Folders {
+		resp.Folders[i].Count = countMap[resp.Folders[i].ID.Hex()]
+	}
+	return resp, nil
+}
+
+// Create 创建收藏夹
+func (s *favoriteFolderService) Create(ctx context.Context, userID, name string) (*model.FavoriteFolder, error) {
+	collection := database.GetCollection(s.collection)
+	count, err := collection.CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if count >= favoriteFolderMaxCount {
+		return nil, ErrFavoriteFolderLimit
+	}
+	if err := s.checkNameAvailable(ctx, userID, name, primitive.NilObjectID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	folder := &model.FavoriteFolder{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := collection.InsertOne(ctx, folder); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrFavoriteFolderExists
+		}
+		return nil, err
+	}
+	return folder, nil
+}
+
+// Rename 重命名收藏夹
+func (s *favoriteFolderService) Rename(ctx context.Context, userID, folderID, name string) (*model.FavoriteFolder, error) {
+	folder, err := findFavoriteFolder(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, userID, name, folder.ID); err != nil {
+		return nil, err
+	}
+
+	folder.Name = name
+	folder.UpdatedAt = time.Now()
+	_, err = database.GetCollection(s.collection).UpdateOne(ctx,
+		bson.M{"_id": folder.ID},
+		bson.M{"$set": bson.M{"name": folder.Name, "updated_at": folder.UpdatedAt}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrFavoriteFolderExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// Delete 删除收藏夹，其中的收藏移回默认收藏夹
+func (s *favoriteFolderService) Delete(ctx context.Context, userID, folderID string) error {
+	folder, err := findFavoriteFolder(ctx, userID, folderID)
+	if err != nil {
+		return err
+	}
+
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		if _, err := database.GetCollection("favorites").UpdateMany(sessCtx,
+			bson.M{"user_id": userID, "folder_id": folderID},
+			bson.M{"$unset": bson.M{"folder_id": ""}},
+		); err != nil {
+			return nil, err
+		}
+		_, err := database.GetCollection(s.collection).DeleteOne(sessCtx, bson.M{"_id": folder.ID})
+		return nil, err
+	})
+	return err
+}
+
+// MoveFavorite 将已收藏的视频移动到指定收藏夹，folderID为空表示移回默认收藏夹
+func (s *favoriteFolderService) MoveFavorite(ctx context.Context, userID, videoID, folderID string) error {
+	update := bson.M{"$unset": bson.M{"folder_id": ""}}
+	if folderID != "" {
+		if _, err := findFavoriteFolder(ctx, userID, folderID); err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"folder_id": folderID}}
+	}
+
+	result, err := database.GetCollection("favorites").UpdateOne(ctx,
+		bson.M{"user_id": userID, "video_id": videoID},
+		update,
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrFavoriteNotFound
+	}
+	return nil
+}
+
+// checkNameAvailable 检查收藏夹名称是否与用户的其他收藏夹重复
+func (s *favoriteFolderService) checkNameAvailable(ctx context.Context, userID, name string, exclude primitive.ObjectID) error {
+	count, err := database.GetCollection(s.collection).CountDocuments(ctx, bson.M{
+		"user_id": userID,
+		"name":    name,
+		"_id":     bson.M{"$ne": exclude},
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrFavoriteFolderExists
+	}
+	return nil
+}
+
+// findFavoriteFolder 获取用户自己的收藏夹
+func findFavoriteFolder(ctx context.Context, userID, folderID string) (*model.FavoriteFolder, error) {
+	objectID, err := primitive.ObjectIDFromHex(folderID)
+	if err != nil {
+		return nil, ErrFavoriteFolderNotFound
+	}
+	var folder model.FavoriteFolder
+	err = database.GetCollection("favorite_folders").FindOne(ctx, bson.M{"_id": objectID, "user_id": userID}).Decode(&folder)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrFavoriteFolderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
@@ -0,0 +1,593 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	feedDefaultSize     = 20                 // 默认每页数量
+	feedMaxSize         = 50                 // 每页数量上限
+	feedInboxTTL        = 7 * 24 * time.Hour // 收件箱过期时间，不活跃用户的收件箱自动清理
+	feedTieAllowance    = 20                 // 读取收件箱时额外读取的条数，用于跳过与游标同分的记录
+	feedFanOutBatchSize = 500                // 推送时每批处理的粉丝数
+	feedFollowBackfill  = 50                 // 新关注时补充到收件箱的视频数
+	feedJobTimeout      = 5 * time.Minute    // 异步推送/重建任务的超时时间
+)
+
+// FeedService 关注动态流服务接口
+type FeedService interface {
+	Timeline(ctx context.Context, userID string, query model.FeedQuery) (*model.FeedResponse, error)
+}
+
+type feedService struct{}
+
+// NewFeedService 创建动态流服务实例
+func NewFeedService() FeedService {
+	return &feedService{}
+}
+
+// feedEntry 动态流中的一条记录，按 Score（视频发布时间，毫秒）倒序、ID倒序排列
+type feedEntry struct {
+	ID    string
+	Score int64
+}
+
+// before 判断 e 是否排在 other 之前
+func (e feedEntry) before(other feedEntry) bool {
+	if e.Score != other.Score {
+		return e.Score > other.Score
+	}
+	return e.ID > other.ID
+}
+
+// Timeline 获取关注的作者发布的视频。
+// 普通作者发布时推送到粉丝的Redis收件箱，大V的视频在读取时从MongoDB拉取，两者合并后分页。
+func (s *feedService) Timeline(ctx context.Context, userID string, query model.FeedQuery) (*model.FeedResponse, error) {
+	size := query.Size
+	if size < 1 {
+		size = feedDefaultSize
+	}
+	if size > feedMaxSize {
+		size = feedMaxSize
+	}
+
+	var after *feedEntry
+	if query.Cursor != "" {
+		entry, err := decodeFeedCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &entry
+	}
+
+	resp := &model.FeedResponse{Items: []model.FeedItem{}}
+	following, err := loadFollowingIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(following) == 0 {
+		return resp, nil
+	}
+
+	candidates := make([]feedEntry, 0, size*2)
+	pullFrom := following
+
+	// 1. 读取收件箱（推模式），收件箱存在时只需拉取大V的视频
+	if cache := redis.GetClient(); cache != nil {
+		inbox, exists, err := readInbox(ctx, cache, userID, after, size+1)
+		switch {
+		case err != nil:
+			slog.Warn("[Timeline] 读取收件箱失败，改为全部拉取", "error", err, "userId", userID)
+		case exists:
+			candidates = append(candidates, inbox...)
+			if pullFrom, err = loadBigCreators(ctx, following); err != nil {
+				return nil, err
+			}
+		default:
+			// 收件箱不存在（新用户或长期未访问），本次全部拉取并异步重建
+			rebuildInboxAsync(userID)
+		}
+	}
+
+	// 2. 拉取（拉模式）
+	if len(pullFrom) > 0 {
+		pulled, err := pullCreatorVideos(ctx, pullFrom, after, size+1)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, pulled...)
+	}
+
+	// 3. 合并分页
+	candidates = mergeFeedEntries(candidates, after)
+	if len(candidates) > size {
+		resp.HasMore = true
+		candidates = candidates[:size]
+	}
+	if resp.HasMore && len(candidates) > 0 {
+		resp.NextCursor = encodeFeedCursor(candidates[len(candidates)-1])
+	}
+
+	followingSet := make(map[string]bool, len(following))
+	for _, id := range following {
+		followingSet[id] = true
+	}
+	resp.Items, err = buildFeedItems(ctx, userID, candidates, followingSet)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// buildFeedItems 按顺序加载视频、作者和当前用户的点赞/收藏状态。
+// 收件箱中已删除、已设为私有、已取消关注以及当前用户拉黑或静音的作者的视频会被过滤掉，followingSet为nil时不按关注关系过滤
+func buildFeedItems(ctx context.Context, userID string, entries []feedEntry, followingSet map[string]bool) ([]model.FeedItem, error) {
+	items := make([]model.FeedItem, 0, len(entries))
+	if len(entries) == 0 {
+		return items, nil
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(entries))
+	videoIDs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if objectID, err := primitive.ObjectIDFromHex(e.ID); err == nil {
+			objectIDs = append(objectIDs, objectID)
+			videoIDs = append(videoIDs, e.ID)
+		}
+	}
+
+	cursor, err := database.GetCollection("videos").Find(ctx, bson.M{
+		"_id":    bson.M{"$in": objectIDs},
+		"status": model.VideoStatusPublic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var videos []model.Video
+	if err := cursor.All(ctx, &videos); err != nil {
+		return nil, err
+	}
+	hidden, err := hiddenUserIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	hiddenSet := make(map[string]bool, len(hidden))
+	for _, id := range hidden {
+		hiddenSet[id] = true
+	}
+
+	videoMap := make(map[string]model.Video, len(videos))
+	authorIDs := make([]string, 0, len(videos))
+	for _, v := range videos {
+		if followingSet != nil && !followingSet[v.UserID] || hiddenSet[v.UserID] {
+			continue
+		}
+		videoMap[v.ID.Hex()] = v
+		authorIDs = append(authorIDs, v.UserID)
+	}
+
+	authors, err := loadUserBriefs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	liked, err := userVideoSet(ctx, "likes", userID, videoIDs)
+	if err != nil {
+		return nil, err
+	}
+	favorited, err := userVideoSet(ctx, "favorites", userID, videoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		v, ok := videoMap[e.ID]
+		if !ok {
+			continue
+		}
+		items = append(items, model.FeedItem{
+			Video:      v,
+			Author:     authors[v.UserID],
+			IsLiked:    liked[e.ID],
+			IsFavorite: favorited[e.ID],
+		})
+	}
+	return items, nil
+}
+
+// userVideoSet 查询用户在指定集合（likes/favorites）中关联了哪些视频
+func userVideoSet(ctx context.Context, name, userID string, videoIDs []string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	if userID == "" || len(videoIDs) == 0 {
+		return set, nil
+	}
+
+	cursor, err := database.GetCollection(name).Find(ctx,
+		bson.M{"user_id": userID, "video_id": bson.M{"$in": videoIDs}},
+		options.Find().SetProjection(bson.M{"video_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		VideoID string `bson:"video_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		set[d.VideoID] = true
+	}
+	return set, nil
+}
+
+// loadFollowingIDs 获取用户关注的所有作者ID
+func loadFollowingIDs(ctx context.Context, userID string) ([]string, error) {
+	cursor, err := database.GetCollection("follows").Find(ctx,
+		bson.M{"follower_id": userID},
+		options.Find().SetProjection(bson.M{"followee_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var follows []model.Follow
+	if err := cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(follows))
+	for _, f := range follows {
+		ids = append(ids, f.FolloweeID)
+	}
+	return ids, nil
+}
+
+// loadBigCreators 从作者列表中筛选出粉丝数超过推送阈值的大V
+func loadBigCreators(ctx context.Context, creatorIDs []string) ([]string, error) {
+	cursor, err := database.GetCollection("follow_stats").Find(ctx,
+		bson.M{
+			"_id":       bson.M{"$in": creatorIDs},
+			"followers": bson.M{"$gt": config.GlobalConfig.Feed.PushThreshold},
+		},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var stats []model.FollowStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(stats))
+	for _, st := range stats {
+		ids = append(ids, st.UserID)
+	}
+	return ids, nil
+}
+
+// pullCreatorVideos 从MongoDB拉取指定作者发布的公开视频
+func pullCreatorVideos(ctx context.Context, creatorIDs []string, after *feedEntry, limit int) ([]feedEntry, error) {
+	filter := bson.M{
+		"user_id": bson.M{"$in": creatorIDs},
+		"status":  model.VideoStatusPublic,
+	}
+	if after != nil {
+		filter["created_at"] = bson.M{"$lte": time.UnixMilli(after.Score)}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + feedTieAllowance)).
+		SetProjection(bson.M{"_id": 1, "created_at": 1})
+	cursor, err := database.GetCollection("videos").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var videos []model.Video
+	if err := cursor.All(ctx, &videos); err != nil {
+		return nil, err
+	}
+
+	entries := make([]feedEntry, 0, len(videos))
+	for _, v := range videos {
+		entries = append(entries, feedEntry{ID: v.ID.Hex(), Score: v.CreatedAt.UnixMilli()})
+	}
+	return entries, nil
+}
+
+// readInbox 读取收件箱中排在游标之后的记录，exists 表示收件箱是否存在
+func readInbox(ctx context.Context, cache *goredis.Client, userID string, after *feedEntry, limit int) ([]feedEntry, bool, error) {
+	key := feedInboxKey(userID)
+	max := "+inf"
+	if after != nil {
+		max = strconv.FormatInt(after.Score, 10)
+	}
+
+	results, err := cache.ZRevRangeByScoreWithScores(ctx, key, &goredis.ZRangeBy{
+		Max:   max,
+		Min:   "-inf",
+		Count: int64(limit + feedTieAllowance),
+	}).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(results) == 0 {
+		exists, err := cache.Exists(ctx, key).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		return nil, exists > 0, nil
+	}
+
+	// 访问时续期
+	cache.Expire(ctx, key, feedInboxTTL)
+
+	entries := make([]feedEntry, 0, len(results))
+	for _, z := range results {
+		member, _ := z.Member.(string)
+		entries = append(entries, feedEntry{ID: member, Score: int64(z.Score)})
+	}
+	return entries, true, nil
+}
+
+// mergeFeedEntries 合并去重并排序，只保留排在游标之后的记录
+func mergeFeedEntries(entries []feedEntry, after *feedEntry) []feedEntry {
+	seen := make(map[string]bool, len(entries))
+	merged := make([]feedEntry, 0, len(entries))
+	for _, e := range entries {
+		if seen[e.ID] {
+			continue
+		}
+		if after != nil && !after.before(e) {
+			continue
+		}
+		seen[e.ID] = true
+		merged = append(merged, e)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].before(merged[j])
+	})
+	return merged
+}
+
+func init() {
+	// 视频设为公开时推送到粉丝的收件箱，设为私有的视频在读取时过滤
+	OnVideoChanged("feed-fanout", func(ctx context.Context, event VideoChangedEvent) error {
+		if !event.Changed(VideoFieldStatus) || event.Video.Status != model.VideoStatusPublic {
+			return nil
+		}
+		return fanOutVideo(ctx, event.Video)
+	})
+}
+
+// fanOutVideoAsync 作者发布公开视频后，异步推送到粉丝的收件箱；大V的视频由粉丝读取时拉取，不推送
+func fanOutVideoAsync(video model.Video) {
+	if video.Status != model.VideoStatusPublic || redis.GetClient() == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), feedJobTimeout)
+		defer cancel()
+		if err := fanOutVideo(ctx, video); err != nil {
+			slog.Error("[fanOutVideo] 推送视频到粉丝收件箱失败", "error", err, "videoId", video.ID.Hex())
+		}
+	}()
+}
+
+// fanOutVideo 将视频推送到所有粉丝已存在的收件箱
+func fanOutVideo(ctx context.Context, video model.Video) error {
+	cache := redis.GetClient()
+	if cache == nil {
+		return nil
+	}
+
+	stats, err := loadFollowStats(ctx, video.UserID)
+	if err != nil {
+		return err
+	}
+	if stats.Followers > config.GlobalConfig.Feed.PushThreshold {
+		return nil
+	}
+
+	cursor, err := database.GetCollection("follows").Find(ctx,
+		bson.M{"followee_id": video.UserID},
+		options.Find().SetProjection(bson.M{"follower_id": 1}).SetBatchSize(feedFanOutBatchSize),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	entry := feedEntry{ID: video.ID.Hex(), Score: video.CreatedAt.UnixMilli()}
+	batch := make([]string, 0, feedFanOutBatchSize)
+	for cursor.Next(ctx) {
+		var f model.Follow
+		if err := cursor.Decode(&f); err != nil {
+			return err
+		}
+		batch = append(batch, f.FollowerID)
+		if len(batch) == feedFanOutBatchSize {
+			if err := pushToInboxes(ctx, cache, batch, []feedEntry{entry}); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return pushToInboxes(ctx, cache, batch, []feedEntry{entry})
+}
+
+// pushToInboxes 将记录写入已存在的收件箱并裁剪长度。
+// 不存在的收件箱不写入，避免只包含部分视频的收件箱掩盖了读取时的全量重建
+func pushToInboxes(ctx context.Context, cache *goredis.Client, userIDs []string, entries []feedEntry) error {
+	if len(userIDs) == 0 || len(entries) == 0 {
+		return nil
+	}
+
+	pipe := cache.Pipeline()
+	checks := make([]*goredis.IntCmd, len(userIDs))
+	for i, id := range userIDs {
+		checks[i] = pipe.Exists(ctx, feedInboxKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	members := make([]goredis.Z, 0, len(entries))
+	for _, e := range entries {
+		members = append(members, goredis.Z{Score: float64(e.Score), Member: e.ID})
+	}
+
+	pipe = cache.Pipeline()
+	pending := 0
+	for i, id := range userIDs {
+		if checks[i].Val() == 0 {
+			continue
+		}
+		key := feedInboxKey(id)
+		pipe.ZAdd(ctx, key, members...)
+		pipe.ZRemRangeByRank(ctx, key, 0, -(config.GlobalConfig.Feed.InboxSize + 1))
+		pending++
+	}
+	if pending == 0 {
+		return nil
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// rebuildInboxAsync 异步重建用户的收件箱
+func rebuildInboxAsync(userID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), feedJobTimeout)
+		defer cancel()
+		if err := rebuildInbox(ctx, userID); err != nil {
+			slog.Error("[rebuildInbox] 重建收件箱失败", "error", err, "userId", userID)
+		}
+	}()
+}
+
+// rebuildInbox 用关注的普通作者最近发布的视频重建收件箱
+func rebuildInbox(ctx context.Context, userID string) error {
+	cache := redis.GetClient()
+	if cache == nil {
+		return nil
+	}
+
+	following, err := loadFollowingIDs(ctx, userID)
+	if err != nil || len(following) == 0 {
+		return err
+	}
+	big, err := loadBigCreators(ctx, following)
+	if err != nil {
+		return err
+	}
+	bigSet := make(map[string]bool, len(big))
+	for _, id := range big {
+		bigSet[id] = true
+	}
+	creators := make([]string, 0, len(following))
+	for _, id := range following {
+		if !bigSet[id] {
+			creators = append(creators, id)
+		}
+	}
+	if len(creators) == 0 {
+		return nil
+	}
+
+	entries, err := pullCreatorVideos(ctx, creators, nil, int(config.GlobalConfig.Feed.InboxSize))
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	members := make([]goredis.Z, 0, len(entries))
+	for _, e := range entries {
+		members = append(members, goredis.Z{Score: float64(e.Score), Member: e.ID})
+	}
+	key := feedInboxKey(userID)
+	pipe := cache.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.ZAdd(ctx, key, members...)
+	pipe.ZRemRangeByRank(ctx, key, 0, -(config.GlobalConfig.Feed.InboxSize + 1))
+	pipe.Expire(ctx, key, feedInboxTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// backfillInboxAsync 新关注普通作者后，把其最近的视频补充到粉丝的收件箱
+func backfillInboxAsync(followerID, followeeID string) {
+	cache := redis.GetClient()
+	if cache == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), feedJobTimeout)
+		defer cancel()
+
+		stats, err := loadFollowStats(ctx, followeeID)
+		if err != nil || stats.Followers > config.GlobalConfig.Feed.PushThreshold {
+			return
+		}
+		entries, err := pullCreatorVideos(ctx, []string{followeeID}, nil, feedFollowBackfill)
+		if err == nil {
+			err = pushToInboxes(ctx, cache, []string{followerID}, entries)
+		}
+		if err != nil {
+			slog.Error("[backfillInbox] 补充收件箱失败", "error", err, "userId", followerID)
+		}
+	}()
+}
+
+// feedInboxKey 收件箱缓存键
+func feedInboxKey(userID string) string {
+	return fmt.Sprintf("feed:inbox:%s", userID)
+}
+
+// encodeFeedCursor 生成动态流游标
+func encodeFeedCursor(e feedEntry) string {
+	raw := fmt.Sprintf("%d:%s", e.Score, e.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor 解析动态流游标
+func decodeFeedCursor(cursor string) (feedEntry, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return feedEntry{}, errors.New("无效的游标")
+	}
+	scoreStr, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return feedEntry{}, errors.New("无效的游标")
+	}
+	score, err := strconv.ParseInt(scoreStr, 10, 64)
+	if err != nil {
+		return feedEntry{}, errors.New("无效的游标")
+	}
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return feedEntry{}, errors.New("无效的游标")
+	}
+	return feedEntry{ID: id, Score: score}, nil
+}
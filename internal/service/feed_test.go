@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 测试动态流游标的编码和解析
+func TestFeedCursor(t *testing.T) {
+	entry := feedEntry{ID: primitive.NewObjectID().Hex(), Score: 1700000000123}
+
+	decoded, err := decodeFeedCursor(encodeFeedCursor(entry))
+	assert.Nil(t, err)
+	assert.Equal(t, entry, decoded)
+
+	_, err = decodeFeedCursor("not-a-cursor!")
+	assert.NotNil(t, err)
+
+	_, err = decodeFeedCursor("MTIzOmFiYw") // "123:abc"
+	assert.NotNil(t, err)
+}
+
+// 测试收件箱与拉取结果的合并：去重、排序、跳过游标之前的记录
+func TestMergeFeedEntries(t *testing.T) {
+	entries := []feedEntry{
+		{ID: "a", Score: 100},
+		{ID: "c", Score: 300},
+		{ID: "b", Score: 200},
+		{ID: "c", Score: 300}, // 收件箱和拉取结果重复
+		{ID: "d", Score: 200}, // 与b同分，按ID倒序排在b之前
+	}
+
+	merged := mergeFeedEntries(entries, nil)
+	assert.Equal(t, []feedEntry{
+		{ID: "c", Score: 300},
+		{ID: "d", Score: 200},
+		{ID: "b", Score: 200},
+		{ID: "a", Score: 100},
+	}, merged)
+
+	// 从d之后继续
+	merged = mergeFeedEntries(entries, &feedEntry{ID: "d", Score: 200})
+	assert.Equal(t, []feedEntry{
+		{ID: "b", Score: 200},
+		{ID: "a", Score: 100},
+	}, merged)
+}
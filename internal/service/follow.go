@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/redis"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 关注计数缓存过期时间
+const followStatsCacheTTL = 10 * time.Minute
+
+// FollowService 关注服务接口
+type FollowService interface {
+	Follow(ctx context.Context, followerID, followeeID string) error
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	GetFollowers(ctx context.Context, userID string, page, size int) (*model.FollowListResponse, error)
+	GetFollowing(ctx context.Context, userID string, page, size int) (*model.FollowListResponse, error)
+	GetRelation(ctx context.Context, userID, targetID string) (*model.FollowRelation, error)
+	GetFollowStats(ctx context.Context, userID string) (*model.FollowStats, error)
+}
+
+type followService struct {
+	collection string
+}
+
+// NewFollowService 创建关注服务实例
+func NewFollowService() FollowService {
+	return &followService{
+		collection: "follows",
+	}
+}
+
+// Follow 关注用户
+func (s *followService) Follow(ctx context.Context, followerID, followeeID string) error {
+	if followerID == followeeID {
+		return errors.New("不能关注自己")
+	}
+	if err := checkInteraction(ctx, followerID, followeeID); err != nil {
+		return err
+	}
+
+	// 检查被关注用户是否存在
+	objectID, err := primitive.ObjectIDFromHex(followeeID)
+	if err != nil {
+		return fmt.Errorf("无效的ID格式: %w", err)
+	}
+	count, err := database.GetCollection("users").CountDocuments(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("用户不存在")
+	}
+
+	collection := database.GetCollection(s.collection)
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中执行添加关注和更新双方计数
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// 1. 添加关注关系，(follower_id, followee_id) 唯一索引保证并发关注只成功一次
+		follow := model.Follow{
+			ID:         primitive.NewObjectID(),
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+			CreatedAt:  time.Now(),
+		}
+		if _, err := collection.InsertOne(sessCtx, follow); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, errors.New("已经关注过该用户")
+			}
+			return nil, err
+		}
+
+		// 2. 更新双方的关注计数
+		if err := incFollowStats(sessCtx, followerID, "following", 1); err != nil {
+			return nil, err
+		}
+		return nil, incFollowStats(sessCtx, followeeID, "followers", 1)
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateFollowStats(ctx, followerID, followeeID)
+
+	// 把对方最近的视频补充到关注动态
+	backfillInboxAsync(followerID, followeeID)
+
+	// 通知被关注的用户
+	notifyAsync(model.Notification{
+		UserID:     followeeID,
+		Type:       model.NotificationTypeFollow,
+		TargetType: model.NotificationTargetUser,
+		TargetID:   followeeID,
+		ActorIDs:   []string{followerID},
+	})
+
+	return nil
+}
+
+// Unfollow 取消关注
+func (s *followService) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	collection := database.GetCollection(s.collection)
+
+	// 在事务中执行取消关注和更新双方计数
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// 1. 删除关注关系
+		result, err := collection.DeleteOne(sessCtx, bson.M{
+			"follower_id": followerID,
+			"followee_id": followeeID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result.DeletedCount == 0 {
+			return nil, errors.New("尚未关注该用户")
+		}
+
+		// 2. 更新双方的关注计数（减1，但确保不会小于0）
+		if err := incFollowStats(sessCtx, followerID, "following", -1); err != nil {
+			return nil, err
+		}
+		return nil, incFollowStats(sessCtx, followeeID, "followers", -1)
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateFollowStats(ctx, followerID, followeeID)
+	return nil
+}
+
+// GetFollowers 获取粉丝列表
+func (s *followService) GetFollowers(ctx context.Context, userID string, page, size int) (*model.FollowListResponse, error) {
+	return s.listFollows(ctx, userID, "followee_id", "follower_id", page, size)
+}
+
+// GetFollowing 获取关注列表
+func (s *followService) GetFollowing(ctx context.Context, userID string, page, size int) (*model.FollowListResponse, error) {
+	return s.listFollows(ctx, userID, "follower_id", "followee_id", page, size)
+}
+
+// listFollows 按ownerField过滤关注关系，并以otherField对应的用户构建列表
+func (s *followService) listFollows(ctx context.Context, userID, ownerField, otherField string, page, size int) (*model.FollowListResponse, error) {
+	collection := database.GetCollection(s.collection)
+
+	// 设置默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 12
+	}
+
+	// 查询条件
+	filter := bson.M{ownerField: userID}
+
+	// 获取总数
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取分页数据
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}). // 按关注时间倒序
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var follows []model.Follow
+	if err = cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+
+	otherIDs := make([]string, 0, len(follows))
+	for _, f := range follows {
+		if otherField == "follower_id" {
+			otherIDs = append(otherIDs, f.FollowerID)
+		} else {
+			otherIDs = append(otherIDs, f.FolloweeID)
+		}
+	}
+
+	// 批量获取用户信息
+	briefs, err := loadUserBriefs(ctx, otherIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询反向关系，判断是否互相关注
+	mutual := make(map[string]bool)
+	if len(otherIDs) > 0 {
+		cursor, err := collection.Find(ctx, bson.M{
+			ownerField: bson.M{"$in": otherIDs},
+			otherField: userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		var reverse []model.Follow
+		if err = cursor.All(ctx, &reverse); err != nil {
+			return nil, err
+		}
+		for _, f := range reverse {
+			if ownerField == "follower_id" {
+				mutual[f.FollowerID] = true
+			} else {
+				mutual[f.FolloweeID] = true
+			}
+		}
+	}
+
+	users := make([]model.FollowUser, 0, len(follows))
+	for i, f := range follows {
+		id := otherIDs[i]
+		users = append(users, model.FollowUser{
+			UserBrief:  briefs[id],
+			FollowedAt: f.CreatedAt,
+			IsMutual:   mutual[id],
+		})
+	}
+
+	return &model.FollowListResponse{
+		Users: users,
+		Total: total,
+		Page:  page,
+		Size:  size,
+	}, nil
+}
+
+// GetRelation 获取userID与targetID之间的关注关系
+func (s *followService) GetRelation(ctx context.Context, userID, targetID string) (*model.FollowRelation, error) {
+	relation := &model.FollowRelation{}
+	if userID == "" || targetID == "" || userID == targetID {
+		return relation, nil
+	}
+
+	cursor, err := database.GetCollection(s.collection).Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"follower_id": userID, "followee_id": targetID},
+			{"follower_id": targetID, "followee_id": userID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var follows []model.Follow
+	if err = cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+
+	for _, f := range follows {
+		if f.FollowerID == userID {
+			relation.IsFollowing = true
+		} else {
+			relation.IsFollowed = true
+		}
+	}
+	relation.IsMutual = relation.IsFollowing && relation.IsFollowed
+
+	return relation, nil
+}
+
+// GetFollowStats 获取用户的关注数和粉丝数
+func (s *followService) GetFollowStats(ctx context.Context, userID string) (*model.FollowStats, error) {
+	return loadFollowStats(ctx, userID)
+}
+
+// followStatsKey 关注计数缓存键
+func followStatsKey(userID string) string {
+	return fmt.Sprintf("follow:stats:%s", userID)
+}
+
+// incFollowStats 更新关注计数，减少时确保不会小于0
+func incFollowStats(ctx context.Context, userID, field string, delta int64) error {
+	collection := database.GetCollection("follow_stats")
+	if delta > 0 {
+		_, err := collection.UpdateOne(
+			ctx,
+			bson.M{"_id": userID},
+			bson.M{"$inc": bson.M{field: delta}},
+			options.Update().SetUpsert(true),
+		)
+		return err
+	}
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID, field: bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{field: delta}},
+	)
+	return err
+}
+
+// loadFollowStats 优先从Redis读取关注计数，未命中时查询MongoDB并回填缓存
+func loadFollowStats(ctx context.Context, userID string) (*model.FollowStats, error) {
+	stats := &model.FollowStats{UserID: userID}
+	key := followStatsKey(userID)
+
+	cache := redis.GetClient()
+	if cache != nil {
+		values, err := cache.HGetAll(ctx, key).Result()
+		if err == nil && len(values) > 0 {
+			stats.Following, _ = strconv.ParseInt(values["following"], 10, 64)
+			stats.Followers, _ = strconv.ParseInt(values["followers"], 10, 64)
+			return stats, nil
+		}
+	}
+
+	err := database.GetCollection("follow_stats").FindOne(ctx, bson.M{"_id": userID}).Decode(stats)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	if cache != nil {
+		pipe := cache.TxPipeline()
+		pipe.HSet(ctx, key, "following", stats.Following, "followers", stats.Followers)
+		pipe.Expire(ctx, key, followStatsCacheTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			slog.Error("[loadFollowStats] 写入关注计数缓存失败", "error", err, "userId", userID)
+		}
+	}
+
+	return stats, nil
+}
+
+// invalidateFollowStats 删除关注计数缓存
+func invalidateFollowStats(ctx context.Context, userIDs ...string) {
+	cache := redis.GetClient()
+	if cache == nil {
+		return
+	}
+
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, followStatsKey(id))
+	}
+	if err := cache.Del(ctx, keys...).Err(); err != nil {
+		slog.Error("[invalidateFollowStats] 删除关注计数缓存失败", "error", err, "keys", keys)
+	}
+}
@@ -0,0 +1,523 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// 导入相关错误
+var (
+	ErrImportVideoNotFound  = errors.New("视频不存在")
+	ErrImportVideoForbidden = errors.New("无权向该视频导入标记")
+	ErrImportEmpty          = errors.New("文件中没有可导入的标记或笔记")
+	ErrImportTooMany        = errors.New("一次最多导入2000条记录")
+)
+
+// importEntryLimit 一次最多导入的记录数
+const importEntryLimit = 2000
+
+// ImportService 标记和笔记导入服务接口
+type ImportService interface {
+	Import(ctx context.Context, userID, videoID, filename string, data []byte, req *model.ImportRequest) (*model.ImportResult, error)
+}
+
+type importService struct{}
+
+// NewImportService 创建导入服务实例
+func NewImportService() ImportService {
+	return &importService{}
+}
+
+// importEntry 解析出的一条待导入记录
+type importEntry struct {
+	Cue         int
+	Line        int
+	Kind        string // mark 或 note
+	Start       float64
+	End         float64
+	Category    string // 分类代码
+	Color       string
+	Content     string
+	Annotations []string
+}
+
+// key 合并时判断重复的依据
+func (e importEntry) key() string {
+	return fmt.Sprintf("%s|%.3f|%s", e.Kind, e.Start, e.Content)
+}
+
+// Import 从 WebVTT、SRT 或本站导出的 JSON 导入标记、注释和笔记。
+// 任意记录校验失败时不写入任何数据，只返回逐条错误
+func (s *importService) Import(ctx context.Context, userID, videoID, filename string, data []byte, req *model.ImportRequest) (*model.ImportResult, error) {
+	result := &model.ImportResult{
+		Format: req.Format,
+		Mode:   req.Mode,
+		DryRun: req.DryRun,
+		Errors: []model.ImportError{},
+	}
+	if result.Format == "" {
+		result.Format = detectImportFormat(filename, data)
+	}
+	if result.Mode == "" {
+		result.Mode = model.ImportModeMerge
+	}
+
+	duration, err := importVideoDuration(ctx, userID, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []importEntry
+	var errs []model.ImportError
+	if result.Format == model.ExportFormatJSON {
+		entries, errs = parseJSONImport(data)
+	} else {
+		entries, errs = parseSubtitleImport(string(data))
+	}
+	if len(entries) == 0 && len(errs) == 0 {
+		return nil, ErrImportEmpty
+	}
+	if len(entries) > importEntryLimit {
+		return nil, ErrImportTooMany
+	}
+	result.Errors = append(errs, validateImportEntries(entries, duration)...)
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if result.Mode == model.ImportModeMerge {
+		existing, err := existingImportKeys(ctx, userID, videoID)
+		if err != nil {
+			return nil, err
+		}
+		kept := entries[:0]
+		for _, e := range entries {
+			if existing[e.key()] {
+				result.Skipped++
+				continue
+			}
+			existing[e.key()] = true
+			kept = append(kept, e)
+		}
+		entries = kept
+	}
+
+	for _, e := range entries {
+		if e.Kind == "note" {
+			result.Notes++
+		} else {
+			result.Marks++
+			result.Annotations += len(e.Annotations)
+		}
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中清空旧数据并写入导入的记录，写入失败时不会丢失原有数据
+	var removed []model.NoteImage
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		removed = nil
+		if result.Mode == model.ImportModeReplace {
+			var err error
+			if removed, err = clearImportTarget(sessCtx, userID, videoID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, writeImportEntries(sessCtx, userID, videoID, entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 事务提交后再删除被替换笔记的图片文件
+	removeNoteImageFiles(removed)
+	result.Applied = true
+	return result, nil
+}
+
+// detectImportFormat 根据扩展名识别格式，无法识别时根据内容判断
+func detectImportFormat(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".vtt":
+		return model.ExportFormatVTT
+	case ".srt":
+		return model.ExportFormatSRT
+	case ".json":
+		return model.ExportFormatJSON
+	}
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("WEBVTT")):
+		return model.ExportFormatVTT
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return model.ExportFormatJSON
+	default:
+		return model.ExportFormatSRT
+	}
+}
+
+// importVideoDuration 检查视频是否可导入并返回视频时长：作者可以向自己的任意视频导入，
+// 其他用户只能向公开视频导入
+func importVideoDuration(ctx context.Context, userID, videoID string) (float64, error) {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return 0, ErrImportVideoNotFound
+	}
+	var video model.Video
+	err = database.GetCollection("videos").FindOne(ctx, bson.M{"_id": objectID}).Decode(&video)
+	if err == mongo.ErrNoDocuments {
+		return 0, ErrImportVideoNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if video.UserID != userID && video.Status != model.VideoStatusPublic {
+		return 0, ErrImportVideoForbidden
+	}
+	return video.Duration, nil
+}
+
+var (
+	// cueTimingPattern 字幕时间行，小时部分可省略，毫秒分隔符兼容 . 和 ,
+	cueTimingPattern = regexp.MustCompile(`^((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s+-->\s+((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})`)
+	// cueLabelPattern 本站导出的记录标题，如 "[标记·重点] 内容"
+	cueLabelPattern = regexp.MustCompile(`^\[(标记|笔记)(?:·([^\]]+))?\]\s*`)
+	// cueTagPattern WebVTT 文本中的样式标签，如 <b>、<v 讲师>
+	cueTagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// parseCueTime 解析字幕时间 [hh:]mm:ss.mmm
+func parseCueTime(value string) (float64, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	parts := strings.Split(value, ":")
+	var seconds float64
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的时间 %s", value)
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds, nil
+}
+
+// parseSubtitleImport 解析 WebVTT 或 SRT。本站导出的字幕会还原记录类型、分类和注释，
+// 其他工具生成的字幕每条作为一个标记导入，显示时长不是默认的5秒时作为区间标记
+func parseSubtitleImport(text string) ([]importEntry, []model.ImportError) {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimPrefix(text, "\ufeff"), "\r\n", "\n"), "\n")
+
+	var entries []importEntry
+	var errs []model.ImportError
+	for i := 0; i < len(lines); {
+		// 跳过空行，取出下一个字幕块
+		if strings.TrimSpace(lines[i]) == "" {
+			i++
+			continue
+		}
+		start := i
+		for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+			i++
+		}
+		block := lines[start:i]
+
+		// WebVTT 文件头和注释、样式、区域块
+		first := strings.TrimSpace(block[0])
+		if strings.HasPrefix(first, "WEBVTT") || strings.HasPrefix(first, "NOTE") ||
+			first == "STYLE" || first == "REGION" {
+			continue
+		}
+
+		// 时间行之前可以有一行序号或标识
+		timing := 0
+		if !strings.Contains(block[0], "-->") && len(block) > 1 {
+			timing = 1
+		}
+		cue := len(entries) + len(errs) + 1
+		lineNo := start + timing + 1
+
+		match := cueTimingPattern.FindStringSubmatch(strings.TrimSpace(block[timing]))
+		if match == nil {
+			errs = append(errs, model.ImportError{Cue: cue, Line: lineNo, Message: "缺少或无效的时间行"})
+			continue
+		}
+		entry := importEntry{Cue: cue, Line: lineNo, Kind: "mark"}
+		var err error
+		if entry.Start, err = parseCueTime(match[1]); err == nil {
+			entry.End, err = parseCueTime(match[2])
+		}
+		if err != nil {
+			errs = append(errs, model.ImportError{Cue: cue, Line: lineNo, Message: err.Error()})
+			continue
+		}
+		if entry.End < entry.Start {
+			errs = append(errs, model.ImportError{Cue: cue, Line: lineNo, Message: "结束时间早于开始时间"})
+			continue
+		}
+
+		var content []string
+		labelled := false
+		for _, line := range block[timing+1:] {
+			line = strings.TrimSpace(cueTagPattern.ReplaceAllString(line, ""))
+			if m := cueLabelPattern.FindStringSubmatch(line); m != nil && len(content) == 0 {
+				labelled = true
+				if m[1] == "笔记" {
+					entry.Kind = "note"
+				}
+				entry.Category = markCategoryCode(m[2])
+				line = line[len(m[0]):]
+			} else if labelled && entry.Kind == "mark" && strings.HasPrefix(line, "- ") {
+				entry.Annotations = append(entry.Annotations, strings.TrimPrefix(line, "- "))
+				continue
+			}
+			content = append(content, line)
+		}
+		entry.Content = strings.Join(content, "\n")
+
+		// 笔记和默认显示时长的记录还原为时间点
+		if entry.Kind == "note" || entry.End <= entry.Start || math.Abs(entry.End-entry.Start-exportCueDuration) < 0.001 {
+			entry.End = 0
+		}
+		entries = append(entries, entry)
+	}
+	return entries, errs
+}
+
+// markCategoryCode 根据分类名称查找分类代码，未知分类返回空
+func markCategoryCode(label string) string {
+	for _, c := range model.MarkCategories {
+		if c.Label == label {
+			return c.Code
+		}
+	}
+	return ""
+}
+
+// parseJSONImport 解析本站导出的 JSON，标记在前、笔记在后依次编号
+func parseJSONImport(data []byte) ([]importEntry, []model.ImportError) {
+	var video model.ExportVideo
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\ufeff")), &video); err != nil {
+		return nil, []model.ImportError{{Cue: 0, Message: "无效的JSON: " + err.Error()}}
+	}
+
+	entries := make([]importEntry, 0, len(video.Marks)+len(video.Notes))
+	for _, m := range video.Marks {
+		entry := importEntry{
+			Cue:      len(entries) + 1,
+			Kind:     "mark",
+			Start:    m.Timestamp,
+			End:      m.EndTime,
+			Category: m.Category,
+			Color:    m.Color,
+			Content:  m.Content,
+		}
+		for _, a := range m.Annotations {
+			entry.Annotations = append(entry.Annotations, a.Content)
+		}
+		entries = append(entries, entry)
+	}
+	for _, n := range video.Notes {
+		entries = append(entries, importEntry{Cue: len(entries) + 1, Kind: "note", Start: n.Timestamp, Content: n.Content})
+	}
+	return entries, nil
+}
+
+// validateImportEntries 校验内容和时间范围，duration 为0（未知时长）时不检查是否超出视频时长
+func validateImportEntries(entries []importEntry, duration float64) []model.ImportError {
+	var errs []model.ImportError
+	for i := range entries {
+		e := &entries[i]
+		fail := func(message string) {
+			errs = append(errs, model.ImportError{Cue: e.Cue, Line: e.Line, Message: message})
+		}
+
+		e.Content = strings.TrimSpace(e.Content)
+		if e.Content == "" {
+			fail("内容不能为空")
+			continue
+		}
+		if duration > 0 && (e.Start > duration || e.End > duration) {
+			fail(fmt.Sprintf("时间超出视频时长 %s", formatClock(duration)))
+			continue
+		}
+		if e.Kind == "note" {
+			if e.Start < 0 {
+				fail("时间不能为负数")
+			} else if err := checkNoteContent(e.Content); err != nil {
+				fail(err.Error())
+			}
+			continue
+		}
+
+		mark := model.Mark{Timestamp: e.Start, EndTime: e.End, Category: e.Category, Color: e.Color}
+		if err := normalizeMark(&mark); err != nil {
+			fail(err.Error())
+			continue
+		}
+		e.Color = mark.Color
+	}
+	return errs
+}
+
+// existingImportKeys 查询用户在该视频上已有的标记和自己的笔记，用于合并时去重
+func existingImportKeys(ctx context.Context, userID, videoID string) (map[string]bool, error) {
+	filter := bson.M{"user_id": userID, "video_id": videoID}
+	keys := make(map[string]bool)
+
+	var marks []model.Mark
+	cursor, err := database.GetCollection("marks").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &marks); err != nil {
+		return nil, err
+	}
+	for _, m := range marks {
+		keys[importEntry{Kind: "mark", Start: m.Timestamp, Content: m.Content}.key()] = true
+	}
+
+	var notes []model.Note
+	cursor, err = database.GetCollection("notes").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		keys[importEntry{Kind: "note", Start: n.Timestamp, Content: n.Content}.key()] = true
+	}
+	return keys, nil
+}
+
+// clearImportTarget 替换导入前删除用户在该视频上的标记（及其注释）和自己的笔记（及其历史版本和图片记录），
+// 返回被删除的图片，图片文件由调用方在事务提交后删除
+func clearImportTarget(ctx context.Context, userID, videoID string) ([]model.NoteImage, error) {
+	filter := bson.M{"user_id": userID, "video_id": videoID}
+	marks := database.GetCollection("marks")
+
+	var ids []primitive.ObjectID
+	cursor, err := marks.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var existing []model.Mark
+	if err := cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+	for _, m := range existing {
+		ids = append(ids, m.ID)
+	}
+
+	if len(ids) > 0 {
+		if _, err := database.GetCollection("annotations").DeleteMany(ctx, bson.M{"mark_id": bson.M{"$in": ids}}); err != nil {
+			return nil, err
+		}
+		if _, err := marks.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := database.GetCollection("note_revisions").DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+	noteIDs, err := database.GetCollection("notes").Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+	var images []model.NoteImage
+	if len(noteIDs) > 0 {
+		if images, err = deleteNoteImageRecords(ctx, bson.M{"note_id": bson.M{"$in": noteIDs}}); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := database.GetCollection("notes").DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// writeImportEntries 批量写入标记、注释和笔记，导入的笔记均为仅自己可见
+func writeImportEntries(ctx context.Context, userID, videoID string, entries []importEntry) error {
+	now := time.Now()
+	var marks, annotations, notes []interface{}
+	for _, e := range entries {
+		if e.Kind == "note" {
+			notes = append(notes, model.Note{
+				ID:         primitive.NewObjectID(),
+				UserID:     userID,
+				VideoID:    videoID,
+				Timestamp:  e.Start,
+				Content:    e.Content,
+				Visibility: model.NoteVisibilityPrivate,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			})
+			continue
+		}
+
+		mark := model.Mark{
+			ID:        primitive.NewObjectID(),
+			UserID:    userID,
+			VideoID:   videoID,
+			Timestamp: e.Start,
+			EndTime:   e.End,
+			Category:  e.Category,
+			Color:     e.Color,
+			Content:   e.Content,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		marks = append(marks, mark)
+		for _, content := range e.Annotations {
+			annotations = append(annotations, model.Annotation{
+				ID:        primitive.NewObjectID(),
+				UserID:    userID,
+				MarkID:    mark.ID,
+				VideoID:   videoID,
+				Content:   content,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+	}
+
+	batches := []struct {
+		collection string
+		docs       []interface{}
+	}{
+		{"marks", marks},
+		{"annotations", annotations},
+		{"notes", notes},
+	}
+	for _, batch := range batches {
+		if len(batch.docs) == 0 {
+			continue
+		}
+		if _, err := database.GetCollection(batch.collection).InsertMany(ctx, batch.docs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试导入格式识别
+func TestDetectImportFormat(t *testing.T) {
+	assert.Equal(t, model.ExportFormatSRT, detectImportFormat("a.SRT", nil))
+	assert.Equal(t, model.ExportFormatVTT, detectImportFormat("upload", []byte("\ufeffWEBVTT\n\n")))
+	assert.Equal(t, model.ExportFormatJSON, detectImportFormat("", []byte(` {"marks":[]}`)))
+	assert.Equal(t, model.ExportFormatSRT, detectImportFormat("", []byte("1\n00:00:01,000 --> 00:00:02,000\nhi")))
+}
+
+// 测试导入本站导出的字幕，还原记录类型、分类、区间和注释
+func TestParseSubtitleImportRoundTrip(t *testing.T) {
+	entries, errs := parseSubtitleImport(string(renderSubtitles(exportTestVideo(), true)))
+	assert.Empty(t, errs)
+	assert.Len(t, entries, 3)
+
+	assert.Equal(t, "mark", entries[0].Kind)
+	assert.Equal(t, 10.0, entries[0].Start)
+	assert.Equal(t, 70.0, entries[0].End)
+
+	assert.Equal(t, "note", entries[1].Kind)
+	assert.Equal(t, 0.0, entries[1].End)
+	assert.Equal(t, "第一段\n第二段", entries[1].Content)
+
+	assert.Equal(t, model.MarkCategoryKeyPoint, entries[2].Category)
+	assert.Equal(t, 0.0, entries[2].End)
+	assert.Equal(t, "特征值", entries[2].Content)
+	assert.Equal(t, []string{"课后复习"}, entries[2].Annotations)
+}
+
+// 测试导入其他工具生成的SRT，并报告逐条错误
+func TestParseSubtitleImportErrors(t *testing.T) {
+	srt := "1\r\n00:00:01,500 --> 00:00:04,000\r\n<b>开场</b>\r\n- 不是注释\r\n\r\n" +
+		"2\r\n00:00:05 --> 00:00:06\r\n格式错误\r\n\r\n" +
+		"3\r\n00:01:00,000 --> 00:00:30,000\r\n倒序\r\n"
+	entries, errs := parseSubtitleImport(srt)
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1.5, entries[0].Start)
+	assert.Equal(t, 4.0, entries[0].End)
+	assert.Equal(t, "开场\n- 不是注释", entries[0].Content)
+	assert.Empty(t, entries[0].Annotations)
+
+	assert.Equal(t, []model.ImportError{
+		{Cue: 2, Line: 7, Message: "缺少或无效的时间行"},
+		{Cue: 3, Line: 11, Message: "结束时间早于开始时间"},
+	}, errs)
+}
+
+// 测试导入本站导出的JSON
+func TestParseJSONImport(t *testing.T) {
+	data, _ := json.Marshal(exportTestVideo())
+	entries, errs := parseJSONImport(data)
+	assert.Empty(t, errs)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "note", entries[2].Kind)
+	assert.Equal(t, 3, entries[2].Cue)
+
+	_, errs = parseJSONImport([]byte("{"))
+	assert.Len(t, errs, 1)
+}
+
+// 测试导入记录校验：内容、视频时长和标记规则
+func TestValidateImportEntries(t *testing.T) {
+	entries := []importEntry{
+		{Cue: 1, Kind: "mark", Start: 10, Category: model.MarkCategoryQuestion, Content: " 疑问 "},
+		{Cue: 2, Kind: "note", Start: 20, Content: "  "},
+		{Cue: 3, Kind: "mark", Start: 50, End: 130, Content: "超出"},
+		{Cue: 4, Kind: "mark", Start: 50, Category: "unknown", Content: "未知分类"},
+	}
+	errs := validateImportEntries(entries, 120)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, []int{2, 3, 4}, []int{errs[0].Cue, errs[1].Cue, errs[2].Cue})
+	assert.Equal(t, "时间超出视频时长 00:02:00", errs[1].Message)
+
+	assert.Equal(t, "疑问", entries[0].Content)
+	assert.Equal(t, "#4A90E2", entries[0].Color)
+
+	// 时长未知时不检查是否超出
+	assert.Empty(t, validateImportEntries(entries[2:3], 0))
+
+	// 导入的笔记同样限制长度
+	errs = validateImportEntries([]importEntry{{Cue: 5, Kind: "note", Start: 1, Content: strings.Repeat("长", noteContentLimit+1)}}, 0)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, ErrNoteTooLong.Error(), errs[0].Message)
+}
@@ -2,30 +2,61 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"time"
 	"video-platform/internal/model"
 	"video-platform/pkg/database"
+	"video-platform/pkg/markdown"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// 标记、注释和笔记相关错误
+var (
+	ErrMarkNotFound          = errors.New("标记不存在")
+	ErrMarkForbidden         = errors.New("无权访问该标记")
+	ErrInvalidMark           = errors.New("无效的标记")
+	ErrInvalidNoteVisibility = errors.New("无效的笔记可见范围")
+	ErrNoteVideoForbidden    = errors.New("无权查看该视频的笔记")
+	ErrNoteNotFound          = errors.New("笔记不存在")
+	ErrNoteRevisionNotFound  = errors.New("笔记的历史版本不存在")
+	ErrNoteConflict          = errors.New("笔记已被修改，请刷新后重试")
+	ErrNoteVersionRequired   = errors.New("修改房间内的笔记需要提供版本号")
+	ErrNoteForbidden         = errors.New("只有作者可以修改笔记的可见范围")
+)
+
+// 每条笔记最多分享的用户数
+const noteShareLimit = 50
+
 // MarkService 标记服务接口
 type MarkService interface {
 	AddMark(ctx context.Context, userID string, mark *model.Mark) error
-	GetMarks(ctx context.Context, userID, videoID string) ([]model.Mark, error)
+	GetMarks(ctx context.Context, userID string, query *model.MarkQuery) ([]model.Mark, error)
 	UpdateMark(ctx context.Context, userID string, markID primitive.ObjectID, mark *model.Mark) error
 	DeleteMark(ctx context.Context, userID string, markID primitive.ObjectID) error
 
 	AddAnnotation(ctx context.Context, annotation *model.Annotation) error
-	GetAnnotations(ctx context.Context, markID primitive.ObjectID) ([]model.Annotation, error)
+	GetAnnotations(ctx context.Context, userID string, markID primitive.ObjectID) ([]model.Annotation, error)
 	UpdateAnnotation(ctx context.Context, userID string, annotationID primitive.ObjectID, annotation *model.Annotation) error
 	DeleteAnnotation(ctx context.Context, userID string, annotationID primitive.ObjectID) error
 
 	AddNote(ctx context.Context, note *model.Note) error
-	GetNotes(ctx context.Context, videoID string) ([]model.Note, error)
+	GetNotes(ctx context.Context, userID, videoID string) ([]model.Note, error)
+	GetCommunityNotes(ctx context.Context, viewerID, videoID string, page, size int) (*model.CommunityNoteResponse, error)
 	UpdateNote(ctx context.Context, userID string, noteID primitive.ObjectID, note *model.Note) error
 	DeleteNote(ctx context.Context, userID string, noteID primitive.ObjectID) error
+
+	ListNoteRevisions(ctx context.Context, userID string, noteID primitive.ObjectID) ([]model.NoteRevision, error)
+	GetNoteRevision(ctx context.Context, userID string, noteID primitive.ObjectID, version int) (*model.NoteRevision, error)
+	RestoreNoteRevision(ctx context.Context, userID string, noteID primitive.ObjectID, version int) (*model.Note, error)
+
+	UploadNoteImage(ctx context.Context, userID string, file *multipart.FileHeader) (*model.NoteImage, error)
 }
 
 // markServiceImpl 标记服务实现
@@ -40,21 +71,34 @@ func NewMarkService() MarkService {
 	}
 }
 
-// AddMark 添加标记
+// AddMark 添加标记，指定房间时需要是房间成员，添加后推送给房间内的成员
 func (s *markServiceImpl) AddMark(ctx context.Context, userID string, mark *model.Mark) error {
+	if err := normalizeMark(mark); err != nil {
+		return err
+	}
+	if mark.RoomID != "" {
+		if err := checkRoomTarget(ctx, userID, mark.RoomID, mark.VideoID); err != nil {
+			return err
+		}
+	}
 	mark.ID = primitive.NewObjectID()
 	mark.UserID = userID // 设置用户ID
 	collection := database.GetCollection(s.collection)
 	mark.CreatedAt = time.Now()
 	mark.UpdatedAt = time.Now()
-	_, err := collection.InsertOne(ctx, mark)
-	return err
+	if _, err := collection.InsertOne(ctx, mark); err != nil {
+		return err
+	}
+	publishRoomEvent(ctx, mark.RoomID, "mark.created", mark)
+	return nil
 }
 
-// GetMarks 获取标记列表
-func (s *markServiceImpl) GetMarks(ctx context.Context, userID string, videoID string) ([]model.Mark, error) {
+// GetMarks 获取标记列表，可按分类和时间窗口过滤，按开始时间排序
+func (s *markServiceImpl) GetMarks(ctx context.Context, userID string, query *model.MarkQuery) ([]model.Mark, error) {
 	collection := database.GetCollection(s.collection)
-	cursor, err := collection.Find(ctx, gin.H{"user_id": userID, "video_id": videoID})
+	cursor, err := collection.Find(ctx, markFilter(userID, query),
+		options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +109,7 @@ func (s *markServiceImpl) GetMarks(ctx context.Context, userID string, videoID s
 
 	// 获取每个标记对应的注释
 	for i := range marks {
-		annotations, err := s.GetAnnotations(ctx, marks[i].ID)
+		annotations, err := s.findAnnotations(ctx, marks[i].ID)
 		if err != nil {
 			// 如果获取注释失败，不影响标记的返回，只是该标记的注释为空
 			marks[i].Annotations = []model.Annotation{}
@@ -77,18 +121,145 @@ func (s *markServiceImpl) GetMarks(ctx context.Context, userID string, videoID s
 	return marks, nil
 }
 
-// AddAnnotation 添加注释
+// markFilter 构建标记查询条件。区间标记与时间窗口有重叠即返回，
+// 时间点标记的结束时间视为开始时间
+func markFilter(userID string, query *model.MarkQuery) bson.M {
+	filter := bson.M{"user_id": userID, "video_id": query.VideoID}
+	if query.Category != "" {
+		filter["category"] = query.Category
+	}
+	if query.End > 0 {
+		filter["timestamp"] = bson.M{"$lte": query.End}
+	}
+	if query.Start > 0 {
+		filter["$or"] = []bson.M{
+			{"timestamp": bson.M{"$gte": query.Start}},
+			{"end_time": bson.M{"$gte": query.Start}},
+		}
+	}
+	return filter
+}
+
+// normalizeMark 校验标记的时间范围、分类和颜色，未指定颜色时使用分类的默认颜色
+func normalizeMark(mark *model.Mark) error {
+	if mark.Timestamp < 0 || mark.EndTime < 0 {
+		return fmt.Errorf("%w: 时间不能为负数", ErrInvalidMark)
+	}
+	if mark.EndTime != 0 && mark.EndTime <= mark.Timestamp {
+		return fmt.Errorf("%w: 结束时间必须大于开始时间", ErrInvalidMark)
+	}
+	if mark.Color != "" && !isHexColor(mark.Color) {
+		return fmt.Errorf("%w: 颜色格式应为#RRGGBB", ErrInvalidMark)
+	}
+	if mark.Category == "" {
+		return nil
+	}
+	category, ok := model.FindMarkCategory(mark.Category)
+	if !ok {
+		return fmt.Errorf("%w: 未知的分类 %s", ErrInvalidMark, mark.Category)
+	}
+	if mark.Color == "" {
+		mark.Color = category.Color
+	}
+	return nil
+}
+
+// isHexColor 判断是否为#RRGGBB格式的颜色
+func isHexColor(color string) bool {
+	if len(color) != 7 || color[0] != '#' {
+		return false
+	}
+	for _, c := range color[1:] {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// AddAnnotation 添加注释，标记必须存在且当前用户可以访问
 func (s *markServiceImpl) AddAnnotation(ctx context.Context, annotation *model.Annotation) error {
+	mark, err := s.accessibleMark(ctx, annotation.UserID, annotation.MarkID)
+	if err != nil {
+		return err
+	}
+	if err := checkInteraction(ctx, annotation.UserID, mark.UserID); err != nil {
+		return err
+	}
+
 	annotation.ID = primitive.NewObjectID()
+	annotation.VideoID = mark.VideoID
 	collection := database.GetCollection("annotations")
 	annotation.CreatedAt = time.Now()
 	annotation.UpdatedAt = time.Now()
-	_, err := collection.InsertOne(ctx, annotation)
-	return err
+	if _, err := collection.InsertOne(ctx, annotation); err != nil {
+		return err
+	}
+
+	// 通知标记的作者
+	notifyAsync(model.Notification{
+		UserID:     mark.UserID,
+		Type:       model.NotificationTypeAnnotation,
+		TargetType: model.NotificationTargetMark,
+		TargetID:   mark.ID.Hex(),
+		ActorIDs:   []string{annotation.UserID},
+		Content:    annotation.Content,
+	})
+	return nil
+}
+
+// GetAnnotations 获取标记的注释，标记必须存在且当前用户可以访问
+func (s *markServiceImpl) GetAnnotations(ctx context.Context, userID string, markID primitive.ObjectID) ([]model.Annotation, error) {
+	if _, err := s.accessibleMark(ctx, userID, markID); err != nil {
+		return nil, err
+	}
+	return s.findAnnotations(ctx, markID)
+}
+
+// accessibleMark 查找标记并检查访问权限：作者可以访问自己的标记，
+// 其他用户只能访问公开视频上未被隐藏的标记
+func (s *markServiceImpl) accessibleMark(ctx context.Context, userID string, markID primitive.ObjectID) (*model.Mark, error) {
+	var mark model.Mark
+	err := database.GetCollection(s.collection).FindOne(ctx, gin.H{"_id": markID}).Decode(&mark)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrMarkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mark.UserID == userID {
+		return &mark, nil
+	}
+	if mark.Hidden {
+		return nil, ErrMarkForbidden
+	}
+	if mark.RoomID != "" {
+		// 房间成员可以访问房间内的标记
+		member, err := isRoomMember(ctx, userID, mark.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		if member {
+			return &mark, nil
+		}
+	}
+
+	videoID, err := primitive.ObjectIDFromHex(mark.VideoID)
+	if err != nil {
+		return nil, ErrMarkForbidden
+	}
+	count, err := database.GetCollection("videos").CountDocuments(ctx, gin.H{"_id": videoID, "status": model.VideoStatusPublic})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrMarkForbidden
+	}
+	return &mark, nil
 }
 
-// GetAnnotations 获取注释
-func (s *markServiceImpl) GetAnnotations(ctx context.Context, markID primitive.ObjectID) ([]model.Annotation, error) {
+// findAnnotations 查询标记下的注释
+func (s *markServiceImpl) findAnnotations(ctx context.Context, markID primitive.ObjectID) ([]model.Annotation, error) {
 	collection := database.GetCollection("annotations")
 	cursor, err := collection.Find(ctx, gin.H{"mark_id": markID})
 	if err != nil {
@@ -101,20 +272,50 @@ func (s *markServiceImpl) GetAnnotations(ctx context.Context, markID primitive.O
 	return annotations, nil
 }
 
-// AddNote 添加笔记
+// AddNote 添加笔记，未指定可见范围时仅自己可见
 func (s *markServiceImpl) AddNote(ctx context.Context, note *model.Note) error {
+	if err := checkNoteContent(note.Content); err != nil {
+		return err
+	}
+	if note.Visibility == "" {
+		note.Visibility = model.NoteVisibilityPrivate
+	}
+	if err := normalizeNoteVisibility(ctx, note); err != nil {
+		return err
+	}
+	if note.RoomID != "" {
+		if err := checkRoomTarget(ctx, note.UserID, note.RoomID, note.VideoID); err != nil {
+			return err
+		}
+	}
+
 	note.ID = primitive.NewObjectID()
+	note.Version = 1
 	collection := database.GetCollection("notes")
 	note.CreatedAt = time.Now()
 	note.UpdatedAt = time.Now()
-	_, err := collection.InsertOne(ctx, note)
-	return err
+	if _, err := collection.InsertOne(ctx, note); err != nil {
+		return err
+	}
+	attachNoteImages(ctx, note.UserID, note)
+	note.ContentHTML = markdown.Render(note.Content)
+	if err := insertNoteRevisions(ctx, noteRevision(note, model.NoteRevisionCreate, 0)); err != nil {
+		return err
+	}
+	publishRoomEvent(ctx, note.RoomID, "note.created", note)
+	return nil
 }
 
-// GetNotes 获取笔记列表
-func (s *markServiceImpl) GetNotes(ctx context.Context, videoID string) ([]model.Note, error) {
+// GetNotes 获取视频下自己的笔记和别人分享给自己的笔记，按时间戳排序
+func (s *markServiceImpl) GetNotes(ctx context.Context, userID, videoID string) ([]model.Note, error) {
 	collection := database.GetCollection("notes")
-	cursor, err := collection.Find(ctx, gin.H{"video_id": videoID})
+	cursor, err := collection.Find(ctx, gin.H{
+		"video_id": videoID,
+		"$or": []gin.H{
+			{"user_id": userID},
+			{"visibility": model.NoteVisibilityShared, "shared_with": userID},
+		},
+	}, options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "created_at", Value: 1}}))
 	if err != nil {
 		return nil, err
 	}
@@ -122,29 +323,208 @@ func (s *markServiceImpl) GetNotes(ctx context.Context, videoID string) ([]model
 	if err := cursor.All(ctx, &notes); err != nil {
 		return nil, err
 	}
+	for i := range notes {
+		if notes[i].Visibility == "" {
+			notes[i].Visibility = model.NoteVisibilityPrivate
+		}
+	}
+	renderNotes(notes)
 	return notes, nil
 }
 
+// GetCommunityNotes 获取视频下公开的社区笔记，不包含当前用户拉黑或静音的作者
+func (s *markServiceImpl) GetCommunityNotes(ctx context.Context, viewerID, videoID string, page, size int) (*model.CommunityNoteResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, errors.New("无效的视频ID")
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, gin.H{"_id": objectID}).Decode(&video); err != nil {
+		return nil, errors.New("视频不存在")
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != viewerID {
+		return nil, ErrNoteVideoForbidden
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	filter := gin.H{"video_id": videoID, "visibility": model.NoteVisibilityPublic}
+	hidden, err := hiddenUserIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(hidden) > 0 {
+		filter["user_id"] = gin.H{"$nin": hidden}
+	}
+
+	collection := database.GetCollection("notes")
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "created_at", Value: 1}}).
+		SetSkip(int64((page-1)*size)).
+		SetLimit(int64(size)))
+	if err != nil {
+		return nil, err
+	}
+	var notes []model.Note
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+
+	renderNotes(notes)
+	authorIDs := make([]string, 0, len(notes))
+	for _, n := range notes {
+		authorIDs = append(authorIDs, n.UserID)
+	}
+	authors, err := loadUserBriefs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.NoteItem, 0, len(notes))
+	for _, n := range notes {
+		items = append(items, model.NoteItem{Note: n, Author: authors[n.UserID]})
+	}
+	return &model.CommunityNoteResponse{
+		Notes: items,
+		Total: total,
+		Page:  page,
+		Size:  size,
+	}, nil
+}
+
+// normalizeNoteVisibility 校验笔记的可见范围，分享时去重并检查分享的用户是否存在
+func normalizeNoteVisibility(ctx context.Context, note *model.Note) error {
+	if !model.IsValidNoteVisibility(note.Visibility) {
+		return ErrInvalidNoteVisibility
+	}
+	if note.Visibility != model.NoteVisibilityShared {
+		note.SharedWith = nil
+		return nil
+	}
+
+	note.SharedWith = uniqueShareTargets(note.SharedWith, note.UserID)
+	if len(note.SharedWith) == 0 {
+		return fmt.Errorf("%w: 请选择分享的用户", ErrInvalidNoteVisibility)
+	}
+	if len(note.SharedWith) > noteShareLimit {
+		return fmt.Errorf("%w: 最多分享给%d个用户", ErrInvalidNoteVisibility, noteShareLimit)
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(note.SharedWith))
+	for _, id := range note.SharedWith {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return fmt.Errorf("%w: 无效的用户ID", ErrInvalidNoteVisibility)
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	count, err := database.GetCollection("users").CountDocuments(ctx, gin.H{"_id": gin.H{"$in": objectIDs}})
+	if err != nil {
+		return err
+	}
+	if count != int64(len(objectIDs)) {
+		return fmt.Errorf("%w: 分享的用户不存在", ErrInvalidNoteVisibility)
+	}
+	return nil
+}
+
+// uniqueShareTargets 去掉空值、重复的用户和笔记作者本人，保持原有顺序
+func uniqueShareTargets(userIDs []string, ownerID string) []string {
+	seen := map[string]bool{ownerID: true, "": true}
+	result := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
 // UpdateMark 更新标记
 func (s *markServiceImpl) UpdateMark(ctx context.Context, userID string, markID primitive.ObjectID, mark *model.Mark) error {
+	if err := normalizeMark(mark); err != nil {
+		return err
+	}
 	collection := database.GetCollection(s.collection)
 	mark.UpdatedAt = time.Now()
-	_, err := collection.UpdateOne(ctx, gin.H{"_id": markID, "user_id": userID}, gin.H{"$set": mark})
-	return err
+
+	// 只更新内容、时间范围和分类，不修改标记所属的用户和视频
+	set := gin.H{
+		"content":    mark.Content,
+		"timestamp":  mark.Timestamp,
+		"updated_at": mark.UpdatedAt,
+	}
+	unset := gin.H{}
+	if mark.EndTime > 0 {
+		set["end_time"] = mark.EndTime
+	} else {
+		unset["end_time"] = ""
+	}
+	if mark.Category != "" {
+		set["category"] = mark.Category
+	} else {
+		unset["category"] = ""
+	}
+	if mark.Color != "" {
+		set["color"] = mark.Color
+	} else {
+		unset["color"] = ""
+	}
+	update := gin.H{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	var updated model.Mark
+	err := collection.FindOneAndUpdate(ctx, gin.H{"_id": markID, "user_id": userID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	publishRoomEvent(ctx, updated.RoomID, "mark.updated", updated)
+	return nil
 }
 
-// DeleteMark 删除标记
+// DeleteMark 删除标记及其注释
 func (s *markServiceImpl) DeleteMark(ctx context.Context, userID string, markID primitive.ObjectID) error {
 	collection := database.GetCollection(s.collection)
-	_, err := collection.DeleteOne(ctx, gin.H{"_id": markID, "user_id": userID})
-	return err
+	var deleted model.Mark
+	err := collection.FindOneAndDelete(ctx, gin.H{"_id": markID, "user_id": userID}).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetCollection("annotations").DeleteMany(ctx, gin.H{"mark_id": markID}); err != nil {
+		return err
+	}
+	publishRoomEvent(ctx, deleted.RoomID, "mark.deleted", gin.H{"id": markID.Hex()})
+	return nil
 }
 
-// UpdateAnnotation 更新注释
+// UpdateAnnotation 更新注释内容，不修改注释所属的标记和作者
 func (s *markServiceImpl) UpdateAnnotation(ctx context.Context, userID string, annotationID primitive.ObjectID, annotation *model.Annotation) error {
 	collection := database.GetCollection("annotations")
 	annotation.UpdatedAt = time.Now()
-	_, err := collection.UpdateOne(ctx, gin.H{"_id": annotationID, "user_id": userID}, gin.H{"$set": annotation})
+	_, err := collection.UpdateOne(ctx, gin.H{"_id": annotationID, "user_id": userID}, gin.H{"$set": gin.H{
+		"content":    annotation.Content,
+		"updated_at": annotation.UpdatedAt,
+	}})
 	return err
 }
 
@@ -155,17 +535,145 @@ func (s *markServiceImpl) DeleteAnnotation(ctx context.Context, userID string, a
 	return err
 }
 
-// UpdateNote 更新笔记
+// UpdateNote 更新笔记内容和时间戳，指定可见范围时同时修改可见范围，并记录历史版本。
+// 房间内的笔记可以由房间成员修改，但只有作者可以修改可见范围；
+// note.Version 为编辑时的版本号，与当前版本不一致说明期间有其他人修改过，返回 ErrNoteConflict；
+// 房间内的笔记多人共同编辑，必须提供版本号，避免后提交的修改覆盖别人的修改。
+// 更新成功后 note 为更新后的完整笔记
 func (s *markServiceImpl) UpdateNote(ctx context.Context, userID string, noteID primitive.ObjectID, note *model.Note) error {
-	collection := database.GetCollection("notes")
+	if err := checkNoteContent(note.Content); err != nil {
+		return err
+	}
+	current, err := findEditableNote(ctx, userID, noteID)
+	if err != nil {
+		return err
+	}
+	if current.RoomID != "" && note.Version == 0 {
+		return ErrNoteVersionRequired
+	}
+	if note.Version > 0 && note.Version != current.Version {
+		return ErrNoteConflict
+	}
+	if note.Visibility != "" && current.UserID != userID {
+		return ErrNoteForbidden
+	}
+
 	note.UpdatedAt = time.Now()
-	_, err := collection.UpdateOne(ctx, gin.H{"_id": noteID, "user_id": userID}, gin.H{"$set": note})
-	return err
+	set := gin.H{
+		"content":    note.Content,
+		"timestamp":  note.Timestamp,
+		"updated_at": note.UpdatedAt,
+	}
+	unset := gin.H{}
+	if note.Visibility != "" {
+		note.UserID = userID
+		if err := normalizeNoteVisibility(ctx, note); err != nil {
+			return err
+		}
+		set["visibility"] = note.Visibility
+		if note.Visibility == model.NoteVisibilityShared {
+			set["shared_with"] = note.SharedWith
+		} else {
+			unset["shared_with"] = ""
+		}
+	}
+
+	updated, err := saveNoteVersion(ctx, current, userID, bson.M(set), bson.M(unset), model.NoteRevisionUpdate, 0)
+	if err != nil {
+		return err
+	}
+	attachNoteImages(ctx, userID, updated)
+	*note = *updated
+	note.ContentHTML = markdown.Render(note.Content)
+	publishRoomEvent(ctx, note.RoomID, "note.updated", note)
+	return nil
 }
 
-// DeleteNote 删除笔记
+// DeleteNote 删除笔记及其历史版本和图片
 func (s *markServiceImpl) DeleteNote(ctx context.Context, userID string, noteID primitive.ObjectID) error {
 	collection := database.GetCollection("notes")
-	_, err := collection.DeleteOne(ctx, gin.H{"_id": noteID, "user_id": userID})
-	return err
+	var deleted model.Note
+	err := collection.FindOneAndDelete(ctx, gin.H{"_id": noteID, "user_id": userID}).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetCollection("note_revisions").DeleteMany(ctx, gin.H{"note_id": noteID}); err != nil {
+		return err
+	}
+	if _, err := deleteNoteImages(ctx, bson.M{"note_id": noteID}); err != nil {
+		return err
+	}
+	publishRoomEvent(ctx, deleted.RoomID, "note.deleted", gin.H{"id": noteID.Hex()})
+	return nil
+}
+
+// annotationRepair 根据注释所属的标记判断注释需要的修复操作：
+// 标记不存在时删除注释，video_id与标记不一致时返回正确的视频ID
+func annotationRepair(annotation model.Annotation, mark *model.Mark) (orphan bool, videoID string) {
+	if mark == nil {
+		return true, ""
+	}
+	if annotation.VideoID != mark.VideoID {
+		return false, mark.VideoID
+	}
+	return false, ""
+}
+
+// RepairAnnotations 扫描所有注释，删除标记已不存在的孤立注释，并补齐或修正注释的video_id
+func RepairAnnotations(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	annotations := database.GetCollection("annotations")
+	cursor, err := annotations.Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"mark_id": 1, "video_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := &MigrationResult{}
+	marks := make(map[primitive.ObjectID]*model.Mark)
+	for cursor.Next(ctx) {
+		var annotation model.Annotation
+		if err := cursor.Decode(&annotation); err != nil {
+			return result, err
+		}
+		result.Scanned++
+
+		mark, ok := marks[annotation.MarkID]
+		if !ok {
+			var found model.Mark
+			err := database.GetCollection("marks").FindOne(ctx, bson.M{"_id": annotation.MarkID},
+				options.FindOne().SetProjection(bson.M{"video_id": 1}),
+			).Decode(&found)
+			switch {
+			case err == nil:
+				mark = &found
+			case err != mongo.ErrNoDocuments:
+				return result, err
+			}
+			marks[annotation.MarkID] = mark
+		}
+
+		orphan, videoID := annotationRepair(annotation, mark)
+		if !orphan && videoID == "" {
+			continue
+		}
+		result.Fixed++
+		if dryRun {
+			continue
+		}
+
+		if orphan {
+			_, err = annotations.DeleteOne(ctx, bson.M{"_id": annotation.ID})
+		} else {
+			_, err = annotations.UpdateOne(ctx, bson.M{"_id": annotation.ID}, bson.M{"$set": bson.M{"video_id": videoID}})
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, cursor.Err()
 }
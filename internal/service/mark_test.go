@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 测试分享用户去重：去掉空值、重复用户和作者本人
+func TestUniqueShareTargets(t *testing.T) {
+	assert.Equal(t, []string{"u2", "u3"}, uniqueShareTargets([]string{"u2", "", "u1", "u3", "u2"}, "u1"))
+	assert.Empty(t, uniqueShareTargets(nil, "u1"))
+}
+
+// 测试笔记可见范围校验
+func TestNormalizeNoteVisibility(t *testing.T) {
+	ctx := context.Background()
+
+	note := &model.Note{UserID: "u1", Visibility: "friends"}
+	assert.ErrorIs(t, normalizeNoteVisibility(ctx, note), ErrInvalidNoteVisibility)
+
+	// 非分享笔记清空分享用户
+	note = &model.Note{UserID: "u1", Visibility: model.NoteVisibilityPublic, SharedWith: []string{"u2"}}
+	assert.NoError(t, normalizeNoteVisibility(ctx, note))
+	assert.Nil(t, note.SharedWith)
+
+	// 分享时必须指定作者以外的用户
+	note = &model.Note{UserID: "u1", Visibility: model.NoteVisibilityShared, SharedWith: []string{"u1"}}
+	assert.ErrorIs(t, normalizeNoteVisibility(ctx, note), ErrInvalidNoteVisibility)
+
+	note = &model.Note{UserID: "u1", Visibility: model.NoteVisibilityShared, SharedWith: []string{"not-an-id"}}
+	assert.ErrorIs(t, normalizeNoteVisibility(ctx, note), ErrInvalidNoteVisibility)
+}
+
+// 测试注释修复：标记不存在时删除，video_id不一致时修正
+func TestAnnotationRepair(t *testing.T) {
+	mark := &model.Mark{VideoID: "v1"}
+
+	orphan, videoID := annotationRepair(model.Annotation{VideoID: "v1"}, nil)
+	assert.True(t, orphan)
+	assert.Empty(t, videoID)
+
+	orphan, videoID = annotationRepair(model.Annotation{}, mark)
+	assert.False(t, orphan)
+	assert.Equal(t, "v1", videoID)
+
+	orphan, videoID = annotationRepair(model.Annotation{VideoID: "v1"}, mark)
+	assert.False(t, orphan)
+	assert.Empty(t, videoID)
+}
+
+// 测试标记校验：区间时间、分类和颜色
+func TestNormalizeMark(t *testing.T) {
+	mark := &model.Mark{Timestamp: 10, EndTime: 30, Category: model.MarkCategoryQuestion}
+	assert.NoError(t, normalizeMark(mark))
+	assert.True(t, mark.IsRange())
+	assert.Equal(t, "#4A90E2", mark.Color)
+
+	// 自定义颜色不被分类默认颜色覆盖
+	mark = &model.Mark{Timestamp: 10, Category: model.MarkCategoryMistake, Color: "#00ff00"}
+	assert.NoError(t, normalizeMark(mark))
+	assert.False(t, mark.IsRange())
+	assert.Equal(t, "#00ff00", mark.Color)
+
+	assert.ErrorIs(t, normalizeMark(&model.Mark{Timestamp: 30, EndTime: 10}), ErrInvalidMark)
+	assert.ErrorIs(t, normalizeMark(&model.Mark{Timestamp: -1}), ErrInvalidMark)
+	assert.ErrorIs(t, normalizeMark(&model.Mark{Category: "unknown"}), ErrInvalidMark)
+	assert.ErrorIs(t, normalizeMark(&model.Mark{Color: "red"}), ErrInvalidMark)
+}
+
+// 测试标记查询条件：分类和时间窗口
+func TestMarkFilter(t *testing.T) {
+	filter := markFilter("u1", &model.MarkQuery{VideoID: "v1"})
+	assert.Equal(t, bson.M{"user_id": "u1", "video_id": "v1"}, filter)
+
+	filter = markFilter("u1", &model.MarkQuery{VideoID: "v1", Category: model.MarkCategoryKeyPoint, Start: 60, End: 120})
+	assert.Equal(t, model.MarkCategoryKeyPoint, filter["category"])
+	assert.Equal(t, bson.M{"$lte": 120.0}, filter["timestamp"])
+	assert.Len(t, filter["$or"], 2)
+}
@@ -0,0 +1,477 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/ws"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrConversationNotFound 会话不存在或当前用户不是会话成员
+	ErrConversationNotFound = errors.New("会话不存在")
+	// ErrInvalidMessage 消息内容不合法
+	ErrInvalidMessage = errors.New("无效的消息")
+	// ErrInvalidPeer 私信对象不合法
+	ErrInvalidPeer = errors.New("无效的私信对象")
+)
+
+const (
+	messageMaxLength       = 1000 // 文本消息最大字符数
+	emojiMaxLength         = 32   // 表情消息最大字符数
+	messageDefaultLimit    = 50   // 默认每次拉取的消息数
+	messageMaxLimit        = 200  // 每次拉取的消息数上限
+	messageOfflineSyncSize = 100  // 重连时每个会话最多补发的离线消息数
+)
+
+// imRelay 私信推送的连接管理，按用户ID分房间，同一用户的多个设备加入同一房间
+var imRelay = ws.NewRelay(ws.NewHub(), "im")
+
+// MessageService 私信服务接口
+type MessageService interface {
+	GetOrCreateConversation(ctx context.Context, userID, peerID string) (*model.Conversation, error)
+	ListConversations(ctx context.Context, userID string, page, size int) ([]model.ConversationItem, error)
+	Send(ctx context.Context, senderID string, req *model.SendMessageRequest) (*model.Message, error)
+	ListMessages(ctx context.Context, userID, conversationID string, query model.MessageQuery) ([]model.Message, error)
+	MarkDelivered(ctx context.Context, userID, conversationID string, seq int64) error
+	MarkRead(ctx context.Context, userID, conversationID string, seq int64) error
+	SyncOffline(ctx context.Context, userID string) ([]model.Message, error)
+	Subscribe(userID string, client *ws.Client)
+	Unsubscribe(userID string, client *ws.Client)
+}
+
+type messageService struct {
+	collection string
+	relay      *ws.Relay
+}
+
+// NewMessageService 创建私信服务实例，relay 为空时使用共享的推送连接
+func NewMessageService(relay *ws.Relay) MessageService {
+	if relay == nil {
+		relay = imRelay
+	}
+	return &messageService{
+		collection: "messages",
+		relay:      relay,
+	}
+}
+
+// GetOrCreateConversation 获取与对方的会话，不存在时创建
+func (s *messageService) GetOrCreateConversation(ctx context.Context, userID, peerID string) (*model.Conversation, error) {
+	if userID == peerID {
+		return nil, fmt.Errorf("%w: 不能给自己发私信", ErrInvalidPeer)
+	}
+	objectID, err := primitive.ObjectIDFromHex(peerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无效的用户ID", ErrInvalidPeer)
+	}
+	count, err := database.GetCollection("users").CountDocuments(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: 用户不存在", ErrInvalidPeer)
+	}
+	if err := checkInteraction(ctx, userID, peerID); err != nil {
+		return nil, err
+	}
+
+	key := conversationKey(userID, peerID)
+	now := time.Now()
+	members := []string{userID, peerID}
+	sort.Strings(members)
+
+	var conv model.Conversation
+	err = database.GetCollection("conversations").FindOneAndUpdate(ctx,
+		bson.M{"key": key},
+		bson.M{"$setOnInsert": bson.M{
+			"members":       members,
+			"last_seq":      0,
+			"delivered_seq": bson.M{},
+			"read_seq":      bson.M{},
+			"created_at":    now,
+			"updated_at":    now,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&conv)
+	// 并发创建时唯一索引冲突，重新读取即可
+	if mongo.IsDuplicateKeyError(err) {
+		err = database.GetCollection("conversations").FindOne(ctx, bson.M{"key": key}).Decode(&conv)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// ListConversations 获取会话列表，按最近消息时间倒序
+func (s *messageService) ListConversations(ctx context.Context, userID string, page, size int) ([]model.ConversationItem, error) {
+	// 设置默认分页参数
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := database.GetCollection("conversations").Find(ctx,
+		bson.M{"members": userID, "last_seq": bson.M{"$gt": 0}},
+		opts,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var convs []model.Conversation
+	if err = cursor.All(ctx, &convs); err != nil {
+		return nil, err
+	}
+
+	peerIDs := make([]string, 0, len(convs))
+	for _, conv := range convs {
+		peerIDs = append(peerIDs, conversationPeer(&conv, userID))
+	}
+	briefs, err := loadUserBriefs(ctx, peerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.ConversationItem, 0, len(convs))
+	for _, conv := range convs {
+		peerID := conversationPeer(&conv, userID)
+		readSeq := conv.ReadSeq[userID]
+		items = append(items, model.ConversationItem{
+			ID:          conv.ID,
+			Peer:        briefs[peerID],
+			LastSeq:     conv.LastSeq,
+			LastMessage: conv.LastMessage,
+			ReadSeq:     readSeq,
+			PeerReadSeq: conv.ReadSeq[peerID],
+			Unread:      max(conv.LastSeq-readSeq, 0),
+			UpdatedAt:   conv.UpdatedAt,
+		})
+	}
+	return items, nil
+}
+
+// Send 发送私信，推送给双方的所有在线设备
+func (s *messageService) Send(ctx context.Context, senderID string, req *model.SendMessageRequest) (*model.Message, error) {
+	content := strings.TrimSpace(req.Content)
+	msgType := req.Type
+	if msgType == "" {
+		msgType = model.MessageTypeText
+	}
+	if err := validateMessage(msgType, content); err != nil {
+		return nil, err
+	}
+
+	collection := database.GetCollection(s.collection)
+
+	// 客户端重发时直接返回已保存的消息
+	if req.ClientMsgID != "" {
+		var existing model.Message
+		err := collection.FindOne(ctx, bson.M{"sender_id": senderID, "client_msg_id": req.ClientMsgID}).Decode(&existing)
+		if err == nil {
+			return &existing, nil
+		}
+		if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+	}
+
+	conv, err := s.GetOrCreateConversation(ctx, senderID, req.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	var msg model.Message
+	// 在事务中分配序号并保存消息，保证序号连续
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		now := time.Now()
+
+		// 1. 分配会话内序号
+		var updated model.Conversation
+		err := database.GetCollection("conversations").FindOneAndUpdate(sessCtx,
+			bson.M{"_id": conv.ID},
+			bson.M{
+				"$inc": bson.M{"last_seq": 1},
+				"$set": bson.M{"updated_at": now},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err != nil {
+			return nil, err
+		}
+
+		// 2. 保存消息
+		msg = model.Message{
+			ID:             primitive.NewObjectID(),
+			ConversationID: conv.ID,
+			Seq:            updated.LastSeq,
+			SenderID:       senderID,
+			ReceiverID:     req.ToUserID,
+			Type:           msgType,
+			Content:        content,
+			ClientMsgID:    req.ClientMsgID,
+			CreatedAt:      now,
+		}
+		if _, err := collection.InsertOne(sessCtx, msg); err != nil {
+			return nil, err
+		}
+
+		// 3. 更新最新消息，发送者视为已读自己的消息
+		_, err = database.GetCollection("conversations").UpdateOne(sessCtx,
+			bson.M{"_id": conv.ID},
+			bson.M{
+				"$set": bson.M{"last_message": msg},
+				"$max": bson.M{
+					"delivered_seq." + senderID: msg.Seq,
+					"read_seq." + senderID:      msg.Seq,
+				},
+			},
+		)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, ws.Message{Type: "message", Data: msg}, senderID, req.ToUserID)
+	return &msg, nil
+}
+
+// ListMessages 获取会话消息，按序号正序返回
+func (s *messageService) ListMessages(ctx context.Context, userID, conversationID string, query model.MessageQuery) ([]model.Message, error) {
+	conv, err := s.findConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = messageDefaultLimit
+	}
+	if limit > messageMaxLimit {
+		limit = messageMaxLimit
+	}
+
+	filter := bson.M{"conversation_id": conv.ID}
+	ascending := false
+	switch {
+	case query.AfterSeq > 0:
+		// 补齐缺失或同步新消息：从指定序号之后正序拉取
+		filter["seq"] = bson.M{"$gt": query.AfterSeq}
+		ascending = true
+	case query.BeforeSeq > 0:
+		// 向上翻页：拉取指定序号之前的消息
+		filter["seq"] = bson.M{"$lt": query.BeforeSeq}
+	}
+
+	return s.findMessages(ctx, filter, limit, ascending)
+}
+
+// MarkDelivered 记录消息已送达，并通知对方
+func (s *messageService) MarkDelivered(ctx context.Context, userID, conversationID string, seq int64) error {
+	return s.ack(ctx, userID, conversationID, seq, false)
+}
+
+// MarkRead 记录消息已读，并通知对方和自己的其他设备
+func (s *messageService) MarkRead(ctx context.Context, userID, conversationID string, seq int64) error {
+	return s.ack(ctx, userID, conversationID, seq, true)
+}
+
+// ack 更新送达/已读序号，序号只增不减
+func (s *messageService) ack(ctx context.Context, userID, conversationID string, seq int64, read bool) error {
+	conv, err := s.findConversation(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+	if seq < 1 {
+		return errors.New("无效的消息序号")
+	}
+	if seq > conv.LastSeq {
+		seq = conv.LastSeq
+	}
+
+	fields := bson.M{"delivered_seq." + userID: seq}
+	if read {
+		fields["read_seq."+userID] = seq
+	}
+	if _, err := database.GetCollection("conversations").UpdateOne(ctx,
+		bson.M{"_id": conv.ID},
+		bson.M{"$max": fields},
+	); err != nil {
+		return err
+	}
+
+	receipt := model.MessageReceipt{
+		ConversationID: conversationID,
+		UserID:         userID,
+		DeliveredSeq:   seq,
+	}
+	rooms := []string{conversationPeer(conv, userID)}
+	if read {
+		receipt.ReadSeq = seq
+		// 已读需要同步到自己的其他设备，以更新未读数
+		rooms = append(rooms, userID)
+	}
+	s.publish(ctx, ws.Message{Type: "receipt", Data: receipt}, rooms...)
+	return nil
+}
+
+// SyncOffline 获取离线期间未送达的消息（每个会话最多补发若干条，其余由客户端按序号拉取）
+func (s *messageService) SyncOffline(ctx context.Context, userID string) ([]model.Message, error) {
+	cursor, err := database.GetCollection("conversations").Find(ctx, bson.M{
+		"members":  userID,
+		"last_seq": bson.M{"$gt": 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var convs []model.Conversation
+	if err = cursor.All(ctx, &convs); err != nil {
+		return nil, err
+	}
+
+	messages := make([]model.Message, 0)
+	for _, conv := range convs {
+		delivered := conv.DeliveredSeq[userID]
+		if conv.LastSeq <= delivered {
+			continue
+		}
+		pending, err := s.findMessages(ctx, bson.M{
+			"conversation_id": conv.ID,
+			"seq":             bson.M{"$gt": delivered},
+		}, messageOfflineSyncSize, true)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, pending...)
+	}
+	return messages, nil
+}
+
+// Subscribe 用户设备上线
+func (s *messageService) Subscribe(userID string, client *ws.Client) {
+	s.relay.Join(userID, client)
+}
+
+// Unsubscribe 用户设备下线
+func (s *messageService) Unsubscribe(userID string, client *ws.Client) {
+	s.relay.Leave(userID, client)
+}
+
+// publish 推送消息给指定用户的所有在线设备
+func (s *messageService) publish(ctx context.Context, msg ws.Message, userIDs ...string) {
+	for _, userID := range userIDs {
+		if err := s.relay.Publish(ctx, userID, msg); err != nil {
+			slog.Warn("推送私信失败", "error", err, "userId", userID, "type", msg.Type)
+		}
+	}
+}
+
+// findConversation 查询会话并校验当前用户是会话成员
+func (s *messageService) findConversation(ctx context.Context, userID, conversationID string) (*model.Conversation, error) {
+	objectID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return nil, ErrConversationNotFound
+	}
+	var conv model.Conversation
+	err = database.GetCollection("conversations").FindOne(ctx, bson.M{"_id": objectID, "members": userID}).Decode(&conv)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// findMessages 按序号查询消息，结果始终按序号正序返回
+func (s *messageService) findMessages(ctx context.Context, filter bson.M, limit int, ascending bool) ([]model.Message, error) {
+	order := -1
+	if ascending {
+		order = 1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "seq", Value: order}}).
+		SetLimit(int64(limit))
+	cursor, err := database.GetCollection(s.collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	messages := make([]model.Message, 0, limit)
+	if err = cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	if !ascending {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+	return messages, nil
+}
+
+// validateMessage 校验消息类型和内容
+func validateMessage(msgType, content string) error {
+	if !model.IsValidMessageType(msgType) {
+		return fmt.Errorf("%w: 不支持的消息类型", ErrInvalidMessage)
+	}
+	length := utf8.RuneCountInString(content)
+	if length == 0 {
+		return fmt.Errorf("%w: 消息内容不能为空", ErrInvalidMessage)
+	}
+	if msgType == model.MessageTypeEmoji && length > emojiMaxLength {
+		return fmt.Errorf("%w: 表情内容过长", ErrInvalidMessage)
+	}
+	if length > messageMaxLength {
+		return fmt.Errorf("%w: 消息内容不能超过%d个字符", ErrInvalidMessage, messageMaxLength)
+	}
+	return nil
+}
+
+// conversationKey 生成会话唯一键，与双方顺序无关
+func conversationKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + ":" + b
+}
+
+// conversationPeer 获取会话中的对方ID
+func conversationPeer(conv *model.Conversation, userID string) string {
+	for _, m := range conv.Members {
+		if m != userID {
+			return m
+		}
+	}
+	return userID
+}
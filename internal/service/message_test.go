@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试会话唯一键与双方顺序无关
+func TestConversationKey(t *testing.T) {
+	assert.Equal(t, conversationKey("a", "b"), conversationKey("b", "a"))
+	assert.Equal(t, "a:b", conversationKey("b", "a"))
+}
+
+// 测试获取会话中的对方
+func TestConversationPeer(t *testing.T) {
+	conv := &model.Conversation{Members: []string{"u1", "u2"}}
+
+	assert.Equal(t, "u2", conversationPeer(conv, "u1"))
+	assert.Equal(t, "u1", conversationPeer(conv, "u2"))
+}
+
+// 测试消息校验
+func TestValidateMessage(t *testing.T) {
+	assert.Nil(t, validateMessage(model.MessageTypeText, "你好"))
+	assert.Nil(t, validateMessage(model.MessageTypeEmoji, "[微笑]"))
+
+	err := validateMessage("image", "x")
+	assert.True(t, errors.Is(err, ErrInvalidMessage))
+
+	err = validateMessage(model.MessageTypeText, "")
+	assert.True(t, errors.Is(err, ErrInvalidMessage))
+
+	err = validateMessage(model.MessageTypeText, strings.Repeat("字", messageMaxLength+1))
+	assert.True(t, errors.Is(err, ErrInvalidMessage))
+
+	err = validateMessage(model.MessageTypeEmoji, strings.Repeat("😀", emojiMaxLength+1))
+	assert.True(t, errors.Is(err, ErrInvalidMessage))
+}
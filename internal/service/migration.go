@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrationResult 数据迁移/修复任务的执行结果
+type MigrationResult struct {
+	Scanned int64 // 扫描的记录数
+	Fixed   int64 // 需要修复（或已修复）的记录数
+}
+
+// RecountVideoInteractions 根据likes和favorites集合重新计算视频的点赞数和收藏数
+// dryRun为true时只统计需要修复的视频数量，不写入数据库
+func RecountVideoInteractions(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	likes, err := countByVideo(ctx, "likes")
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := countByVideo(ctx, "favorites")
+	if err != nil {
+		return nil, err
+	}
+
+	// 遍历所有视频，对比计数
+	collection := database.GetCollection("videos")
+	cursor, err := collection.Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"stats.likes": 1, "stats.favorites": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := &MigrationResult{}
+	var models []mongo.WriteModel
+	for cursor.Next(ctx) {
+		var video struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Stats struct {
+				Likes     int64 `bson:"likes"`
+				Favorites int64 `bson:"favorites"`
+			} `bson:"stats"`
+		}
+		if err := cursor.Decode(&video); err != nil {
+			return nil, err
+		}
+		result.Scanned++
+
+		id := video.ID.Hex()
+		if video.Stats.Likes == likes[id] && video.Stats.Favorites == favorites[id] {
+			continue
+		}
+
+		result.Fixed++
+		slog.Info("[RecountVideoInteractions] 计数不一致",
+			"videoId", id,
+			"likes", video.Stats.Likes, "expectedLikes", likes[id],
+			"favorites", video.Stats.Favorites, "expectedFavorites", favorites[id],
+		)
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": video.ID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"stats.likes":     likes[id],
+				"stats.favorites": favorites[id],
+			}}),
+		)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if len(models) == 0 {
+		return result, nil
+	}
+
+	if _, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// countByVideo 按视频ID统计集合中的用户数，同一用户的重复记录只计一次
+func countByVideo(ctx context.Context, name string) (map[string]int64, error) {
+	cursor, err := database.GetCollection(name).Aggregate(ctx, []bson.M{
+		{"$group": bson.M{
+			"_id": bson.M{"video_id": "$video_id", "user_id": "$user_id"},
+		}},
+		{"$group": bson.M{
+			"_id":   "$_id.video_id",
+			"count": bson.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		VideoID string `bson:"_id"`
+		Count   int64  `bson:"count"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		counts[r.VideoID] = r.Count
+	}
+	return counts, nil
+}
+
+// indexSpec 需要确保存在的索引
+type indexSpec struct {
+	collection string
+	model      mongo.IndexModel
+}
+
+// requiredIndexes 业务依赖的索引（唯一性约束和高频查询）
+var requiredIndexes = []indexSpec{
+	{"follows", mongo.IndexModel{
+		Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	// 同一用户对同一视频只有一条点赞记录，LikeVideo 的 upsert 依赖该索引保证并发幂等
+	{"likes", mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "video_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"comment_likes", mongo.IndexModel{
+		Keys:    bson.D{{Key: "comment_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	// 分享事件只用于重建热门排行榜，保留到小时桶过期
+	{"share_events", mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(trendingBucketTTL / time.Second)),
+	}},
+	{"danmaku", mongo.IndexModel{
+		Keys: bson.D{{Key: "video_id", Value: 1}, {Key: "time", Value: 1}},
+	}},
+	{"share_links", mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"share_links", mongo.IndexModel{
+		Keys: bson.D{{Key: "video_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "channel", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"user_id": bson.M{"$gt": ""}}),
+	}},
+	{"notifications", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read", Value: 1}, {Key: "updated_at", Value: -1}},
+	}},
+	// 同一对象的未读聚合通知只有一条，保证并发触发时不会重复创建
+	{"notifications", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "type", Value: 1}, {Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"aggregated": true, "read": false}),
+	}},
+	{"conversations", mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"conversations", mongo.IndexModel{
+		Keys: bson.D{{Key: "members", Value: 1}, {Key: "updated_at", Value: -1}},
+	}},
+	{"messages", mongo.IndexModel{
+		Keys:    bson.D{{Key: "conversation_id", Value: 1}, {Key: "seq", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"messages", mongo.IndexModel{
+		Keys: bson.D{{Key: "sender_id", Value: 1}, {Key: "client_msg_id", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"client_msg_id": bson.M{"$exists": true}}),
+	}},
+	{"recommend_candidates", mongo.IndexModel{
+		Keys: bson.D{{Key: "popularity", Value: -1}},
+	}},
+	{"playlists", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "updated_at", Value: -1}},
+	}},
+	{"playlist_items", mongo.IndexModel{
+		Keys:    bson.D{{Key: "playlist_id", Value: 1}, {Key: "video_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"playlist_items", mongo.IndexModel{
+		Keys: bson.D{{Key: "playlist_id", Value: 1}, {Key: "position", Value: 1}},
+	}},
+	{"watch_later", mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "video_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"watch_later", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "position", Value: 1}},
+	}},
+	{"blocks", mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "target_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"moderation_cases", mongo.IndexModel{
+		Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"open": true}),
+	}},
+	{"moderation_cases", mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "report_count", Value: -1}, {Key: "created_at", Value: 1}},
+	}},
+	{"reports", mongo.IndexModel{
+		Keys:    bson.D{{Key: "case_id", Value: 1}, {Key: "reporter_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"moderation_logs", mongo.IndexModel{
+		Keys: bson.D{{Key: "case_id", Value: 1}, {Key: "created_at", Value: 1}},
+	}},
+	{"notes", mongo.IndexModel{
+		Keys: bson.D{{Key: "video_id", Value: 1}, {Key: "user_id", Value: 1}},
+	}},
+	{"notes", mongo.IndexModel{
+		Keys: bson.D{{Key: "video_id", Value: 1}, {Key: "visibility", Value: 1}, {Key: "timestamp", Value: 1}},
+	}},
+	{"notes", mongo.IndexModel{
+		Keys: bson.D{{Key: "shared_with", Value: 1}, {Key: "video_id", Value: 1}},
+	}},
+	{"favorite_folders", mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"marks", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "video_id", Value: 1}, {Key: "timestamp", Value: 1}},
+	}},
+	{"annotations", mongo.IndexModel{
+		Keys: bson.D{{Key: "mark_id", Value: 1}, {Key: "created_at", Value: 1}},
+	}},
+	{"annotations", mongo.IndexModel{
+		Keys: bson.D{{Key: "video_id", Value: 1}},
+	}},
+	{"annotations", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	}},
+	{"marks", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	}},
+	{"notes", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	}},
+	{"note_revisions", mongo.IndexModel{
+		Keys:    bson.D{{Key: "note_id", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	}},
+	{"note_revisions", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "video_id", Value: 1}},
+	}},
+	{"note_images", mongo.IndexModel{
+		Keys: bson.D{{Key: "note_id", Value: 1}},
+	}},
+	{"note_images", mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "file_name", Value: 1}},
+	}},
+	{"note_images", mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: 1}},
+	}},
+	{"annotation_rooms", mongo.IndexModel{
+		Keys: bson.D{{Key: "members", Value: 1}, {Key: "updated_at", Value: -1}},
+	}},
+	{"marks", mongo.IndexModel{
+		Keys:    bson.D{{Key: "room_id", Value: 1}, {Key: "timestamp", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"room_id": bson.M{"$exists": true}}),
+	}},
+	{"notes", mongo.IndexModel{
+		Keys:    bson.D{{Key: "room_id", Value: 1}, {Key: "timestamp", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"room_id": bson.M{"$exists": true}}),
+	}},
+}
+
+// EnsureIndexes 创建业务依赖的索引，已存在的索引不会重复创建
+func EnsureIndexes(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	result := &MigrationResult{Scanned: int64(len(requiredIndexes))}
+	if dryRun {
+		return result, nil
+	}
+
+	for _, spec := range requiredIndexes {
+		name, err := database.GetCollection(spec.collection).Indexes().CreateOne(ctx, spec.model)
+		if err != nil {
+			return result, fmt.Errorf("创建索引 %s 失败: %w", spec.collection, err)
+		}
+		slog.Info("[EnsureIndexes] 索引已就绪", "collection", spec.collection, "index", name)
+		result.Fixed++
+	}
+	return result, nil
+}
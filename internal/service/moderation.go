@@ -0,0 +1,667 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/ws"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 举报与审核相关错误
+var (
+	ErrInvalidReport            = errors.New("无效的举报")
+	ErrAlreadyReported          = errors.New("已经举报过该内容")
+	ErrReportTargetNotFound     = errors.New("举报的内容不存在")
+	ErrModerationCaseNotFound   = errors.New("审核工单不存在")
+	ErrModerationCaseClaimed    = errors.New("审核工单已被其他审核员领取")
+	ErrModerationCaseNotClaimed = errors.New("请先领取审核工单")
+	ErrModerationCaseClosed     = errors.New("审核工单已处理")
+)
+
+// 工单详情中返回的举报记录数
+const moderationCaseReportLimit = 100
+
+// ModerationService 举报与审核服务接口
+type ModerationService interface {
+	Report(ctx context.Context, reporterID string, req *model.CreateReportRequest) (*model.Report, error)
+	ListCases(ctx context.Context, query model.ModerationQuery) (*model.ModerationCaseListResponse, error)
+	GetCase(ctx context.Context, caseID string) (*model.ModerationCaseDetail, error)
+	Claim(ctx context.Context, moderatorID, caseID string) (*model.ModerationCase, error)
+	Resolve(ctx context.Context, moderatorID, caseID string, req *model.ResolveCaseRequest) (*model.ModerationCase, error)
+	Dismiss(ctx context.Context, moderatorID, caseID, note string) (*model.ModerationCase, error)
+	ListLogs(ctx context.Context, query model.ModerationLogQuery) (*model.ModerationLogListResponse, error)
+}
+
+type moderationService struct {
+	collection string
+}
+
+// NewModerationService 创建举报与审核服务实例
+func NewModerationService() ModerationService {
+	return &moderationService{
+		collection: "moderation_cases",
+	}
+}
+
+// Report 举报内容。同一对象未处理的举报合并到一个工单，举报数达到阈值时自动隐藏内容
+func (s *moderationService) Report(ctx context.Context, reporterID string, req *model.CreateReportRequest) (*model.Report, error) {
+	if !model.IsValidReportTarget(req.TargetType) {
+		return nil, fmt.Errorf("%w: 无效的举报对象", ErrInvalidReport)
+	}
+	if !model.IsValidReportReason(req.Reason) {
+		return nil, fmt.Errorf("%w: 无效的举报原因", ErrInvalidReport)
+	}
+	ownerID, err := reportTargetOwner(ctx, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == reporterID {
+		return nil, fmt.Errorf("%w: 不能举报自己", ErrInvalidReport)
+	}
+
+	mc, err := s.openCase(ctx, req.TargetType, req.TargetID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一用户对同一工单只能举报一次，由 (case_id, reporter_id) 唯一索引保证
+	report := &model.Report{
+		ID:          primitive.NewObjectID(),
+		CaseID:      mc.ID,
+		ReporterID:  reporterID,
+		TargetType:  req.TargetType,
+		TargetID:    req.TargetID,
+		Reason:      req.Reason,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := database.GetCollection("reports").InsertOne(ctx, report); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrAlreadyReported
+		}
+		return nil, err
+	}
+
+	err = database.GetCollection(s.collection).FindOneAndUpdate(ctx,
+		bson.M{"_id": mc.ID},
+		bson.M{
+			"$inc": bson.M{"report_count": 1, "reasons." + req.Reason: 1},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(mc)
+	if err != nil {
+		return nil, err
+	}
+
+	if shouldAutoHide(mc) {
+		if err := s.autoHide(ctx, mc); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// openCase 获取对象未处理的工单，不存在时创建
+func (s *moderationService) openCase(ctx context.Context, targetType, targetID, ownerID string) (*model.ModerationCase, error) {
+	collection := database.GetCollection(s.collection)
+	filter := bson.M{"target_type": targetType, "target_id": targetID, "open": true}
+	now := time.Now()
+
+	var mc model.ModerationCase
+	err := collection.FindOneAndUpdate(ctx,
+		filter,
+		bson.M{"$setOnInsert": bson.M{
+			"owner_id":     ownerID,
+			"status":       model.ModerationStatusPending,
+			"report_count": 0,
+			"reasons":      bson.M{},
+			"hidden":       false,
+			"created_at":   now,
+			"updated_at":   now,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&mc)
+	// 并发创建时唯一索引冲突，重新读取即可
+	if mongo.IsDuplicateKeyError(err) {
+		err = collection.FindOne(ctx, filter).Decode(&mc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mc, nil
+}
+
+// shouldAutoHide 待领取的工单举报数达到阈值且内容尚未隐藏时自动隐藏，用户不会被自动禁用
+func shouldAutoHide(mc *model.ModerationCase) bool {
+	threshold := config.GlobalConfig.Moderation.HideThreshold
+	return threshold > 0 &&
+		mc.ReportCount >= threshold &&
+		!mc.Hidden &&
+		mc.Status == model.ModerationStatusPending &&
+		mc.TargetType != model.ReportTargetUser
+}
+
+// autoHide 自动隐藏被举报的内容，并发举报时只执行一次
+func (s *moderationService) autoHide(ctx context.Context, mc *model.ModerationCase) error {
+	result, err := database.GetCollection(s.collection).UpdateOne(ctx,
+		bson.M{"_id": mc.ID, "open": true, "hidden": false},
+		bson.M{"$set": bson.M{"hidden": true, "updated_at": time.Now()}},
+	)
+	if err != nil || result.ModifiedCount == 0 {
+		return err
+	}
+
+	prevStatus, err := hideReportTarget(ctx, mc.TargetType, mc.TargetID)
+	if err != nil {
+		return err
+	}
+	if prevStatus != "" {
+		if _, err := database.GetCollection(s.collection).UpdateOne(ctx,
+			bson.M{"_id": mc.ID},
+			bson.M{"$set": bson.M{"prev_status": prevStatus}},
+		); err != nil {
+			return err
+		}
+	}
+	mc.Hidden = true
+	mc.PrevStatus = prevStatus
+
+	note := fmt.Sprintf("举报次数达到%d次，自动隐藏", mc.ReportCount)
+	return logModeration(ctx, mc, model.ModerationActorSystem, model.ModerationActionAutoHide, note)
+}
+
+// ListCases 获取审核队列，按举报次数倒序、创建时间正序
+func (s *moderationService) ListCases(ctx context.Context, query model.ModerationQuery) (*model.ModerationCaseListResponse, error) {
+	page, size := query.Page, query.Size
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	status := query.Status
+	if status == "" {
+		status = model.ModerationStatusPending
+	}
+	filter := bson.M{"status": status}
+	if query.TargetType != "" {
+		filter["target_type"] = query.TargetType
+	}
+
+	collection := database.GetCollection(s.collection)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "report_count", Value: -1}, {Key: "created_at", Value: 1}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	cases := []model.ModerationCase{}
+	if err := cursor.All(ctx, &cases); err != nil {
+		return nil, err
+	}
+
+	return &model.ModerationCaseListResponse{
+		Cases: cases,
+		Total: total,
+		Page:  page,
+		Size:  size,
+	}, nil
+}
+
+// GetCase 获取工单详情，包括最近的举报记录和审核记录
+func (s *moderationService) GetCase(ctx context.Context, caseID string) (*model.ModerationCaseDetail, error) {
+	mc, err := s.findCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &model.ModerationCaseDetail{
+		ModerationCase: *mc,
+		Reports:        []model.Report{},
+		Logs:           []model.ModerationLog{},
+	}
+
+	cursor, err := database.GetCollection("reports").Find(ctx,
+		bson.M{"case_id": mc.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(moderationCaseReportLimit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &detail.Reports); err != nil {
+		return nil, err
+	}
+
+	cursor, err = database.GetCollection("moderation_logs").Find(ctx,
+		bson.M{"case_id": mc.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &detail.Logs); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// Claim 领取工单。已被领取的工单超时未处理时可以重新领取
+func (s *moderationService) Claim(ctx context.Context, moderatorID, caseID string) (*model.ModerationCase, error) {
+	objectID, err := primitive.ObjectIDFromHex(caseID)
+	if err != nil {
+		return nil, ErrModerationCaseNotFound
+	}
+
+	now := time.Now()
+	expired := now.Add(-time.Duration(config.GlobalConfig.Moderation.ClaimTimeout) * time.Minute)
+	var mc model.ModerationCase
+	err = database.GetCollection(s.collection).FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":  objectID,
+			"open": true,
+			"$or": bson.A{
+				bson.M{"status": model.ModerationStatusPending},
+				bson.M{"status": model.ModerationStatusClaimed, "claimed_by": moderatorID},
+				bson.M{"status": model.ModerationStatusClaimed, "claimed_at": bson.M{"$lt": expired}},
+			},
+		},
+		bson.M{"$set": bson.M{
+			"status":     model.ModerationStatusClaimed,
+			"claimed_by": moderatorID,
+			"claimed_at": now,
+			"updated_at": now,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&mc)
+	if err == mongo.ErrNoDocuments {
+		existing, err := s.findCase(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		if !existing.Open {
+			return nil, ErrModerationCaseClosed
+		}
+		return nil, ErrModerationCaseClaimed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := logModeration(ctx, &mc, moderatorID, model.ModerationActionClaim, ""); err != nil {
+		return nil, err
+	}
+	return &mc, nil
+}
+
+// Resolve 确认违规。remove 下架视频、隐藏评论或标记、禁用用户；warn 只警告作者，自动隐藏的内容会被恢复
+func (s *moderationService) Resolve(ctx context.Context, moderatorID, caseID string, req *model.ResolveCaseRequest) (*model.ModerationCase, error) {
+	if req.Action != model.ModerationActionRemove && req.Action != model.ModerationActionWarn {
+		return nil, fmt.Errorf("%w: 无效的处理方式", ErrInvalidReport)
+	}
+	mc, err := s.claimedCase(ctx, moderatorID, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{}
+	switch {
+	case req.Action == model.ModerationActionRemove && !mc.Hidden:
+		prevStatus, err := hideReportTarget(ctx, mc.TargetType, mc.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		set["hidden"] = true
+		if prevStatus != "" {
+			set["prev_status"] = prevStatus
+		}
+	case req.Action == model.ModerationActionWarn && mc.Hidden:
+		if err := restoreReportTarget(ctx, mc.TargetType, mc.TargetID, mc.PrevStatus); err != nil {
+			return nil, err
+		}
+		set["hidden"] = false
+	}
+
+	if err := s.close(ctx, mc, moderatorID, model.ModerationStatusResolved, req.Action, req.Note, set); err != nil {
+		return nil, err
+	}
+
+	message := "你的内容因违反社区规范已被处理"
+	if req.Action == model.ModerationActionWarn {
+		message = "你的内容被举报并经审核确认违反社区规范，请注意遵守社区规范"
+	}
+	notifyAsync(model.Notification{
+		UserID:     mc.OwnerID,
+		Type:       model.NotificationTypeSystem,
+		TargetType: mc.TargetType,
+		TargetID:   mc.TargetID,
+		Content:    message,
+	})
+	return mc, nil
+}
+
+// Dismiss 驳回举报，恢复自动隐藏的内容
+func (s *moderationService) Dismiss(ctx context.Context, moderatorID, caseID, note string) (*model.ModerationCase, error) {
+	mc, err := s.claimedCase(ctx, moderatorID, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{}
+	if mc.Hidden {
+		if err := restoreReportTarget(ctx, mc.TargetType, mc.TargetID, mc.PrevStatus); err != nil {
+			return nil, err
+		}
+		set["hidden"] = false
+	}
+
+	if err := s.close(ctx, mc, moderatorID, model.ModerationStatusDismissed, model.ModerationActionDismiss, note, set); err != nil {
+		return nil, err
+	}
+	return mc, nil
+}
+
+// ListLogs 获取审核记录，按时间倒序
+func (s *moderationService) ListLogs(ctx context.Context, query model.ModerationLogQuery) (*model.ModerationLogListResponse, error) {
+	page, size := query.Page, query.Size
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	filter := bson.M{}
+	if query.ModeratorID != "" {
+		filter["moderator_id"] = query.ModeratorID
+	}
+	if query.CaseID != "" {
+		caseID, err := primitive.ObjectIDFromHex(query.CaseID)
+		if err != nil {
+			return nil, ErrModerationCaseNotFound
+		}
+		filter["case_id"] = caseID
+	}
+
+	collection := database.GetCollection("moderation_logs")
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	logs := []model.ModerationLog{}
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+
+	return &model.ModerationLogListResponse{
+		Logs:  logs,
+		Total: total,
+		Page:  page,
+		Size:  size,
+	}, nil
+}
+
+// findCase 根据ID查找工单
+func (s *moderationService) findCase(ctx context.Context, caseID string) (*model.ModerationCase, error) {
+	objectID, err := primitive.ObjectIDFromHex(caseID)
+	if err != nil {
+		return nil, ErrModerationCaseNotFound
+	}
+
+	var mc model.ModerationCase
+	err = database.GetCollection(s.collection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&mc)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrModerationCaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mc, nil
+}
+
+// claimedCase 查找由该审核员领取的未处理工单
+func (s *moderationService) claimedCase(ctx context.Context, moderatorID, caseID string) (*model.ModerationCase, error) {
+	mc, err := s.findCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if !mc.Open {
+		return nil, ErrModerationCaseClosed
+	}
+	if mc.Status != model.ModerationStatusClaimed || mc.ClaimedBy != moderatorID {
+		return nil, ErrModerationCaseNotClaimed
+	}
+	return mc, nil
+}
+
+// close 关闭工单并写入审核记录，set 为需要同时更新的字段
+func (s *moderationService) close(ctx context.Context, mc *model.ModerationCase, moderatorID, status, action, note string, set bson.M) error {
+	now := time.Now()
+	set["open"] = false
+	set["status"] = status
+	set["action"] = action
+	set["note"] = note
+	set["resolved_by"] = moderatorID
+	set["resolved_at"] = now
+	set["updated_at"] = now
+
+	err := database.GetCollection(s.collection).FindOneAndUpdate(ctx,
+		bson.M{"_id": mc.ID, "open": true, "status": model.ModerationStatusClaimed, "claimed_by": moderatorID},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(mc)
+	if err == mongo.ErrNoDocuments {
+		return ErrModerationCaseNotClaimed
+	}
+	if err != nil {
+		return err
+	}
+	return logModeration(ctx, mc, moderatorID, action, note)
+}
+
+// logModeration 写入审核记录
+func logModeration(ctx context.Context, mc *model.ModerationCase, moderatorID, action, note string) error {
+	_, err := database.GetCollection("moderation_logs").InsertOne(ctx, model.ModerationLog{
+		ID:          primitive.NewObjectID(),
+		CaseID:      mc.ID,
+		TargetType:  mc.TargetType,
+		TargetID:    mc.TargetID,
+		ModeratorID: moderatorID,
+		Action:      action,
+		Note:        note,
+		CreatedAt:   time.Now(),
+	})
+	return err
+}
+
+// reportTargetCollection 举报对象所在的集合
+func reportTargetCollection(targetType string) string {
+	switch targetType {
+	case model.ReportTargetVideo:
+		return "videos"
+	case model.ReportTargetComment:
+		return "comments"
+	case model.ReportTargetMark:
+		return "marks"
+	default:
+		return "users"
+	}
+}
+
+// reportTargetOwner 获取被举报内容的作者，举报用户时返回该用户ID
+func reportTargetOwner(ctx context.Context, targetType, targetID string) (string, error) {
+	objectID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return "", ErrReportTargetNotFound
+	}
+
+	var target struct {
+		UserID string `bson:"user_id"`
+	}
+	err = database.GetCollection(reportTargetCollection(targetType)).FindOne(ctx,
+		bson.M{"_id": objectID},
+		options.FindOne().SetProjection(bson.M{"user_id": 1}),
+	).Decode(&target)
+	if err == mongo.ErrNoDocuments {
+		return "", ErrReportTargetNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if targetType == model.ReportTargetUser {
+		return targetID, nil
+	}
+	return target.UserID, nil
+}
+
+// hideReportTarget 隐藏被举报的内容：视频改为已下架，评论和标记标记为隐藏，用户被禁用。
+// 返回视频被下架前的状态，用于驳回时恢复
+func hideReportTarget(ctx context.Context, targetType, targetID string) (string, error) {
+	objectID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return "", ErrReportTargetNotFound
+	}
+	collection := database.GetCollection(reportTargetCollection(targetType))
+
+	switch targetType {
+	case model.ReportTargetVideo:
+		var video model.Video
+		err := collection.FindOneAndUpdate(ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$set": bson.M{"status": model.VideoStatusBlocked, "updated_at": time.Now()}},
+		).Decode(&video)
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		if err != nil || video.Status == model.VideoStatusBlocked {
+			return "", err
+		}
+		prevStatus := video.Status
+		video.Status = model.VideoStatusBlocked
+		publishVideoChanged(video, VideoFieldStatus)
+		return prevStatus, nil
+	case model.ReportTargetUser:
+		if _, err := collection.UpdateOne(ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$set": bson.M{"status": model.UserStatusDisabled, "updated_at": time.Now()}},
+		); err != nil {
+			return "", err
+		}
+		invalidateUserStatus(ctx, targetID)
+		disconnectUser(ctx, targetID)
+		return "", nil
+	default:
+		_, err := collection.UpdateOne(ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$set": bson.M{"hidden": true}},
+		)
+		return "", err
+	}
+}
+
+// disconnectUser 关闭被禁用用户在所有实例上的通知、私信和协作标注房间连接，断开失败只记录日志
+func disconnectUser(ctx context.Context, userID string) {
+	msg := ws.Message{Type: "disabled", Data: bson.M{"message": ErrUserDisabled.Error()}}
+	if err := notificationRelay.Disconnect(ctx, userID, msg); err != nil {
+		slog.Error("[disconnectUser] 断开通知连接失败", "error", err, "userId", userID)
+	}
+	if err := imRelay.Disconnect(ctx, userID, msg); err != nil {
+		slog.Error("[disconnectUser] 断开私信连接失败", "error", err, "userId", userID)
+	}
+
+	cursor, err := database.GetCollection("annotation_rooms").Find(ctx,
+		bson.M{"members": userID},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		slog.Error("[disconnectUser] 查询用户所在房间失败", "error", err, "userId", userID)
+		return
+	}
+	var rooms []model.AnnotationRoom
+	if err := cursor.All(ctx, &rooms); err != nil {
+		slog.Error("[disconnectUser] 查询用户所在房间失败", "error", err, "userId", userID)
+		return
+	}
+	for _, room := range rooms {
+		roomID := room.ID.Hex()
+		if err := roomRelay.Disconnect(ctx, roomMemberChannel(roomID, userID), msg); err != nil {
+			slog.Error("[disconnectUser] 断开房间连接失败", "error", err, "roomId", roomID, "userId", userID)
+		}
+		if err := roomPresence.remove(ctx, roomID, userID); err != nil {
+			slog.Warn("[disconnectUser] 清除在线状态失败", "error", err, "roomId", roomID, "userId", userID)
+		}
+	}
+}
+
+// restoreReportTarget 恢复被隐藏的内容，视频恢复为下架前的状态
+func restoreReportTarget(ctx context.Context, targetType, targetID, prevStatus string) error {
+	objectID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return ErrReportTargetNotFound
+	}
+	collection := database.GetCollection(reportTargetCollection(targetType))
+
+	switch targetType {
+	case model.ReportTargetVideo:
+		if prevStatus == "" {
+			prevStatus = model.VideoStatusPrivate
+		}
+		var video model.Video
+		err := collection.FindOneAndUpdate(ctx,
+			bson.M{"_id": objectID, "status": model.VideoStatusBlocked},
+			bson.M{"$set": bson.M{"status": prevStatus, "updated_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&video)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		publishVideoChanged(video, VideoFieldStatus)
+		return nil
+	case model.ReportTargetUser:
+		if _, err := collection.UpdateOne(ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$set": bson.M{"status": model.UserStatusNormal, "updated_at": time.Now()}},
+		); err != nil {
+			return err
+		}
+		invalidateUserStatus(ctx, targetID)
+		return nil
+	default:
+		_, err := collection.UpdateOne(ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$unset": bson.M{"hidden": ""}},
+		)
+		return err
+	}
+}
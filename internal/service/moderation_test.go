@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"video-platform/config"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试自动隐藏的条件
+func TestShouldAutoHide(t *testing.T) {
+	original := config.GlobalConfig.Moderation
+	defer func() { config.GlobalConfig.Moderation = original }()
+	config.GlobalConfig.Moderation.HideThreshold = 3
+
+	pending := func(targetType string, count int64) *model.ModerationCase {
+		return &model.ModerationCase{
+			TargetType:  targetType,
+			Status:      model.ModerationStatusPending,
+			ReportCount: count,
+		}
+	}
+
+	assert.False(t, shouldAutoHide(pending(model.ReportTargetVideo, 2)))
+	assert.True(t, shouldAutoHide(pending(model.ReportTargetVideo, 3)))
+	assert.True(t, shouldAutoHide(pending(model.ReportTargetComment, 5)))
+
+	// 用户不会被自动禁用
+	assert.False(t, shouldAutoHide(pending(model.ReportTargetUser, 10)))
+
+	// 已隐藏或已被审核员领取
+	hidden := pending(model.ReportTargetVideo, 3)
+	hidden.Hidden = true
+	assert.False(t, shouldAutoHide(hidden))
+	claimed := pending(model.ReportTargetVideo, 3)
+	claimed.Status = model.ModerationStatusClaimed
+	assert.False(t, shouldAutoHide(claimed))
+
+	// 阈值为0时不自动隐藏
+	config.GlobalConfig.Moderation.HideThreshold = 0
+	assert.False(t, shouldAutoHide(pending(model.ReportTargetVideo, 100)))
+}
+
+// 测试举报对象和原因的校验
+func TestReportValidation(t *testing.T) {
+	assert.True(t, model.IsValidReportTarget(model.ReportTargetMark))
+	assert.False(t, model.IsValidReportTarget("note"))
+	assert.True(t, model.IsValidReportReason(model.ReportReasonCopyright))
+	assert.False(t, model.IsValidReportReason("unknown"))
+
+	assert.Equal(t, "videos", reportTargetCollection(model.ReportTargetVideo))
+	assert.Equal(t, "comments", reportTargetCollection(model.ReportTargetComment))
+	assert.Equal(t, "marks", reportTargetCollection(model.ReportTargetMark))
+	assert.Equal(t, "users", reportTargetCollection(model.ReportTargetUser))
+}
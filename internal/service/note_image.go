@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/markdown"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 笔记图片相关错误
+var (
+	ErrNoteImageTooLarge = errors.New("图片大小不能超过5MB")
+	ErrNoteImageFormat   = errors.New("不支持的图片格式")
+	ErrNoteTooLong       = errors.New("笔记内容不能超过20000字")
+)
+
+const (
+	// noteImageMaxSize 笔记图片大小上限
+	noteImageMaxSize = 5 * 1024 * 1024
+	// noteContentLimit 笔记内容的最大字数
+	noteContentLimit = 20000
+	// noteImageOrphanTTL 上传后超过该时间仍未保存到笔记的图片会被清理
+	noteImageOrphanTTL = 24 * time.Hour
+)
+
+// noteImagePattern 笔记内容中引用的本站笔记图片
+var noteImagePattern = regexp.MustCompile(`/uploads/(note_[0-9a-f]{24}\.(?:jpg|jpeg|png|gif))`)
+
+// imageContentTypes 图片扩展名对应的实际文件类型
+var imageContentTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+}
+
+// UploadNoteImage 上传笔记图片，返回可以插入Markdown的图片地址
+func (s *markServiceImpl) UploadNoteImage(ctx context.Context, userID string, file *multipart.FileHeader) (*model.NoteImage, error) {
+	if file.Size > noteImageMaxSize {
+		return nil, ErrNoteImageTooLarge
+	}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !isValidImageFormat(ext) {
+		return nil, ErrNoteImageFormat
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	// 根据文件内容判断类型，防止把其他文件改扩展名后上传
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, ErrNoteImageFormat
+	}
+	if http.DetectContentType(head[:n]) != imageContentTypes[ext] {
+		return nil, ErrNoteImageFormat
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(config.GlobalConfig.Storage.UploadDir, 0755); err != nil {
+		return nil, err
+	}
+	image := &model.NoteImage{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Size:      file.Size,
+		CreatedAt: time.Now(),
+	}
+	image.FileName = fmt.Sprintf("note_%s%s", image.ID.Hex(), ext)
+	image.URL = "/uploads/" + image.FileName
+	filePath := filepath.Join(config.GlobalConfig.Storage.UploadDir, image.FileName)
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		os.Remove(filePath) // 清理失败的文件
+		return nil, err
+	}
+
+	if _, err := database.GetCollection("note_images").InsertOne(ctx, image); err != nil {
+		os.Remove(filePath) // 清理文件
+		return nil, err
+	}
+	return image, nil
+}
+
+// noteImageFileNames 提取笔记内容中引用的笔记图片文件名
+func noteImageFileNames(content string) []string {
+	var names []string
+	for _, m := range noteImagePattern.FindAllStringSubmatch(content, -1) {
+		names = append(names, m[1])
+	}
+	return uniqueStrings(names)
+}
+
+// attachNoteImages 把笔记内容中引用的、由 userID（笔记作者或修改笔记的房间成员）上传且尚未关联的图片关联到笔记。
+// 不再引用的图片仍然保留，以便恢复历史版本时图片可用，删除笔记时一并删除
+func attachNoteImages(ctx context.Context, userID string, note *model.Note) {
+	names := noteImageFileNames(note.Content)
+	if len(names) == 0 {
+		return
+	}
+	_, err := database.GetCollection("note_images").UpdateMany(ctx,
+		bson.M{"user_id": userID, "file_name": bson.M{"$in": names}, "note_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"note_id": note.ID}},
+	)
+	if err != nil {
+		slog.Warn("[attachNoteImages] 关联笔记图片失败", "error", err, "noteId", note.ID.Hex())
+	}
+}
+
+// deleteNoteImages 删除符合条件的笔记图片及其文件，返回删除的图片数
+func deleteNoteImages(ctx context.Context, filter bson.M) (int64, error) {
+	images, err := deleteNoteImageRecords(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	removeNoteImageFiles(images)
+	return int64(len(images)), nil
+}
+
+// deleteNoteImageRecords 只删除符合条件的图片记录并返回这些图片，
+// 在事务中使用时由调用方在提交后调用 removeNoteImageFiles 删除文件
+func deleteNoteImageRecords(ctx context.Context, filter bson.M) ([]model.NoteImage, error) {
+	collection := database.GetCollection("note_images")
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var images []model.NoteImage
+	if err := cursor.All(ctx, &images); err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(images))
+	for _, image := range images {
+		ids = append(ids, image.ID)
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// removeNoteImageFiles 删除图片文件，失败时只记录日志
+func removeNoteImageFiles(images []model.NoteImage) {
+	for _, image := range images {
+		path := filepath.Join(config.GlobalConfig.Storage.UploadDir, image.FileName)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			slog.Warn("[removeNoteImageFiles] 删除图片文件失败", "error", err, "path", path)
+		}
+	}
+}
+
+// CleanupNoteImages 删除上传后超过24小时仍未保存到笔记的图片，建议每天执行
+func CleanupNoteImages(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	filter := bson.M{
+		"note_id":    bson.M{"$exists": false},
+		"created_at": bson.M{"$lt": time.Now().Add(-noteImageOrphanTTL)},
+	}
+	count, err := database.GetCollection("note_images").CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrationResult{Scanned: count, Fixed: count}
+	if dryRun {
+		return result, nil
+	}
+	result.Fixed, err = deleteNoteImages(ctx, filter)
+	return result, err
+}
+
+// checkNoteContent 检查笔记内容长度
+func checkNoteContent(content string) error {
+	if len([]rune(content)) > noteContentLimit {
+		return ErrNoteTooLong
+	}
+	return nil
+}
+
+// renderNotes 为笔记生成过滤后的HTML
+func renderNotes(notes []model.Note) {
+	for i := range notes {
+		notes[i].ContentHTML = markdown.Render(notes[i].Content)
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试从笔记内容中提取引用的笔记图片
+func TestNoteImageFileNames(t *testing.T) {
+	content := "![图1](/uploads/note_65f1a2b3c4d5e6f7a8b9c0d1.png)\n" +
+		"![重复](http://example.com/uploads/note_65f1a2b3c4d5e6f7a8b9c0d1.png)\n" +
+		"<img src=\"/uploads/note_65f1a2b3c4d5e6f7a8b9c0d2.jpeg\">\n" +
+		"![封面](/uploads/cover_65f1a2b3c4d5e6f7a8b9c0d3.png)\n" +
+		"![脚本](/uploads/note_65f1a2b3c4d5e6f7a8b9c0d4.svg)"
+
+	assert.Equal(t, []string{
+		"note_65f1a2b3c4d5e6f7a8b9c0d1.png",
+		"note_65f1a2b3c4d5e6f7a8b9c0d2.jpeg",
+	}, noteImageFileNames(content))
+	assert.Empty(t, noteImageFileNames("没有图片的笔记"))
+}
+
+// 测试笔记内容长度限制按字数计算
+func TestCheckNoteContent(t *testing.T) {
+	assert.NoError(t, checkNoteContent(strings.Repeat("字", noteContentLimit)))
+	assert.ErrorIs(t, checkNoteContent(strings.Repeat("字", noteContentLimit+1)), ErrNoteTooLong)
+}
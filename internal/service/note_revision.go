@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/markdown"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ListNoteRevisions 获取笔记的历史版本列表，按版本倒序，不包含内容
+func (s *markServiceImpl) ListNoteRevisions(ctx context.Context, userID string, noteID primitive.ObjectID) ([]model.NoteRevision, error) {
+	if _, err := findEditableNote(ctx, userID, noteID); err != nil {
+		return nil, err
+	}
+
+	cursor, err := database.GetCollection("note_revisions").Find(ctx, bson.M{"note_id": noteID},
+		options.Find().
+			SetSort(bson.D{{Key: "version", Value: -1}}).
+			SetProjection(bson.M{"content": 0}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	revisions := []model.NoteRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetNoteRevision 获取笔记的某个历史版本
+func (s *markServiceImpl) GetNoteRevision(ctx context.Context, userID string, noteID primitive.ObjectID, version int) (*model.NoteRevision, error) {
+	if _, err := findEditableNote(ctx, userID, noteID); err != nil {
+		return nil, err
+	}
+	revision, err := findNoteRevision(ctx, noteID, version)
+	if err != nil {
+		return nil, err
+	}
+	revision.ContentHTML = markdown.Render(revision.Content)
+	return revision, nil
+}
+
+// RestoreNoteRevision 把笔记的内容和时间戳恢复到某个历史版本，恢复本身也会产生一个新版本。
+// 可见范围属于访问控制，不随历史版本恢复
+func (s *markServiceImpl) RestoreNoteRevision(ctx context.Context, userID string, noteID primitive.ObjectID, version int) (*model.Note, error) {
+	current, err := findEditableNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := findNoteRevision(ctx, noteID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{
+		"content":    revision.Content,
+		"timestamp":  revision.Timestamp,
+		"updated_at": time.Now(),
+	}
+	note, err := saveNoteVersion(ctx, current, userID, set, nil, model.NoteRevisionRestore, version)
+	if err != nil {
+		return nil, err
+	}
+	note.ContentHTML = markdown.Render(note.Content)
+	publishRoomEvent(ctx, note.RoomID, "note.updated", note)
+	return note, nil
+}
+
+// findEditableNote 查找当前用户可以编辑的笔记：自己的笔记，或自己所在房间内的笔记
+func findEditableNote(ctx context.Context, userID string, noteID primitive.ObjectID) (*model.Note, error) {
+	var note model.Note
+	err := database.GetCollection("notes").FindOne(ctx, bson.M{"_id": noteID}).Decode(&note)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if note.UserID == userID {
+		return &note, nil
+	}
+	if note.RoomID != "" {
+		member, err := isRoomMember(ctx, userID, note.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		if member {
+			return &note, nil
+		}
+	}
+	return nil, ErrNoteNotFound
+}
+
+// findNoteRevision 查找笔记的某个历史版本
+func findNoteRevision(ctx context.Context, noteID primitive.ObjectID, version int) (*model.NoteRevision, error) {
+	var revision model.NoteRevision
+	err := database.GetCollection("note_revisions").FindOne(ctx, bson.M{"note_id": noteID, "version": version}).Decode(&revision)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNoteRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// saveNoteVersion 以 current 的版本号为条件更新笔记并记录新版本，版本号不一致说明笔记已被并发修改。
+// 没有历史记录的旧笔记（版本为0）先补记修改前的内容作为版本1。editorID 为修改人
+func saveNoteVersion(ctx context.Context, current *model.Note, editorID string, set, unset bson.M, action string, restoredFrom int) (*model.Note, error) {
+	filter := bson.M{"_id": current.ID, "user_id": current.UserID, "version": current.Version}
+	next := current.Version + 1
+	var revisions []model.NoteRevision
+	if current.Version == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+		initial := noteRevision(current, model.NoteRevisionInitial, 0)
+		initial.Version = 1
+		initial.CreatedAt = current.UpdatedAt
+		revisions = append(revisions, initial)
+		next = 2
+	}
+
+	set["version"] = next
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var updated model.Note
+	err := database.GetCollection("notes").FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNoteConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	revision := noteRevision(&updated, action, restoredFrom)
+	if editorID != updated.UserID {
+		revision.EditedBy = editorID
+	}
+	revisions = append(revisions, revision)
+	if err := insertNoteRevisions(ctx, revisions...); err != nil {
+		return nil, err
+	}
+	if _, err := compactNoteRevisions(ctx, updated.ID, false); err != nil {
+		slog.Warn("[saveNoteVersion] 清理笔记历史版本失败", "error", err, "noteId", updated.ID.Hex())
+	}
+	return &updated, nil
+}
+
+// noteRevision 根据笔记当前内容生成历史版本
+func noteRevision(note *model.Note, action string, restoredFrom int) model.NoteRevision {
+	return model.NoteRevision{
+		ID:           primitive.NewObjectID(),
+		NoteID:       note.ID,
+		UserID:       note.UserID,
+		VideoID:      note.VideoID,
+		Version:      note.Version,
+		Action:       action,
+		RestoredFrom: restoredFrom,
+		Content:      note.Content,
+		Timestamp:    note.Timestamp,
+		CreatedAt:    note.UpdatedAt,
+	}
+}
+
+// insertNoteRevisions 写入历史版本
+func insertNoteRevisions(ctx context.Context, revisions ...model.NoteRevision) error {
+	docs := make([]interface{}, 0, len(revisions))
+	for _, r := range revisions {
+		docs = append(docs, r)
+	}
+	_, err := database.GetCollection("note_revisions").InsertMany(ctx, docs)
+	return err
+}
+
+// compactRevisions 按保留策略返回需要删除的历史版本，revisions 需按版本倒序排列：
+// 最新版本始终保留；keepDays 天内的版本全部保留，更早的每天只保留当天最后一个版本；
+// 保留的版本数超过 max 时删除最旧的版本。keepDays 或 max 为0时不做对应的清理
+func compactRevisions(revisions []model.NoteRevision, now time.Time, keepDays, max int) []primitive.ObjectID {
+	var removed []primitive.ObjectID
+	kept := 0
+	cutoff := now.AddDate(0, 0, -keepDays)
+	days := make(map[string]bool)
+	for _, r := range revisions {
+		if keepDays > 0 && r.CreatedAt.Before(cutoff) {
+			day := r.CreatedAt.Local().Format("2006-01-02")
+			if days[day] {
+				removed = append(removed, r.ID)
+				continue
+			}
+			days[day] = true
+		}
+		if max > 0 && kept >= max {
+			removed = append(removed, r.ID)
+			continue
+		}
+		kept++
+	}
+	return removed
+}
+
+// compactNoteRevisions 按配置的保留策略清理一条笔记的历史版本，返回需要删除（或已删除）的版本数
+func compactNoteRevisions(ctx context.Context, noteID primitive.ObjectID, dryRun bool) (int, error) {
+	collection := database.GetCollection("note_revisions")
+	cursor, err := collection.Find(ctx, bson.M{"note_id": noteID},
+		options.Find().
+			SetSort(bson.D{{Key: "version", Value: -1}}).
+			SetProjection(bson.M{"_id": 1, "version": 1, "created_at": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var revisions []model.NoteRevision
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return 0, err
+	}
+
+	cfg := config.GlobalConfig.Note
+	removed := compactRevisions(revisions, time.Now(), int(cfg.RevisionKeepDays), int(cfg.RevisionMax))
+	if len(removed) == 0 || dryRun {
+		return len(removed), nil
+	}
+	_, err = collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": removed}})
+	return len(removed), err
+}
+
+// CompactNoteRevisions 按保留策略清理所有笔记的历史版本，建议每天执行
+func CompactNoteRevisions(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	noteIDs, err := database.GetCollection("note_revisions").Distinct(ctx, "note_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{}
+	for _, value := range noteIDs {
+		noteID, ok := value.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		result.Scanned++
+		removed, err := compactNoteRevisions(ctx, noteID, dryRun)
+		result.Fixed += int64(removed)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
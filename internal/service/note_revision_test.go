@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newRevisions 按版本倒序生成历史版本，ages 为每个版本距今的时长
+func newRevisions(now time.Time, ages ...time.Duration) []model.NoteRevision {
+	revisions := make([]model.NoteRevision, 0, len(ages))
+	for i, age := range ages {
+		revisions = append(revisions, model.NoteRevision{
+			ID:        primitive.NewObjectID(),
+			Version:   len(ages) - i,
+			CreatedAt: now.Add(-age),
+		})
+	}
+	return revisions
+}
+
+// 测试历史版本保留策略
+func TestCompactRevisions(t *testing.T) {
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+
+	// 最近的版本全部保留，更早的每天只保留最后一个
+	revisions := newRevisions(now, time.Hour, 2*time.Hour, 10*day, 10*day+time.Hour, 10*day+2*time.Hour, 11*day)
+	removed := compactRevisions(revisions, now, 7, 0)
+	assert.Equal(t, []primitive.ObjectID{revisions[3].ID, revisions[4].ID}, removed)
+
+	// 超过数量上限时删除最旧的版本
+	removed = compactRevisions(revisions, now, 0, 4)
+	assert.Equal(t, []primitive.ObjectID{revisions[4].ID, revisions[5].ID}, removed)
+
+	// 最新版本即使很旧也始终保留
+	revisions = newRevisions(now, 30*day, 30*day+time.Hour)
+	assert.Equal(t, []primitive.ObjectID{revisions[1].ID}, compactRevisions(revisions, now, 7, 0))
+
+	assert.Empty(t, compactRevisions(revisions, now, 0, 0))
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+	"unicode/utf8"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/ws"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotificationNotFound 通知不存在
+var ErrNotificationNotFound = errors.New("通知不存在")
+
+const (
+	notificationMaxActors    = 10              // 聚合通知保留的最近触发者数量
+	notificationContentLimit = 50              // 内容摘要最大字符数
+	notificationMaxMentions  = 10              // 单条评论最多通知的@用户数
+	notificationTimeout      = 5 * time.Second // 异步发送通知的超时时间
+)
+
+// mentionPattern 匹配 @用户名
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}_-]+)`)
+
+// notificationRelay 通知推送的连接管理，所有通知服务实例共享，多实例部署时通过Redis转发
+var notificationRelay = ws.NewRelay(ws.NewHub(), "notifications")
+
+// NotificationService 通知服务接口
+type NotificationService interface {
+	Notify(ctx context.Context, n *model.Notification) error
+	List(ctx context.Context, userID string, query model.NotificationQuery) (*model.NotificationListResponse, error)
+	UnreadCount(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID string, ids []string) (int64, error)
+	Delete(ctx context.Context, userID, notificationID string) error
+	Subscribe(userID string, client *ws.Client)
+	Unsubscribe(userID string, client *ws.Client)
+}
+
+type notificationService struct {
+	collection string
+	relay      *ws.Relay
+}
+
+// NewNotificationService 创建通知服务实例，relay 为空时使用共享的推送连接
+func NewNotificationService(relay *ws.Relay) NotificationService {
+	if relay == nil {
+		relay = notificationRelay
+	}
+	return &notificationService{
+		collection: "notifications",
+		relay:      relay,
+	}
+}
+
+// Notify 发送通知：聚合类型的通知合并到同一对象的未读通知中，其余类型单独保存
+func (s *notificationService) Notify(ctx context.Context, n *model.Notification) error {
+	if n.UserID == "" || !model.IsValidNotificationType(n.Type) {
+		return errors.New("无效的通知")
+	}
+
+	now := time.Now()
+	n.Content = truncateRunes(n.Content, notificationContentLimit)
+	collection := database.GetCollection(s.collection)
+
+	if model.IsAggregatedNotification(n.Type) && len(n.ActorIDs) > 0 {
+		actorID := n.ActorIDs[0]
+		actors := bson.M{"$ifNull": bson.A{"$actor_ids", bson.A{}}}
+		count := bson.M{"$ifNull": bson.A{"$actor_count", 0}}
+
+		// 同一触发者重复触发时只移到最前，不重复计数
+		update := mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"actor_count": bson.M{"$cond": bson.A{
+					bson.M{"$in": bson.A{actorID, actors}},
+					count,
+					bson.M{"$add": bson.A{count, 1}},
+				}},
+				"actor_ids": bson.M{"$slice": bson.A{
+					bson.M{"$concatArrays": bson.A{
+						bson.A{actorID},
+						bson.M{"$filter": bson.M{
+							"input": actors,
+							"cond":  bson.M{"$ne": bson.A{"$$this", actorID}},
+						}},
+					}},
+					notificationMaxActors,
+				}},
+				"content":    n.Content,
+				"aggregated": true,
+				"created_at": bson.M{"$ifNull": bson.A{"$created_at", now}},
+				"updated_at": now,
+			}}},
+		}
+
+		filter := bson.M{
+			"user_id":     n.UserID,
+			"type":        n.Type,
+			"target_type": n.TargetType,
+			"target_id":   n.TargetID,
+			"read":        false,
+		}
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+		err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(n)
+		if mongo.IsDuplicateKeyError(err) {
+			// 并发触发时另一个请求已经创建了未读通知，唯一索引拒绝重复插入后合并到该通知
+			err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(n)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		n.ID = primitive.NewObjectID()
+		n.ActorCount = int64(len(n.ActorIDs))
+		if n.ActorIDs == nil {
+			n.ActorIDs = []string{}
+		}
+		n.Read = false
+		n.CreatedAt = now
+		n.UpdatedAt = now
+		if _, err := collection.InsertOne(ctx, n); err != nil {
+			return err
+		}
+	}
+
+	s.push(ctx, n)
+	return nil
+}
+
+// push 推送通知给在线的接收者
+func (s *notificationService) push(ctx context.Context, n *model.Notification) {
+	items, err := s.buildItems(ctx, []model.Notification{*n})
+	if err != nil {
+		slog.Warn("构建通知推送内容失败", "error", err, "userId", n.UserID)
+		return
+	}
+	if err := s.relay.Publish(ctx, n.UserID, ws.Message{Type: "notification", Data: items[0]}); err != nil {
+		slog.Warn("推送通知失败", "error", err, "userId", n.UserID)
+	}
+}
+
+// List 获取通知列表，按最近触发时间倒序
+func (s *notificationService) List(ctx context.Context, userID string, query model.NotificationQuery) (*model.NotificationListResponse, error) {
+	collection := database.GetCollection(s.collection)
+
+	// 设置默认分页参数
+	page, size := query.Page, query.Size
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	filter := bson.M{"user_id": userID}
+	if query.Type != "" {
+		filter["type"] = query.Type
+	}
+	if query.Unread {
+		filter["read"] = false
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.UnreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []model.Notification
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+
+	items, err := s.buildItems(ctx, notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NotificationListResponse{
+		Notifications: items,
+		Total:         total,
+		Unread:        unread,
+		Page:          page,
+		Size:          size,
+	}, nil
+}
+
+// UnreadCount 获取未读通知数
+func (s *notificationService) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	return database.GetCollection(s.collection).CountDocuments(ctx, bson.M{"user_id": userID, "read": false})
+}
+
+// MarkRead 将通知标记为已读，ids 为空时全部标记为已读
+func (s *notificationService) MarkRead(ctx context.Context, userID string, ids []string) (int64, error) {
+	filter := bson.M{"user_id": userID, "read": false}
+	if len(ids) > 0 {
+		objectIDs := make([]primitive.ObjectID, 0, len(ids))
+		for _, id := range ids {
+			objectID, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				return 0, fmt.Errorf("无效的通知ID: %s", id)
+			}
+			objectIDs = append(objectIDs, objectID)
+		}
+		filter["_id"] = bson.M{"$in": objectIDs}
+	}
+
+	result, err := database.GetCollection(s.collection).UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"read": true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// Delete 删除通知
+func (s *notificationService) Delete(ctx context.Context, userID, notificationID string) error {
+	objectID, err := primitive.ObjectIDFromHex(notificationID)
+	if err != nil {
+		return ErrNotificationNotFound
+	}
+	result, err := database.GetCollection(s.collection).DeleteOne(ctx, bson.M{"_id": objectID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// Subscribe 订阅当前用户的实时通知
+func (s *notificationService) Subscribe(userID string, client *ws.Client) {
+	s.relay.Join(userID, client)
+}
+
+// Unsubscribe 取消订阅
+func (s *notificationService) Unsubscribe(userID string, client *ws.Client) {
+	s.relay.Leave(userID, client)
+}
+
+// buildItems 组装通知列表项（触发者信息和展示文案）
+func (s *notificationService) buildItems(ctx context.Context, notifications []model.Notification) ([]model.NotificationItem, error) {
+	items := make([]model.NotificationItem, 0, len(notifications))
+	if len(notifications) == 0 {
+		return items, nil
+	}
+
+	actorIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, n := range notifications {
+		for _, id := range n.ActorIDs {
+			if !seen[id] {
+				seen[id] = true
+				actorIDs = append(actorIDs, id)
+			}
+		}
+	}
+	briefs, err := loadUserBriefs(ctx, actorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range notifications {
+		actors := make([]model.UserBrief, 0, len(n.ActorIDs))
+		for _, id := range n.ActorIDs {
+			actors = append(actors, briefs[id])
+		}
+		items = append(items, model.NotificationItem{
+			Notification: n,
+			Actors:       actors,
+			Message:      notificationMessage(&n, actors),
+		})
+	}
+	return items, nil
+}
+
+// notificationMessage 生成通知的展示文案
+func notificationMessage(n *model.Notification, actors []model.UserBrief) string {
+	if n.Type == model.NotificationTypeSystem {
+		return n.Content
+	}
+
+	who := "有人"
+	if len(actors) > 0 {
+		who = actors[0].Nickname
+		if who == "" {
+			who = actors[0].Username
+		}
+		if n.ActorCount > 1 {
+			who = fmt.Sprintf("%s等%d人", who, n.ActorCount)
+		}
+	}
+
+	switch n.Type {
+	case model.NotificationTypeLike:
+		return who + "赞了你的视频"
+	case model.NotificationTypeFavorite:
+		return who + "收藏了你的视频"
+	case model.NotificationTypeFollow:
+		return who + "关注了你"
+	case model.NotificationTypeComment:
+		if n.TargetType == model.NotificationTargetComment {
+			return who + "回复了你的评论"
+		}
+		return who + "评论了你的视频"
+	case model.NotificationTypeAnnotation:
+		return who + "注释了你的标记"
+	case model.NotificationTypeMention:
+		return who + "在评论中提到了你"
+	default:
+		return n.Content
+	}
+}
+
+// notifyAsync 异步发送通知，给自己的操作不发送，失败只记录日志不影响主流程
+func notifyAsync(n model.Notification) {
+	if n.UserID == "" {
+		return
+	}
+	for _, actorID := range n.ActorIDs {
+		if actorID == n.UserID {
+			return
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+		defer cancel()
+		if err := NewNotificationService(nil).Notify(ctx, &n); err != nil {
+			slog.Warn("发送通知失败", "error", err, "userId", n.UserID, "type", n.Type)
+		}
+	}()
+}
+
+// notifyMentions 异步给内容中@到的用户发送通知，skip 中的用户不重复通知
+func notifyMentions(actorID, content, targetType, targetID string, skip map[string]bool) {
+	usernames := parseMentions(content)
+	if len(usernames) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+		defer cancel()
+
+		cursor, err := database.GetCollection("users").Find(ctx,
+			bson.M{"username": bson.M{"$in": usernames}},
+			options.Find().SetProjection(bson.M{"_id": 1}),
+		)
+		if err != nil {
+			slog.Warn("查询被提及的用户失败", "error", err)
+			return
+		}
+		var users []model.User
+		if err := cursor.All(ctx, &users); err != nil {
+			slog.Warn("查询被提及的用户失败", "error", err)
+			return
+		}
+
+		for _, u := range users {
+			if skip[u.ID.Hex()] {
+				continue
+			}
+			notifyAsync(model.Notification{
+				UserID:     u.ID.Hex(),
+				Type:       model.NotificationTypeMention,
+				TargetType: targetType,
+				TargetID:   targetID,
+				ActorIDs:   []string{actorID},
+				Content:    content,
+			})
+		}
+	}()
+}
+
+// parseMentions 解析内容中@到的用户名（去重）
+func parseMentions(content string) []string {
+	matches := mentionPattern.FindAllStringSubmatch(content, -1)
+	usernames := make([]string, 0, len(matches))
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		usernames = append(usernames, m[1])
+		if len(usernames) >= notificationMaxMentions {
+			break
+		}
+	}
+	return usernames
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit]) + "…"
+}
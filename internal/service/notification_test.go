@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试聚合通知的展示文案
+func TestNotificationMessageAggregated(t *testing.T) {
+	n := &model.Notification{Type: model.NotificationTypeFavorite, ActorCount: 12}
+	actors := []model.UserBrief{{ID: "u1", Username: "zhangsan", Nickname: "张三"}}
+
+	assert.Equal(t, "张三等12人收藏了你的视频", notificationMessage(n, actors))
+
+	// 只有一个人时不显示人数，没有昵称时使用用户名
+	n = &model.Notification{Type: model.NotificationTypeFollow, ActorCount: 1}
+	actors = []model.UserBrief{{ID: "u1", Username: "zhangsan"}}
+	assert.Equal(t, "zhangsan关注了你", notificationMessage(n, actors))
+}
+
+// 测试评论与回复通知的展示文案
+func TestNotificationMessageComment(t *testing.T) {
+	actors := []model.UserBrief{{ID: "u1", Nickname: "张三"}}
+
+	n := &model.Notification{Type: model.NotificationTypeComment, TargetType: model.NotificationTargetVideo, ActorCount: 1}
+	assert.Equal(t, "张三评论了你的视频", notificationMessage(n, actors))
+
+	n.TargetType = model.NotificationTargetComment
+	assert.Equal(t, "张三回复了你的评论", notificationMessage(n, actors))
+
+	n = &model.Notification{Type: model.NotificationTypeSystem, Content: "系统维护通知"}
+	assert.Equal(t, "系统维护通知", notificationMessage(n, nil))
+}
+
+// 测试@用户名解析
+func TestParseMentions(t *testing.T) {
+	mentions := parseMentions("@张三 说得对，@lisi_01 你看看 @张三")
+	assert.Equal(t, []string{"张三", "lisi_01"}, mentions)
+
+	assert.Empty(t, parseMentions("没有提到任何人"))
+}
+
+// 测试按字符截断
+func TestTruncateRunes(t *testing.T) {
+	assert.Equal(t, "你好", truncateRunes("你好", 5))
+	assert.Equal(t, "你好世…", truncateRunes("你好世界", 3))
+}
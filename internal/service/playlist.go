@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrPlaylistNotFound     = errors.New("播放列表不存在")
+	ErrPlaylistForbidden    = errors.New("无权操作该播放列表")
+	ErrPlaylistItemExists   = errors.New("视频已在播放列表中")
+	ErrPlaylistItemNotFound = errors.New("视频不在播放列表中")
+	ErrPlaylistFull         = errors.New("播放列表中的视频数已达上限")
+	ErrInvalidPlaylistOrder = errors.New("排序需要包含播放列表中的全部视频")
+)
+
+// playlistMaxItems 每个播放列表最多包含的视频数
+const playlistMaxItems = 500
+
+// PlaylistService 播放列表服务接口
+type PlaylistService interface {
+	Create(ctx context.Context, userID string, req *model.CreatePlaylistRequest) (*model.Playlist, error)
+	Update(ctx context.Context, userID, playlistID string, req *model.UpdatePlaylistRequest) (*model.Playlist, error)
+	Delete(ctx context.Context, userID, playlistID string) error
+	Get(ctx context.Context, viewerID, playlistID string) (*model.PlaylistDetail, error)
+	ListByUser(ctx context.Context, viewerID, ownerID string, page, size int) (*model.PlaylistListResponse, error)
+	AddItem(ctx context.Context, userID, playlistID, videoID string) (*model.PlaylistItem, error)
+	RemoveItem(ctx context.Context, userID, playlistID, videoID string) error
+	Reorder(ctx context.Context, userID, playlistID string, videoIDs []string) error
+	Navigate(ctx context.Context, viewerID, playlistID, videoID string) (*model.PlaylistNavigation, error)
+}
+
+type playlistService struct {
+	collection     string
+	itemCollection string
+}
+
+// NewPlaylistService 创建播放列表服务实例
+func NewPlaylistService() PlaylistService {
+	return &playlistService{
+		collection:     "playlists",
+		itemCollection: "playlist_items",
+	}
+}
+
+// Create 创建播放列表
+func (s *playlistService) Create(ctx context.Context, userID string, req *model.CreatePlaylistRequest) (*model.Playlist, error) {
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = model.PlaylistVisibilityPublic
+	}
+
+	now := time.Now()
+	playlist := &model.Playlist{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		Title:       req.Title,
+		Description: req.Description,
+		Visibility:  visibility,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := database.GetCollection(s.collection).InsertOne(ctx, playlist); err != nil {
+		return nil, err
+	}
+	return playlist, nil
+}
+
+// Update 更新播放列表信息
+func (s *playlistService) Update(ctx context.Context, userID, playlistID string, req *model.UpdatePlaylistRequest) (*model.Playlist, error) {
+	playlist, err := s.findOwned(ctx, userID, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	if req.Title != "" {
+		set["title"] = req.Title
+	}
+	if req.Description != nil {
+		set["description"] = *req.Description
+	}
+	if req.Visibility != "" {
+		set["visibility"] = req.Visibility
+	}
+
+	var updated model.Playlist
+	err = database.GetCollection(s.collection).FindOneAndUpdate(ctx,
+		bson.M{"_id": playlist.ID},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrPlaylistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Delete 删除播放列表及其中的视频记录
+func (s *playlistService) Delete(ctx context.Context, userID, playlistID string) error {
+	playlist, err := s.findOwned(ctx, userID, playlistID)
+	if err != nil {
+		return err
+	}
+
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		if _, err := database.GetCollection(s.itemCollection).DeleteMany(sessCtx, bson.M{"playlist_id": playlistID}); err != nil {
+			return nil, err
+		}
+		_, err := database.GetCollection(s.collection).DeleteOne(sessCtx, bson.M{"_id": playlist.ID})
+		return nil, err
+	})
+	return err
+}
+
+// Get 获取播放列表详情，私有列表只有创建者可以查看
+func (s *playlistService) Get(ctx context.Context, viewerID, playlistID string) (*model.PlaylistDetail, error) {
+	playlist, err := s.findVisible(ctx, viewerID, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.loadItems(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PlaylistDetail{Playlist: *playlist, Items: items}, nil
+}
+
+// ListByUser 获取用户创建的播放列表，其他用户只能看到公开的列表
+func (s *playlistService) ListByUser(ctx context.Context, viewerID, ownerID string, page, size int) (*model.PlaylistListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	filter := bson.M{"user_id": ownerID}
+	if viewerID != ownerID {
+		filter["visibility"] = model.PlaylistVisibilityPublic
+	}
+
+	collection := database.GetCollection(s.collection)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	playlists := []model.Playlist{}
+	if err := cursor.All(ctx, &playlists); err != nil {
+		return nil, err
+	}
+
+	return &model.PlaylistListResponse{
+		Playlists: playlists,
+		Total:     total,
+		Page:      page,
+		Size:      size,
+	}, nil
+}
+
+// AddItem 将视频添加到播放列表末尾
+func (s *playlistService) AddItem(ctx context.Context, userID, playlistID, videoID string) (*model.PlaylistItem, error) {
+	playlist, err := s.findOwned(ctx, userID, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.ItemCount >= playlistMaxItems {
+		return nil, ErrPlaylistFull
+	}
+
+	videoObjectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, errors.New("无效的视频ID")
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": videoObjectID}).Decode(&video); err != nil {
+		return nil, errors.New("视频不存在")
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != userID {
+		return nil, errors.New("无权添加该视频")
+	}
+
+	itemCollection := database.GetCollection(s.itemCollection)
+	exists, err := itemCollection.CountDocuments(ctx, bson.M{"playlist_id": playlistID, "video_id": videoID})
+	if err != nil {
+		return nil, err
+	}
+	if exists > 0 {
+		return nil, ErrPlaylistItemExists
+	}
+
+	// 排在当前最后一个视频之后
+	position := int64(0)
+	var last model.PlaylistItem
+	err = itemCollection.FindOne(ctx,
+		bson.M{"playlist_id": playlistID},
+		options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}}),
+	).Decode(&last)
+	if err == nil {
+		position = last.Position + 1
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	item := &model.PlaylistItem{
+		ID:            primitive.NewObjectID(),
+		PlaylistID:    playlistID,
+		VideoID:       videoID,
+		Position:      position,
+		VideoTitle:    video.Title,
+		CoverURL:      videoCover(video),
+		VideoDuration: video.Duration,
+		AddedAt:       time.Now(),
+	}
+
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		if _, err := itemCollection.InsertOne(sessCtx, item); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, ErrPlaylistItemExists
+			}
+			return nil, err
+		}
+		_, err := database.GetCollection(s.collection).UpdateOne(sessCtx,
+			bson.M{"_id": playlist.ID},
+			bson.M{
+				"$inc": bson.M{"item_count": 1},
+				"$set": bson.M{"updated_at": item.AddedAt},
+			},
+		)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// RemoveItem 从播放列表中移除视频
+func (s *playlistService) RemoveItem(ctx context.Context, userID, playlistID, videoID string) error {
+	playlist, err := s.findOwned(ctx, userID, playlistID)
+	if err != nil {
+		return err
+	}
+
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		result, err := database.GetCollection(s.itemCollection).DeleteOne(sessCtx,
+			bson.M{"playlist_id": playlistID, "video_id": videoID},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if result.DeletedCount == 0 {
+			return nil, ErrPlaylistItemNotFound
+		}
+		_, err = database.GetCollection(s.collection).UpdateOne(sessCtx,
+			bson.M{"_id": playlist.ID, "item_count": bson.M{"$gt": 0}},
+			bson.M{
+				"$inc": bson.M{"item_count": -1},
+				"$set": bson.M{"updated_at": time.Now()},
+			},
+		)
+		return nil, err
+	})
+	return err
+}
+
+// Reorder 按给定的视频ID顺序重新排列播放列表
+func (s *playlistService) Reorder(ctx context.Context, userID, playlistID string, videoIDs []string) error {
+	if _, err := s.findOwned(ctx, userID, playlistID); err != nil {
+		return err
+	}
+
+	items, err := s.loadItems(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+	if !samePlaylistItems(items, videoIDs) {
+		return ErrInvalidPlaylistOrder
+	}
+
+	models := make([]mongo.WriteModel, 0, len(videoIDs))
+	for i, videoID := range videoIDs {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"playlist_id": playlistID, "video_id": videoID}).
+			SetUpdate(bson.M{"$set": bson.M{"position": int64(i)}}))
+	}
+	_, err = database.GetCollection(s.itemCollection).BulkWrite(ctx, models)
+	return err
+}
+
+// Navigate 获取视频在播放列表中的位置及前后视频，视频不在列表中时返回 ErrPlaylistItemNotFound
+func (s *playlistService) Navigate(ctx context.Context, viewerID, playlistID, videoID string) (*model.PlaylistNavigation, error) {
+	playlist, err := s.findVisible(ctx, viewerID, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.loadItems(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	nav := playlistNavigation(items, videoID)
+	if nav == nil {
+		return nil, ErrPlaylistItemNotFound
+	}
+	nav.PlaylistID = playlistID
+	nav.Title = playlist.Title
+	return nav, nil
+}
+
+// playlistNavigation 计算视频在有序列表中的位置及前后视频
+func playlistNavigation(items []model.PlaylistItem, videoID string) *model.PlaylistNavigation {
+	for i := range items {
+		if items[i].VideoID != videoID {
+			continue
+		}
+		nav := &model.PlaylistNavigation{Index: i + 1, Total: len(items)}
+		if i > 0 {
+			nav.Prev = &items[i-1]
+		}
+		if i < len(items)-1 {
+			nav.Next = &items[i+1]
+		}
+		return nav
+	}
+	return nil
+}
+
+// samePlaylistItems 检查排序请求是否恰好包含列表中的全部视频
+func samePlaylistItems(items []model.PlaylistItem, videoIDs []string) bool {
+	if len(items) != len(videoIDs) {
+		return false
+	}
+	remaining := make(map[string]bool, len(items))
+	for _, item := range items {
+		remaining[item.VideoID] = true
+	}
+	for _, id := range videoIDs {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
+
+// loadItems 按顺序加载播放列表中的视频
+func (s *playlistService) loadItems(ctx context.Context, playlistID string) ([]model.PlaylistItem, error) {
+	cursor, err := database.GetCollection(s.itemCollection).Find(ctx,
+		bson.M{"playlist_id": playlistID},
+		options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "added_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	items := []model.PlaylistItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// find 获取播放列表
+func (s *playlistService) find(ctx context.Context, playlistID string) (*model.Playlist, error) {
+	objectID, err := primitive.ObjectIDFromHex(playlistID)
+	if err != nil {
+		return nil, ErrPlaylistNotFound
+	}
+	var playlist model.Playlist
+	err = database.GetCollection(s.collection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&playlist)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrPlaylistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// findOwned 获取当前用户创建的播放列表
+func (s *playlistService) findOwned(ctx context.Context, userID, playlistID string) (*model.Playlist, error) {
+	playlist, err := s.find(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.UserID != userID {
+		return nil, ErrPlaylistForbidden
+	}
+	return playlist, nil
+}
+
+// findVisible 获取当前用户可见的播放列表，私有列表对其他用户表现为不存在
+func (s *playlistService) findVisible(ctx context.Context, viewerID, playlistID string) (*model.Playlist, error) {
+	playlist, err := s.find(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.Visibility != model.PlaylistVisibilityPublic && playlist.UserID != viewerID {
+		return nil, ErrPlaylistNotFound
+	}
+	return playlist, nil
+}
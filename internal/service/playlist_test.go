@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试视频在播放列表中的位置及前后视频
+func TestPlaylistNavigation(t *testing.T) {
+	items := []model.PlaylistItem{{VideoID: "a"}, {VideoID: "b"}, {VideoID: "c"}}
+
+	nav := playlistNavigation(items, "a")
+	assert.Equal(t, 1, nav.Index)
+	assert.Equal(t, 3, nav.Total)
+	assert.Nil(t, nav.Prev)
+	assert.Equal(t, "b", nav.Next.VideoID)
+
+	nav = playlistNavigation(items, "b")
+	assert.Equal(t, 2, nav.Index)
+	assert.Equal(t, "a", nav.Prev.VideoID)
+	assert.Equal(t, "c", nav.Next.VideoID)
+
+	nav = playlistNavigation(items, "c")
+	assert.Equal(t, "b", nav.Prev.VideoID)
+	assert.Nil(t, nav.Next)
+
+	assert.Nil(t, playlistNavigation(items, "x"))
+}
+
+// 测试排序请求必须恰好包含列表中的全部视频
+func TestSamePlaylistItems(t *testing.T) {
+	items := []model.PlaylistItem{{VideoID: "a"}, {VideoID: "b"}, {VideoID: "c"}}
+
+	assert.True(t, samePlaylistItems(items, []string{"c", "a", "b"}))
+	assert.False(t, samePlaylistItems(items, []string{"a", "b"}))
+	assert.False(t, samePlaylistItems(items, []string{"a", "b", "b"}))
+	assert.False(t, samePlaylistItems(items, []string{"a", "b", "x"}))
+}
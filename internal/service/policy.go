@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/redis"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 互动权限相关错误
+var (
+	ErrBlocked        = errors.New("对方已将你拉黑")
+	ErrBlockingTarget = errors.New("你已拉黑该用户")
+)
+
+const (
+	blockCacheTTL      = 30 * time.Minute // 屏蔽关系缓存过期时间
+	blockCacheLoaded   = "_"              // 缓存中表示已加载的字段，用于区分空列表和缓存未命中
+	userStatusCacheTTL = 10 * time.Minute // 用户账号状态缓存过期时间
+)
+
+// blockRelationsKey 用户屏蔽关系缓存键，字段为被屏蔽者ID，值为屏蔽方式
+func blockRelationsKey(userID string) string {
+	return fmt.Sprintf("block:relations:%s", userID)
+}
+
+// checkInteraction 检查 actorID 能否与 ownerID 互动（评论、弹幕、私信、关注）。
+// 任一方拉黑了对方都不能互动，静音不影响互动
+func checkInteraction(ctx context.Context, actorID, ownerID string) error {
+	if actorID == "" || ownerID == "" || actorID == ownerID {
+		return nil
+	}
+
+	mode, err := blockMode(ctx, ownerID, actorID)
+	if err != nil {
+		return err
+	}
+	if mode == model.BlockModeBlock {
+		return ErrBlocked
+	}
+
+	mode, err = blockMode(ctx, actorID, ownerID)
+	if err != nil {
+		return err
+	}
+	if mode == model.BlockModeBlock {
+		return ErrBlockingTarget
+	}
+	return nil
+}
+
+// hiddenUserIDs 获取对 viewerID 隐藏内容的用户（拉黑和静音的用户）
+func hiddenUserIDs(ctx context.Context, viewerID string) ([]string, error) {
+	if viewerID == "" {
+		return nil, nil
+	}
+	relations, err := loadBlockRelations(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(relations))
+	for id := range relations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// blockMode 获取 userID 对 targetID 的屏蔽方式，未屏蔽时返回空字符串
+func blockMode(ctx context.Context, userID, targetID string) (string, error) {
+	if cache := redis.GetClient(); cache != nil {
+		values, err := cache.HMGet(ctx, blockRelationsKey(userID), blockCacheLoaded, targetID).Result()
+		if err == nil && values[0] != nil {
+			mode, _ := values[1].(string)
+			return mode, nil
+		}
+	}
+
+	relations, err := loadBlockRelations(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return relations[targetID], nil
+}
+
+// loadBlockRelations 优先从Redis读取用户的屏蔽关系，未命中时查询MongoDB并回填缓存
+func loadBlockRelations(ctx context.Context, userID string) (map[string]string, error) {
+	key := blockRelationsKey(userID)
+
+	cache := redis.GetClient()
+	if cache != nil {
+		values, err := cache.HGetAll(ctx, key).Result()
+		if err == nil && len(values) > 0 {
+			delete(values, blockCacheLoaded)
+			return values, nil
+		}
+	}
+
+	cursor, err := database.GetCollection("blocks").Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetProjection(bson.M{"target_id": 1, "mode": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []model.Block
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+
+	relations := make(map[string]string, len(blocks))
+	fields := []interface{}{blockCacheLoaded, "1"}
+	for _, b := range blocks {
+		relations[b.TargetID] = b.Mode
+		fields = append(fields, b.TargetID, b.Mode)
+	}
+
+	if cache != nil {
+		pipe := cache.TxPipeline()
+		pipe.HSet(ctx, key, fields...)
+		pipe.Expire(ctx, key, blockCacheTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			slog.Error("[loadBlockRelations] 写入屏蔽关系缓存失败", "error", err, "userId", userID)
+		}
+	}
+
+	return relations, nil
+}
+
+// invalidateBlockRelations 删除屏蔽关系缓存
+func invalidateBlockRelations(ctx context.Context, userID string) {
+	cache := redis.GetClient()
+	if cache == nil {
+		return
+	}
+	if err := cache.Del(ctx, blockRelationsKey(userID)).Err(); err != nil {
+		slog.Error("[invalidateBlockRelations] 删除屏蔽关系缓存失败", "error", err, "userId", userID)
+	}
+}
+
+// userStatusKey 用户账号状态缓存键
+func userStatusKey(userID string) string {
+	return fmt.Sprintf("user:status:%s", userID)
+}
+
+// CheckUserActive 检查用户账号是否可用，账号被禁用时返回 ErrUserDisabled。
+// 已签发的token在过期前仍然有效，认证中间件通过该方法拦截被禁用的用户
+func CheckUserActive(ctx context.Context, userID string) error {
+	status, err := userStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if status == model.UserStatusDisabled {
+		return ErrUserDisabled
+	}
+	return nil
+}
+
+// userStatus 优先从Redis读取用户的账号状态，未命中时查询MongoDB并回填缓存
+func userStatus(ctx context.Context, userID string) (int, error) {
+	key := userStatusKey(userID)
+
+	cache := redis.GetClient()
+	if cache != nil {
+		if status, err := cache.Get(ctx, key).Int(); err == nil {
+			return status, nil
+		}
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, nil
+	}
+	var user model.User
+	err = database.GetCollection("users").FindOne(ctx,
+		bson.M{"_id": objectID},
+		options.FindOne().SetProjection(bson.M{"status": 1}),
+	).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return 0, err
+	}
+
+	if cache != nil {
+		if err := cache.Set(ctx, key, user.Status, userStatusCacheTTL).Err(); err != nil {
+			slog.Error("[userStatus] 写入账号状态缓存失败", "error", err, "userId", userID)
+		}
+	}
+	return user.Status, nil
+}
+
+// invalidateUserStatus 删除账号状态缓存
+func invalidateUserStatus(ctx context.Context, userID string) {
+	cache := redis.GetClient()
+	if cache == nil {
+		return
+	}
+	if err := cache.Del(ctx, userStatusKey(userID)).Err(); err != nil {
+		slog.Error("[invalidateUserStatus] 删除账号状态缓存失败", "error", err, "userId", userID)
+	}
+}
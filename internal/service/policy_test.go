@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 测试自己和未登录用户不需要检查屏蔽关系
+func TestCheckInteractionSkipsSelfAndAnonymous(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, checkInteraction(ctx, "user1", "user1"))
+	assert.NoError(t, checkInteraction(ctx, "", "user1"))
+	assert.NoError(t, checkInteraction(ctx, "user1", ""))
+
+	hidden, err := hiddenUserIDs(ctx, "")
+	assert.NoError(t, err)
+	assert.Empty(t, hidden)
+}
+
+// 测试评论查询条件中排除屏蔽的评论者
+func TestHideAuthors(t *testing.T) {
+	filter := hideAuthors(bson.M{"video_id": "v1"}, nil)
+	assert.Equal(t, bson.M{"video_id": "v1", "hidden": bson.M{"$ne": true}}, filter)
+
+	filter = hideAuthors(bson.M{"video_id": "v1"}, []string{"u1", "u2"})
+	assert.Equal(t, bson.M{"$nin": []string{"u1", "u2"}}, filter["user_id"])
+}
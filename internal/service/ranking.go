@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrInvalidRankingPeriod 排行榜周期无效
+	ErrInvalidRankingPeriod = errors.New("无效的排行榜周期")
+	// ErrRankingUnavailable Redis不可用时无法提供排行榜
+	ErrRankingUnavailable = errors.New("排行榜暂不可用")
+)
+
+const (
+	trendingBucketTTL    = 8 * 24 * time.Hour // 小时桶过期时间，需覆盖周榜的时间窗口
+	trendingRankSize     = 1000               // 每个榜单保留的视频数
+	trendingEventTimeout = 5 * time.Second    // 异步记录互动事件的超时时间
+	trendingReplayWindow = 7 * 24 * time.Hour // 重建任务回放的时间范围
+	trendingScanBatch    = 500                // 重建任务清理旧数据时每批扫描的key数
+)
+
+// rankingEventWeights 各类互动事件计入热度的权重
+var rankingEventWeights = map[string]float64{
+	model.RankingEventView:    1,
+	model.RankingEventLike:    3,
+	model.RankingEventComment: 4,
+	model.RankingEventShare:   5,
+}
+
+// rankingPeriod 榜单的时间窗口和衰减参数
+type rankingPeriod struct {
+	window   int           // 合并的小时桶数量
+	halfLife float64       // 热度半衰期（小时）
+	ttl      time.Duration // 榜单重新计算的间隔
+}
+
+var rankingPeriods = map[string]rankingPeriod{
+	model.RankingPeriodHourly: {window: 2, halfLife: 1, ttl: time.Minute},
+	model.RankingPeriodDaily:  {window: 24, halfLife: 8, ttl: 5 * time.Minute},
+	model.RankingPeriodWeekly: {window: 168, halfLife: 48, ttl: 30 * time.Minute},
+}
+
+// RankingService 热门排行榜服务接口
+type RankingService interface {
+	Get(ctx context.Context, userID, period string, query model.RankingQuery) (*model.RankingResponse, error)
+}
+
+type rankingService struct{}
+
+// NewRankingService 创建排行榜服务实例
+func NewRankingService() RankingService {
+	return &rankingService{}
+}
+
+// Get 分页获取排行榜，榜单过期时先从小时桶重新计算
+func (s *rankingService) Get(ctx context.Context, userID, period string, query model.RankingQuery) (*model.RankingResponse, error) {
+	if !model.IsValidRankingPeriod(period) {
+		return nil, ErrInvalidRankingPeriod
+	}
+	cache := redis.GetClient()
+	if cache == nil {
+		return nil, ErrRankingUnavailable
+	}
+
+	page, size := query.Page, query.Size
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = feedDefaultSize
+	}
+	if size > feedMaxSize {
+		size = feedMaxSize
+	}
+
+	key := trendingRankKey(period, query.Tag)
+	built, err := cache.Exists(ctx, trendingBuiltKey(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if built == 0 {
+		if err := rebuildRanking(ctx, cache, period, query.Tag, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	total, err := cache.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	resp := &model.RankingResponse{
+		Period: period,
+		Tag:    query.Tag,
+		Items:  []model.RankingItem{},
+		Total:  total,
+		Page:   page,
+		Size:   size,
+	}
+
+	start := int64((page - 1) * size)
+	if start >= total {
+		return resp, nil
+	}
+	results, err := cache.ZRevRangeWithScores(ctx, key, start, start+int64(size)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]feedEntry, 0, len(results))
+	ranks := make(map[string]int64, len(results))
+	scores := make(map[string]float64, len(results))
+	for i, z := range results {
+		id, _ := z.Member.(string)
+		entries = append(entries, feedEntry{ID: id})
+		ranks[id] = start + int64(i) + 1
+		scores[id] = z.Score
+	}
+
+	// 已删除或已设为私有的视频会被过滤掉，名次保持不变
+	items, err := buildFeedItems(ctx, userID, entries, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		id := item.Video.ID.Hex()
+		resp.Items = append(resp.Items, model.RankingItem{
+			Rank:     ranks[id],
+			Score:    scores[id],
+			FeedItem: item,
+		})
+	}
+	return resp, nil
+}
+
+// rebuildRanking 按时间衰减合并最近的小时桶，生成榜单
+func rebuildRanking(ctx context.Context, cache *goredis.Client, period, tag string, now time.Time) error {
+	cfg := rankingPeriods[period]
+	keys, weights := trendingBuckets(cfg, tag, now)
+
+	key := trendingRankKey(period, tag)
+	_, err := cache.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, key, &goredis.ZStore{Keys: keys, Weights: weights, Aggregate: "SUM"})
+		pipe.ZRemRangeByRank(ctx, key, 0, -trendingRankSize-1)
+		pipe.Set(ctx, trendingBuiltKey(key), 1, cfg.ttl)
+		return nil
+	})
+	return err
+}
+
+// trendingBuckets 返回榜单需要合并的小时桶及其衰减权重，越早的桶权重越低
+func trendingBuckets(cfg rankingPeriod, tag string, now time.Time) ([]string, []float64) {
+	current := trendingHour(now)
+	keys := make([]string, 0, cfg.window)
+	weights := make([]float64, 0, cfg.window)
+	for age := 0; age < cfg.window; age++ {
+		keys = append(keys, trendingBucketKey(current-int64(age), tag))
+		weights = append(weights, math.Pow(0.5, float64(age)/cfg.halfLife))
+	}
+	return keys, weights
+}
+
+// trendingIncrements 记录一次互动事件对全站和各标签小时桶的热度增量
+func trendingIncrements(incs map[string]map[string]float64, videoID string, tags []string, event string, at time.Time, count int64) {
+	weight := rankingEventWeights[event] * float64(count)
+	if weight == 0 {
+		return
+	}
+
+	hour := trendingHour(at)
+	add := func(key string) {
+		if incs[key] == nil {
+			incs[key] = make(map[string]float64)
+		}
+		incs[key][videoID] += weight
+	}
+	add(trendingBucketKey(hour, ""))
+	for _, tag := range tags {
+		add(trendingBucketKey(hour, tag))
+	}
+}
+
+// flushTrendingIncrements 将热度增量写入Redis的小时桶
+func flushTrendingIncrements(ctx context.Context, cache *goredis.Client, incs map[string]map[string]float64) error {
+	pipe := cache.Pipeline()
+	for key, members := range incs {
+		for member, score := range members {
+			pipe.ZIncrBy(ctx, key, score, member)
+		}
+		pipe.Expire(ctx, key, trendingBucketTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// recordTrendingAsync 异步记录公开视频的互动事件
+func recordTrendingAsync(video model.Video, event string) {
+	if video.Status != model.VideoStatusPublic || redis.GetClient() == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), trendingEventTimeout)
+		defer cancel()
+
+		incs := make(map[string]map[string]float64)
+		trendingIncrements(incs, video.ID.Hex(), video.Tags, event, time.Now(), 1)
+		if err := flushTrendingIncrements(ctx, redis.GetClient(), incs); err != nil {
+			slog.Warn("[recordTrending] 记录热度失败", "error", err, "videoId", video.ID.Hex(), "event", event)
+		}
+	}()
+}
+
+// RebuildTrending 根据最近一周的点赞、评论、观看和分享记录重建小时桶，Redis数据丢失后执行
+func RebuildTrending(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	cache := redis.GetClient()
+	if cache == nil {
+		return nil, ErrRankingUnavailable
+	}
+
+	since := time.Now().Add(-trendingReplayWindow)
+	sources := []struct {
+		collection string
+		timeField  string
+		event      string
+	}{
+		{"likes", "created_at", model.RankingEventLike},
+		{"comments", "created_at", model.RankingEventComment},
+		{"watch_history", "watched_at", model.RankingEventView},
+		{"share_events", "created_at", model.RankingEventShare},
+	}
+
+	type event struct {
+		videoID string
+		name    string
+		at      time.Time
+	}
+	var events []event
+	videoIDs := make(map[string]bool)
+	for _, src := range sources {
+		cursor, err := database.GetCollection(src.collection).Find(ctx,
+			bson.M{src.timeField: bson.M{"$gte": since}},
+			options.Find().SetProjection(bson.M{"video_id": 1, src.timeField: 1}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			videoID, _ := doc["video_id"].(string)
+			at, ok := doc[src.timeField].(primitive.DateTime)
+			if videoID == "" || !ok {
+				continue
+			}
+			events = append(events, event{videoID: videoID, name: src.event, at: at.Time()})
+			videoIDs[videoID] = true
+		}
+	}
+
+	// 只统计公开视频
+	objectIDs := make([]primitive.ObjectID, 0, len(videoIDs))
+	for id := range videoIDs {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	videos, err := findVideos(ctx, bson.M{
+		"_id":    bson.M{"$in": objectIDs},
+		"status": model.VideoStatusPublic,
+	}, options.Find().SetProjection(bson.M{"tags": 1}))
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string][]string, len(videos))
+	for _, v := range videos {
+		tags[v.ID.Hex()] = v.Tags
+	}
+
+	result := &MigrationResult{Scanned: int64(len(events))}
+	incs := make(map[string]map[string]float64)
+	for _, e := range events {
+		videoTags, ok := tags[e.videoID]
+		if !ok {
+			continue
+		}
+		trendingIncrements(incs, e.videoID, videoTags, e.name, e.at, 1)
+		result.Fixed++
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	if err := clearTrending(ctx, cache); err != nil {
+		return nil, err
+	}
+	if len(incs) > 0 {
+		if err := flushTrendingIncrements(ctx, cache, incs); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// clearTrending 删除所有小时桶和榜单
+func clearTrending(ctx context.Context, cache *goredis.Client) error {
+	iter := cache.Scan(ctx, 0, "trending:*", trendingScanBatch).Iterator()
+	keys := make([]string, 0, trendingScanBatch)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= trendingScanBatch {
+			if err := cache.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return cache.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+// trendingHour 时间所在的小时序号
+func trendingHour(t time.Time) int64 {
+	return t.Unix() / 3600
+}
+
+// trendingBucketKey 小时桶的key，tag为空表示全站
+func trendingBucketKey(hour int64, tag string) string {
+	if tag == "" {
+		return fmt.Sprintf("trending:bucket:%d", hour)
+	}
+	return fmt.Sprintf("trending:bucket:%d:tag:%s", hour, tag)
+}
+
+// trendingRankKey 榜单的key，tag为空表示全站
+func trendingRankKey(period, tag string) string {
+	if tag == "" {
+		return "trending:rank:" + period
+	}
+	return fmt.Sprintf("trending:rank:%s:tag:%s", period, tag)
+}
+
+// trendingBuiltKey 榜单计算标记，过期后读取时重新计算
+func trendingBuiltKey(rankKey string) string {
+	return "trending:built:" + rankKey
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试榜单合并的小时桶和衰减权重
+func TestTrendingBuckets(t *testing.T) {
+	now := time.Unix(3600*100+1800, 0)
+
+	keys, weights := trendingBuckets(rankingPeriods[model.RankingPeriodHourly], "", now)
+	assert.Equal(t, []string{"trending:bucket:100", "trending:bucket:99"}, keys)
+	assert.Equal(t, []float64{1, 0.5}, weights)
+
+	keys, weights = trendingBuckets(rankingPeriods[model.RankingPeriodDaily], "go", now)
+	assert.Len(t, keys, 24)
+	assert.Equal(t, "trending:bucket:100:tag:go", keys[0])
+	assert.Equal(t, "trending:bucket:77:tag:go", keys[23])
+	assert.InDelta(t, 0.5, weights[8], 1e-9)
+	for i := 1; i < len(weights); i++ {
+		assert.Less(t, weights[i], weights[i-1])
+	}
+}
+
+// 测试互动事件写入全站和标签小时桶，并按权重累加
+func TestTrendingIncrements(t *testing.T) {
+	at := time.Unix(3600*100, 0)
+	incs := make(map[string]map[string]float64)
+
+	trendingIncrements(incs, "v1", []string{"go"}, model.RankingEventView, at, 1)
+	trendingIncrements(incs, "v1", []string{"go"}, model.RankingEventLike, at, 1)
+	trendingIncrements(incs, "v2", nil, model.RankingEventShare, at, 2)
+	trendingIncrements(incs, "v2", nil, "unknown", at, 1)
+
+	assert.Equal(t, map[string]map[string]float64{
+		"trending:bucket:100":        {"v1": 4, "v2": 10},
+		"trending:bucket:100:tag:go": {"v1": 4},
+	}, incs)
+}
+
+// 测试排行榜key
+func TestTrendingKeys(t *testing.T) {
+	assert.Equal(t, "trending:rank:daily", trendingRankKey(model.RankingPeriodDaily, ""))
+	assert.Equal(t, "trending:rank:weekly:tag:go", trendingRankKey(model.RankingPeriodWeekly, "go"))
+	assert.Equal(t, "trending:built:trending:rank:daily", trendingBuiltKey(trendingRankKey(model.RankingPeriodDaily, "")))
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	recommendHistoryLimit = 200 // 计算标签偏好时读取的观看/收藏记录数
+
+	// 标签偏好中不同行为的权重
+	recommendWatchWeight    = 1.0
+	recommendFavoriteWeight = 3.0
+
+	// 综合得分中各项的权重
+	recommendAffinityWeight   = 0.5
+	recommendPopularityWeight = 0.3
+	recommendFreshnessWeight  = 0.2
+)
+
+func init() {
+	// 视频设为非公开时移出候选池，标签变更时同步候选池中的标签
+	OnVideoChanged("recommend-pool", func(ctx context.Context, event VideoChangedEvent) error {
+		if !event.Changed(VideoFieldStatus, VideoFieldTags) {
+			return nil
+		}
+		collection := database.GetCollection("recommend_candidates")
+		videoID := event.Video.ID.Hex()
+		if event.Video.Status != model.VideoStatusPublic {
+			_, err := collection.DeleteOne(ctx, bson.M{"_id": videoID})
+			return err
+		}
+		_, err := collection.UpdateOne(ctx, bson.M{"_id": videoID}, bson.M{"$set": bson.M{"tags": event.Video.Tags}})
+		return err
+	})
+}
+
+// RecommendService 推荐服务接口
+type RecommendService interface {
+	Recommend(ctx context.Context, userID string, query model.FeedQuery) (*model.FeedResponse, error)
+}
+
+type recommendService struct {
+	collection string
+}
+
+// NewRecommendService 创建推荐服务实例
+func NewRecommendService() RecommendService {
+	return &recommendService{
+		collection: "recommend_candidates",
+	}
+}
+
+// Recommend 获取推荐视频。
+// 登录用户按标签偏好、热度和新鲜度综合排序并过滤已看过的视频；未登录用户只按热度和新鲜度排序（即热门）
+func (s *recommendService) Recommend(ctx context.Context, userID string, query model.FeedQuery) (*model.FeedResponse, error) {
+	size := query.Size
+	if size < 1 {
+		size = feedDefaultSize
+	}
+	if size > feedMaxSize {
+		size = feedMaxSize
+	}
+	offset := 0
+	if query.Cursor != "" {
+		var err error
+		if offset, err = decodeOffsetCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	candidates, err := s.loadCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var affinity map[string]float64
+	exclude := make(map[string]bool)
+	if userID != "" {
+		var watched []string
+		affinity, watched, err = loadTagAffinity(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range watched {
+			exclude[id] = true
+		}
+	}
+
+	ranked := rankCandidates(candidates, userID, affinity, exclude, time.Now())
+
+	resp := &model.FeedResponse{Items: []model.FeedItem{}}
+	if offset >= len(ranked) {
+		return resp, nil
+	}
+	end := offset + size
+	if end < len(ranked) {
+		resp.HasMore = true
+		resp.NextCursor = encodeOffsetCursor(end)
+	} else {
+		end = len(ranked)
+	}
+
+	entries := make([]feedEntry, 0, end-offset)
+	for _, c := range ranked[offset:end] {
+		entries = append(entries, feedEntry{ID: c.VideoID})
+	}
+	resp.Items, err = buildFeedItems(ctx, userID, entries, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// loadCandidates 按热度读取候选池，最多 PoolSize 条。候选池由 refresh-recommend-pool 任务离线生成，
+// 尚未生成时返回空列表，不在请求中全量扫描视频
+func (s *recommendService) loadCandidates(ctx context.Context) ([]model.RecommendCandidate, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "popularity", Value: -1}}).
+		SetProjection(bson.M{"refreshed_at": 0})
+	if limit := config.GlobalConfig.Recommend.PoolSize; limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := database.GetCollection(s.collection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]model.RecommendCandidate, 0)
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		slog.Warn("[Recommend] 推荐候选池为空，请执行 refresh-recommend-pool 任务")
+	}
+	return candidates, nil
+}
+
+// rankCandidates 计算候选视频的综合得分并排序，过滤已看过的视频和用户自己的视频
+func rankCandidates(candidates []model.RecommendCandidate, userID string, affinity map[string]float64, exclude map[string]bool, now time.Time) []model.RecommendCandidate {
+	maxPopularity := 0.0
+	for _, c := range candidates {
+		maxPopularity = math.Max(maxPopularity, c.Popularity)
+	}
+
+	halfLife := float64(config.GlobalConfig.Recommend.FreshHalfLife)
+	if halfLife <= 0 {
+		halfLife = 72
+	}
+
+	type scored struct {
+		candidate model.RecommendCandidate
+		score     float64
+	}
+	list := make([]scored, 0, len(candidates))
+	for _, c := range candidates {
+		if exclude[c.VideoID] || (userID != "" && c.UserID == userID) {
+			continue
+		}
+
+		popularity := 0.0
+		if maxPopularity > 0 {
+			popularity = c.Popularity / maxPopularity
+		}
+		ageHours := math.Max(now.Sub(c.CreatedAt).Hours(), 0)
+		freshness := math.Pow(0.5, ageHours/halfLife)
+
+		score := recommendPopularityWeight*popularity + recommendFreshnessWeight*freshness
+		if len(affinity) > 0 {
+			score += recommendAffinityWeight * tagAffinity(c.Tags, affinity)
+		}
+		list = append(list, scored{candidate: c, score: score})
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		return list[i].candidate.VideoID > list[j].candidate.VideoID
+	})
+
+	ranked := make([]model.RecommendCandidate, 0, len(list))
+	for _, item := range list {
+		ranked = append(ranked, item.candidate)
+	}
+	return ranked
+}
+
+// tagAffinity 视频与用户偏好的匹配度，取视频标签中偏好最高的一个（0-1）
+func tagAffinity(tags []string, affinity map[string]float64) float64 {
+	best := 0.0
+	for _, tag := range tags {
+		best = math.Max(best, affinity[tag])
+	}
+	return best
+}
+
+// popularityScore 根据互动数据计算热度分，取对数避免头部视频分数过高
+func popularityScore(stats model.VideoStats) float64 {
+	return math.Log1p(float64(stats.Views)) +
+		2*math.Log1p(float64(stats.Likes)) +
+		3*math.Log1p(float64(stats.Favorites)) +
+		2*math.Log1p(float64(stats.Comments)) +
+		3*math.Log1p(float64(stats.Shares))
+}
+
+// loadTagAffinity 根据观看历史和收藏计算用户的标签偏好（归一化到0-1），同时返回看过的视频ID
+func loadTagAffinity(ctx context.Context, userID string) (map[string]float64, []string, error) {
+	watched, err := recentVideoIDs(ctx, "watch_history", userID, "watched_at")
+	if err != nil {
+		return nil, nil, err
+	}
+	favorited, err := recentVideoIDs(ctx, "favorites", userID, "added_at")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	weights := make(map[string]float64)
+	for _, id := range watched {
+		weights[id] += recommendWatchWeight
+	}
+	for _, id := range favorited {
+		weights[id] += recommendFavoriteWeight
+	}
+	if len(weights) == 0 {
+		return nil, watched, nil
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(weights))
+	for id := range weights {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	cursor, err := database.GetCollection("videos").Find(ctx,
+		bson.M{"_id": bson.M{"$in": objectIDs}},
+		options.Find().SetProjection(bson.M{"tags": 1}),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var videos []model.Video
+	if err := cursor.All(ctx, &videos); err != nil {
+		return nil, nil, err
+	}
+
+	affinity := make(map[string]float64)
+	maxWeight := 0.0
+	for _, v := range videos {
+		for _, tag := range v.Tags {
+			affinity[tag] += weights[v.ID.Hex()]
+			maxWeight = math.Max(maxWeight, affinity[tag])
+		}
+	}
+	for tag := range affinity {
+		affinity[tag] /= maxWeight
+	}
+	return affinity, watched, nil
+}
+
+// recentVideoIDs 获取用户最近在指定集合中关联的视频ID
+func recentVideoIDs(ctx context.Context, name, userID, timeField string) ([]string, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: timeField, Value: -1}}).
+		SetLimit(recommendHistoryLimit).
+		SetProjection(bson.M{"video_id": 1})
+	cursor, err := database.GetCollection(name).Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		VideoID string `bson:"video_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.VideoID)
+	}
+	return ids, nil
+}
+
+// buildRecommendCandidates 从最近发布的公开视频中按热度选出候选池，返回候选和扫描的视频数
+func buildRecommendCandidates(ctx context.Context) ([]model.RecommendCandidate, int64, error) {
+	cfg := config.GlobalConfig.Recommend
+	opts := options.Find().SetProjection(bson.M{
+		"user_id": 1, "tags": 1, "stats": 1, "created_at": 1,
+	})
+
+	since := time.Now().AddDate(0, 0, -int(cfg.PoolDays))
+	videos, err := findVideos(ctx, bson.M{
+		"status":     model.VideoStatusPublic,
+		"created_at": bson.M{"$gte": since},
+	}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	// 近期没有新视频时退化为最新发布的视频
+	if len(videos) == 0 {
+		opts.SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(cfg.PoolSize)
+		if videos, err = findVideos(ctx, bson.M{"status": model.VideoStatusPublic}, opts); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	now := time.Now()
+	candidates := make([]model.RecommendCandidate, 0, len(videos))
+	for _, v := range videos {
+		candidates = append(candidates, model.RecommendCandidate{
+			VideoID:     v.ID.Hex(),
+			UserID:      v.UserID,
+			Tags:        v.Tags,
+			Popularity:  popularityScore(v.Stats),
+			CreatedAt:   v.CreatedAt,
+			RefreshedAt: now,
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Popularity > candidates[j].Popularity
+	})
+	if cfg.PoolSize > 0 && int64(len(candidates)) > cfg.PoolSize {
+		candidates = candidates[:cfg.PoolSize]
+	}
+	return candidates, int64(len(videos)), nil
+}
+
+// findVideos 按条件查询视频
+func findVideos(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]model.Video, error) {
+	cursor, err := database.GetCollection("videos").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var videos []model.Video
+	if err := cursor.All(ctx, &videos); err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+// RefreshRecommendCandidates 离线刷新推荐候选池，建议定时执行
+func RefreshRecommendCandidates(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	candidates, scanned, err := buildRecommendCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrationResult{Scanned: scanned, Fixed: int64(len(candidates))}
+	if dryRun || len(candidates) == 0 {
+		return result, nil
+	}
+
+	collection := database.GetCollection("recommend_candidates")
+	models := make([]mongo.WriteModel, 0, len(candidates))
+	for _, c := range candidates {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": c.VideoID}).
+			SetReplacement(c).
+			SetUpsert(true))
+	}
+	if _, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return nil, err
+	}
+
+	// 删除本次未入选的旧候选
+	if _, err := collection.DeleteMany(ctx, bson.M{"refreshed_at": bson.M{"$lt": candidates[0].RefreshedAt}}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// encodeOffsetCursor 生成基于偏移量的游标
+func encodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeOffsetCursor 解析基于偏移量的游标
+func decodeOffsetCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("无效的游标")
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, errors.New("无效的游标")
+	}
+	return offset, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试偏移量游标的编码和解析
+func TestOffsetCursor(t *testing.T) {
+	offset, err := decodeOffsetCursor(encodeOffsetCursor(40))
+	assert.Nil(t, err)
+	assert.Equal(t, 40, offset)
+
+	_, err = decodeOffsetCursor("not-a-cursor!")
+	assert.NotNil(t, err)
+
+	_, err = decodeOffsetCursor(encodeOffsetCursor(-1))
+	assert.NotNil(t, err)
+}
+
+// 测试热度分随互动数据增长且收藏、分享权重更高
+func TestPopularityScore(t *testing.T) {
+	assert.Equal(t, 0.0, popularityScore(model.VideoStats{}))
+	assert.Greater(t, popularityScore(model.VideoStats{Views: 100}), popularityScore(model.VideoStats{Views: 10}))
+	assert.Greater(t, popularityScore(model.VideoStats{Favorites: 10}), popularityScore(model.VideoStats{Views: 10}))
+	assert.InDelta(t, 3*math.Log1p(5), popularityScore(model.VideoStats{Shares: 5}), 1e-9)
+}
+
+// 测试候选排序：标签偏好优先，过滤已看过和自己的视频
+func TestRankCandidates(t *testing.T) {
+	now := time.Now()
+	candidates := []model.RecommendCandidate{
+		{VideoID: "hot", UserID: "u1", Tags: []string{"music"}, Popularity: 10, CreatedAt: now},
+		{VideoID: "match", UserID: "u2", Tags: []string{"go"}, Popularity: 5, CreatedAt: now},
+		{VideoID: "watched", UserID: "u3", Tags: []string{"go"}, Popularity: 10, CreatedAt: now},
+		{VideoID: "mine", UserID: "me", Tags: []string{"go"}, Popularity: 10, CreatedAt: now},
+		{VideoID: "old", UserID: "u4", Tags: []string{"music"}, Popularity: 10, CreatedAt: now.AddDate(0, -1, 0)},
+	}
+
+	ids := func(list []model.RecommendCandidate) []string {
+		result := make([]string, 0, len(list))
+		for _, c := range list {
+			result = append(result, c.VideoID)
+		}
+		return result
+	}
+
+	// 未登录：只按热度和新鲜度排序
+	ranked := rankCandidates(candidates, "", nil, nil, now)
+	assert.Equal(t, []string{"watched", "mine", "hot", "match", "old"}, ids(ranked))
+
+	// 登录用户：偏好go标签
+	ranked = rankCandidates(candidates, "me", map[string]float64{"go": 1, "music": 0.1}, map[string]bool{"watched": true}, now)
+	assert.Equal(t, []string{"match", "hot", "old"}, ids(ranked))
+}
@@ -0,0 +1,551 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+	"video-platform/pkg/redis"
+	"video-platform/pkg/ws"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 协作标注房间相关错误
+var (
+	ErrRoomNotFound  = errors.New("房间不存在")
+	ErrRoomForbidden = errors.New("不是该房间的成员")
+	ErrInvalidRoom   = errors.New("无效的房间参数")
+)
+
+const (
+	// roomMemberLimit 每个房间最多的成员数（含创建者）
+	roomMemberLimit = 20
+	// roomPresenceTTL 超过该时间没有上报播放位置的成员视为离线
+	roomPresenceTTL = 60 * time.Second
+)
+
+// roomRelay 协作标注房间的推送连接，所有服务实例共享，多实例部署时通过Redis转发
+var roomRelay = ws.NewRelay(ws.NewHub(), "annotation_rooms")
+
+// roomPresence 房间成员的在线状态，所有服务实例共享
+var roomPresence = &roomPresenceStore{local: make(map[string]map[string]model.RoomPresence)}
+
+// RoomService 协作标注房间服务接口
+type RoomService interface {
+	Create(ctx context.Context, userID string, req *model.CreateRoomRequest) (*model.AnnotationRoom, error)
+	List(ctx context.Context, userID, videoID string) ([]model.AnnotationRoom, error)
+	Get(ctx context.Context, userID, roomID string) (*model.RoomDetail, error)
+	AddMembers(ctx context.Context, userID, roomID string, userIDs []string) (*model.AnnotationRoom, error)
+	RemoveMember(ctx context.Context, userID, roomID, memberID string) error
+	Delete(ctx context.Context, userID, roomID string) error
+
+	Join(ctx context.Context, userID, roomID string, client *ws.Client) ([]model.RoomPresence, error)
+	Leave(ctx context.Context, userID, roomID string, client *ws.Client)
+	UpdatePresence(ctx context.Context, userID, roomID string, timestamp float64, playing bool) error
+}
+
+type roomService struct {
+	collection string
+	relay      *ws.Relay
+}
+
+// NewRoomService 创建协作标注房间服务实例，relay 为空时使用共享的推送连接
+func NewRoomService(relay *ws.Relay) RoomService {
+	if relay == nil {
+		relay = roomRelay
+	}
+	return &roomService{
+		collection: "annotation_rooms",
+		relay:      relay,
+	}
+}
+
+// Create 创建房间并邀请成员，视频需要公开或由创建者上传
+func (s *roomService) Create(ctx context.Context, userID string, req *model.CreateRoomRequest) (*model.AnnotationRoom, error) {
+	objectID, err := primitive.ObjectIDFromHex(req.VideoID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无效的视频ID", ErrInvalidRoom)
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		return nil, fmt.Errorf("%w: 视频不存在", ErrInvalidRoom)
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != userID {
+		return nil, fmt.Errorf("%w: 无权在该视频创建房间", ErrInvalidRoom)
+	}
+
+	invited, err := roomInvitees(ctx, userID, []string{userID}, req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	room := &model.AnnotationRoom{
+		ID:        primitive.NewObjectID(),
+		VideoID:   req.VideoID,
+		OwnerID:   userID,
+		Name:      req.Name,
+		Members:   append([]string{userID}, invited...),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := database.GetCollection(s.collection).InsertOne(ctx, room); err != nil {
+		return nil, err
+	}
+	notifyRoomInvite(room, invited)
+	return room, nil
+}
+
+// List 获取当前用户加入的房间，可按视频过滤，按更新时间倒序
+func (s *roomService) List(ctx context.Context, userID, videoID string) ([]model.AnnotationRoom, error) {
+	filter := bson.M{"members": userID}
+	if videoID != "" {
+		filter["video_id"] = videoID
+	}
+	cursor, err := database.GetCollection(s.collection).Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	rooms := []model.AnnotationRoom{}
+	if err := cursor.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// Get 获取房间详情：成员信息、房间内的标记和笔记（按时间戳排序）以及在线成员
+func (s *roomService) Get(ctx context.Context, userID, roomID string) (*model.RoomDetail, error) {
+	room, err := findMemberRoom(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	briefs, err := loadUserBriefs(ctx, room.Members)
+	if err != nil {
+		return nil, err
+	}
+	detail := &model.RoomDetail{AnnotationRoom: *room, MemberInfo: make([]model.UserBrief, 0, len(room.Members))}
+	for _, id := range room.Members {
+		if brief, ok := briefs[id]; ok {
+			detail.MemberInfo = append(detail.MemberInfo, brief)
+		}
+	}
+
+	byTimestamp := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "created_at", Value: 1}})
+	cursor, err := database.GetCollection("marks").Find(ctx, bson.M{"room_id": roomID, "hidden": bson.M{"$ne": true}}, byTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	detail.Marks = []model.Mark{}
+	if err := cursor.All(ctx, &detail.Marks); err != nil {
+		return nil, err
+	}
+
+	cursor, err = database.GetCollection("notes").Find(ctx, bson.M{"room_id": roomID}, byTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	detail.Notes = []model.Note{}
+	if err := cursor.All(ctx, &detail.Notes); err != nil {
+		return nil, err
+	}
+	renderNotes(detail.Notes)
+
+	if detail.Presence, err = roomPresence.list(ctx, roomID, time.Now()); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// AddMembers 邀请成员，只有创建者可以邀请
+func (s *roomService) AddMembers(ctx context.Context, userID, roomID string, userIDs []string) (*model.AnnotationRoom, error) {
+	room, err := findMemberRoom(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.OwnerID != userID {
+		return nil, ErrRoomForbidden
+	}
+
+	invited, err := roomInvitees(ctx, userID, room.Members, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(invited) == 0 {
+		return room, nil
+	}
+
+	// 以当前成员数为条件，避免并发邀请超过人数上限
+	var updated model.AnnotationRoom
+	err = database.GetCollection(s.collection).FindOneAndUpdate(ctx,
+		bson.M{"_id": room.ID, "members": bson.M{"$size": len(room.Members)}},
+		bson.M{
+			"$addToSet": bson.M{"members": bson.M{"$each": invited}},
+			"$set":      bson.M{"updated_at": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("%w: 房间成员已变化，请刷新后重试", ErrInvalidRoom)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	notifyRoomInvite(&updated, invited)
+	s.publish(ctx, roomID, "members", updated.Members)
+	return &updated, nil
+}
+
+// RemoveMember 移除成员：创建者可以移除其他成员，成员可以移除自己（退出房间）。
+// 被移除成员的实时推送连接会被断开；成员退出后，其添加到房间的标记和笔记仍保留在房间中
+func (s *roomService) RemoveMember(ctx context.Context, userID, roomID, memberID string) error {
+	room, err := findMemberRoom(ctx, userID, roomID)
+	if err != nil {
+		return err
+	}
+	if memberID == room.OwnerID {
+		return fmt.Errorf("%w: 创建者不能退出房间，可以删除房间", ErrInvalidRoom)
+	}
+	if userID != room.OwnerID && userID != memberID {
+		return ErrRoomForbidden
+	}
+
+	var updated model.AnnotationRoom
+	err = database.GetCollection(s.collection).FindOneAndUpdate(ctx,
+		bson.M{"_id": room.ID},
+		bson.M{
+			"$pull": bson.M{"members": memberID},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return err
+	}
+
+	if err := s.relay.Disconnect(ctx, roomMemberChannel(roomID, memberID), ws.Message{Type: "removed", Data: bson.M{"roomId": roomID}}); err != nil {
+		slog.Error("[RemoveRoomMember] 断开成员连接失败", "error", err, "roomId", roomID, "userId", memberID)
+	}
+	if err := roomPresence.remove(ctx, roomID, memberID); err != nil {
+		slog.Warn("[RemoveRoomMember] 清除在线状态失败", "error", err, "roomId", roomID)
+	}
+	s.publish(ctx, roomID, "members", updated.Members)
+	return nil
+}
+
+// Delete 删除房间，只有创建者可以删除，所有成员的实时推送连接会被断开。房间内的标记和笔记保留为各自作者的私有内容
+func (s *roomService) Delete(ctx context.Context, userID, roomID string) error {
+	room, err := findMemberRoom(ctx, userID, roomID)
+	if err != nil {
+		return err
+	}
+	if room.OwnerID != userID {
+		return ErrRoomForbidden
+	}
+
+	if _, err := database.GetCollection(s.collection).DeleteOne(ctx, bson.M{"_id": room.ID}); err != nil {
+		return err
+	}
+	unset := bson.M{"$unset": bson.M{"room_id": ""}}
+	if _, err := database.GetCollection("marks").UpdateMany(ctx, bson.M{"room_id": roomID}, unset); err != nil {
+		return err
+	}
+	if _, err := database.GetCollection("notes").UpdateMany(ctx, bson.M{"room_id": roomID}, unset); err != nil {
+		return err
+	}
+
+	closeRoom(ctx, s.relay, roomID)
+	return nil
+}
+
+// closeRoom 通知房间已删除并断开所有成员的连接，清除在线状态，失败只记录日志
+func closeRoom(ctx context.Context, relay *ws.Relay, roomID string) {
+	if err := relay.Disconnect(ctx, roomChannel(roomID), ws.Message{Type: "deleted", Data: bson.M{"roomId": roomID}}); err != nil {
+		slog.Error("[closeRoom] 断开房间连接失败", "error", err, "roomId", roomID)
+	}
+	if err := roomPresence.clear(ctx, roomID); err != nil {
+		slog.Warn("[closeRoom] 清除在线状态失败", "error", err, "roomId", roomID)
+	}
+}
+
+// Join 成员连接房间的实时推送，返回当前在线的成员。
+// 连接同时加入成员自己的频道，被移出房间时只断开该成员的连接
+func (s *roomService) Join(ctx context.Context, userID, roomID string, client *ws.Client) ([]model.RoomPresence, error) {
+	if _, err := findMemberRoom(ctx, userID, roomID); err != nil {
+		return nil, err
+	}
+	s.relay.Join(roomChannel(roomID), client)
+	s.relay.Join(roomMemberChannel(roomID, userID), client)
+	return roomPresence.list(ctx, roomID, time.Now())
+}
+
+// Leave 断开房间的实时推送并通知其他成员
+func (s *roomService) Leave(ctx context.Context, userID, roomID string, client *ws.Client) {
+	s.relay.Leave(roomChannel(roomID), client)
+	s.relay.Leave(roomMemberChannel(roomID, userID), client)
+	if err := roomPresence.remove(ctx, roomID, userID); err != nil {
+		slog.Warn("[LeaveRoom] 清除在线状态失败", "error", err, "roomId", roomID)
+	}
+	s.publish(ctx, roomID, "leave", bson.M{"userId": userID})
+}
+
+// UpdatePresence 上报成员的播放位置并推送给其他成员，已被移出房间的用户不能再上报
+func (s *roomService) UpdatePresence(ctx context.Context, userID, roomID string, timestamp float64, playing bool) error {
+	if timestamp < 0 {
+		return fmt.Errorf("%w: 无效的播放位置", ErrInvalidRoom)
+	}
+	if _, err := findMemberRoom(ctx, userID, roomID); err != nil {
+		return err
+	}
+	presence := model.RoomPresence{
+		UserID:    userID,
+		Timestamp: timestamp,
+		Playing:   playing,
+		UpdatedAt: time.Now(),
+	}
+	if err := roomPresence.set(ctx, roomID, presence); err != nil {
+		return err
+	}
+	s.publish(ctx, roomID, "presence", presence)
+	return nil
+}
+
+// findMemberRoom 查找房间并检查当前用户是否为成员
+func findMemberRoom(ctx context.Context, userID, roomID string) (*model.AnnotationRoom, error) {
+	objectID, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	var room model.AnnotationRoom
+	err = database.GetCollection("annotation_rooms").FindOne(ctx, bson.M{"_id": objectID}).Decode(&room)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !room.IsMember(userID) {
+		return nil, ErrRoomForbidden
+	}
+	return &room, nil
+}
+
+// roomInvitees 校验邀请的用户：去掉空值、重复和已在房间的用户，检查人数上限、用户是否存在以及拉黑关系
+func roomInvitees(ctx context.Context, ownerID string, members, userIDs []string) ([]string, error) {
+	seen := make(map[string]bool, len(members))
+	for _, id := range members {
+		seen[id] = true
+	}
+	invited := make([]string, 0, len(userIDs))
+	for _, id := range uniqueShareTargets(userIDs, ownerID) {
+		if !seen[id] {
+			invited = append(invited, id)
+		}
+	}
+	if len(invited) == 0 {
+		return invited, nil
+	}
+	if len(members)+len(invited) > roomMemberLimit {
+		return nil, fmt.Errorf("%w: 房间最多%d名成员", ErrInvalidRoom, roomMemberLimit)
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(invited))
+	for _, id := range invited {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 无效的用户ID", ErrInvalidRoom)
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	count, err := database.GetCollection("users").CountDocuments(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(len(objectIDs)) {
+		return nil, fmt.Errorf("%w: 邀请的用户不存在", ErrInvalidRoom)
+	}
+	for _, id := range invited {
+		if err := checkInteraction(ctx, ownerID, id); err != nil {
+			return nil, err
+		}
+	}
+	return invited, nil
+}
+
+// notifyRoomInvite 通知被邀请的用户
+func notifyRoomInvite(room *model.AnnotationRoom, invited []string) {
+	for _, id := range invited {
+		notifyAsync(model.Notification{
+			UserID:     id,
+			Type:       model.NotificationTypeRoomInvite,
+			TargetType: model.NotificationTargetRoom,
+			TargetID:   room.ID.Hex(),
+			ActorIDs:   []string{room.OwnerID},
+			Content:    room.Name,
+		})
+	}
+}
+
+// isRoomMember 用户是否为房间成员，房间不存在时返回 false
+func isRoomMember(ctx context.Context, userID, roomID string) (bool, error) {
+	_, err := findMemberRoom(ctx, userID, roomID)
+	if errors.Is(err, ErrRoomNotFound) || errors.Is(err, ErrRoomForbidden) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkRoomTarget 检查用户是否为房间成员，以及房间是否属于该视频
+func checkRoomTarget(ctx context.Context, userID, roomID, videoID string) error {
+	room, err := findMemberRoom(ctx, userID, roomID)
+	if err != nil {
+		return err
+	}
+	if room.VideoID != videoID {
+		return fmt.Errorf("%w: 房间不属于该视频", ErrInvalidRoom)
+	}
+	return nil
+}
+
+// publish 通过房间服务的推送连接向房间内所有在线成员推送消息
+func (s *roomService) publish(ctx context.Context, roomID, eventType string, data interface{}) {
+	publishRoomMessage(ctx, s.relay, roomID, eventType, data)
+}
+
+// publishRoomEvent 标记和笔记服务通过共享的推送连接向房间推送变更
+func publishRoomEvent(ctx context.Context, roomID, eventType string, data interface{}) {
+	publishRoomMessage(ctx, roomRelay, roomID, eventType, data)
+}
+
+// publishRoomMessage 向房间内所有在线成员推送消息，推送失败只记录日志
+func publishRoomMessage(ctx context.Context, relay *ws.Relay, roomID, eventType string, data interface{}) {
+	if roomID == "" {
+		return
+	}
+	if err := relay.Publish(ctx, roomChannel(roomID), ws.Message{Type: eventType, Data: data}); err != nil {
+		slog.Error("[publishRoomEvent] 推送房间消息失败", "error", err, "roomId", roomID, "type", eventType)
+	}
+}
+
+// roomChannel 房间的推送频道
+func roomChannel(roomID string) string {
+	return "room:" + roomID
+}
+
+// roomMemberChannel 房间内单个成员的推送频道，用于断开被移除成员的连接
+func roomMemberChannel(roomID, userID string) string {
+	return "room:" + roomID + ":user:" + userID
+}
+
+// roomPresenceStore 房间成员的在线状态。配置了Redis时保存在Redis中以便多实例共享，
+// 否则保存在本实例内存中
+type roomPresenceStore struct {
+	mu    sync.Mutex
+	local map[string]map[string]model.RoomPresence
+}
+
+func roomPresenceKey(roomID string) string {
+	return "room:presence:" + roomID
+}
+
+func (p *roomPresenceStore) set(ctx context.Context, roomID string, presence model.RoomPresence) error {
+	if cache := redis.GetClient(); cache != nil {
+		data, err := json.Marshal(presence)
+		if err != nil {
+			return err
+		}
+		key := roomPresenceKey(roomID)
+		if err := cache.HSet(ctx, key, presence.UserID, data).Err(); err != nil {
+			return err
+		}
+		// 房间长时间没有人上报时整个键过期
+		return cache.Expire(ctx, key, 2*roomPresenceTTL).Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.local[roomID] == nil {
+		p.local[roomID] = make(map[string]model.RoomPresence)
+	}
+	p.local[roomID][presence.UserID] = presence
+	return nil
+}
+
+func (p *roomPresenceStore) remove(ctx context.Context, roomID, userID string) error {
+	if cache := redis.GetClient(); cache != nil {
+		return cache.HDel(ctx, roomPresenceKey(roomID), userID).Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.local[roomID], userID)
+	if len(p.local[roomID]) == 0 {
+		delete(p.local, roomID)
+	}
+	return nil
+}
+
+func (p *roomPresenceStore) clear(ctx context.Context, roomID string) error {
+	if cache := redis.GetClient(); cache != nil {
+		return cache.Del(ctx, roomPresenceKey(roomID)).Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.local, roomID)
+	return nil
+}
+
+// list 获取房间内在线的成员
+func (p *roomPresenceStore) list(ctx context.Context, roomID string, now time.Time) ([]model.RoomPresence, error) {
+	var entries []model.RoomPresence
+	if cache := redis.GetClient(); cache != nil {
+		values, err := cache.HGetAll(ctx, roomPresenceKey(roomID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			var presence model.RoomPresence
+			if err := json.Unmarshal([]byte(value), &presence); err == nil {
+				entries = append(entries, presence)
+			}
+		}
+	} else {
+		p.mu.Lock()
+		for _, presence := range p.local[roomID] {
+			entries = append(entries, presence)
+		}
+		p.mu.Unlock()
+	}
+	return activePresence(entries, now), nil
+}
+
+// activePresence 过滤掉超过 roomPresenceTTL 没有上报的成员，按播放位置排序
+func activePresence(entries []model.RoomPresence, now time.Time) []model.RoomPresence {
+	active := make([]model.RoomPresence, 0, len(entries))
+	for _, presence := range entries {
+		if now.Sub(presence.UpdatedAt) <= roomPresenceTTL {
+			active = append(active, presence)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Timestamp != active[j].Timestamp {
+			return active[i].Timestamp < active[j].Timestamp
+		}
+		return active[i].UserID < active[j].UserID
+	})
+	return active
+}
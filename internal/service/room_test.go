@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试在线成员过滤和排序
+func TestActivePresence(t *testing.T) {
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local)
+	entries := []model.RoomPresence{
+		{UserID: "u3", Timestamp: 30, UpdatedAt: now.Add(-10 * time.Second)},
+		{UserID: "u1", Timestamp: 12.5, UpdatedAt: now},
+		{UserID: "u2", Timestamp: 12.5, UpdatedAt: now.Add(-roomPresenceTTL)},
+		{UserID: "u4", Timestamp: 5, UpdatedAt: now.Add(-roomPresenceTTL - time.Second)}, // 已超时
+	}
+
+	active := activePresence(entries, now)
+	var ids []string
+	for _, p := range active {
+		ids = append(ids, p.UserID)
+	}
+	assert.Equal(t, []string{"u1", "u2", "u3"}, ids)
+	assert.Empty(t, activePresence(nil, now))
+}
+
+// 测试未配置Redis时在线状态保存在内存中
+func TestRoomPresenceStoreLocal(t *testing.T) {
+	ctx := context.Background()
+	store := &roomPresenceStore{local: make(map[string]map[string]model.RoomPresence)}
+	now := time.Now()
+
+	assert.NoError(t, store.set(ctx, "room1", model.RoomPresence{UserID: "u1", Timestamp: 10, UpdatedAt: now}))
+	assert.NoError(t, store.set(ctx, "room1", model.RoomPresence{UserID: "u2", Timestamp: 5, Playing: true, UpdatedAt: now}))
+	assert.NoError(t, store.set(ctx, "room1", model.RoomPresence{UserID: "u1", Timestamp: 20, UpdatedAt: now}))
+	assert.NoError(t, store.set(ctx, "room2", model.RoomPresence{UserID: "u3", UpdatedAt: now}))
+
+	list, err := store.list(ctx, "room1", now)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "u2", list[0].UserID)
+	assert.Equal(t, 20.0, list[1].Timestamp)
+
+	assert.NoError(t, store.remove(ctx, "room1", "u2"))
+	list, _ = store.list(ctx, "room1", now)
+	assert.Len(t, list, 1)
+
+	assert.NoError(t, store.clear(ctx, "room1"))
+	list, _ = store.list(ctx, "room1", now)
+	assert.Empty(t, list)
+	list, _ = store.list(ctx, "room2", now)
+	assert.Len(t, list, 1)
+}
+
+// 测试房间成员判断
+func TestAnnotationRoomIsMember(t *testing.T) {
+	room := model.AnnotationRoom{OwnerID: "u1", Members: []string{"u1", "u2"}}
+	assert.True(t, room.IsMember("u1"))
+	assert.True(t, room.IsMember("u2"))
+	assert.False(t, room.IsMember("u3"))
+	assert.False(t, room.IsMember(""))
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// searchCandidateLimit 每类记录最多参与排序的候选数，超过时响应的 truncated 为 true
+	searchCandidateLimit = 500
+	// searchTermLimit 查询最多使用的分词数
+	searchTermLimit = 10
+	// searchSnippetLength 高亮片段的最大长度（字符）
+	searchSnippetLength = 80
+)
+
+// SearchService 标记、注释和笔记搜索服务接口
+type SearchService interface {
+	Search(ctx context.Context, userID string, query *model.SearchQuery) (*model.SearchResponse, error)
+}
+
+type searchService struct{}
+
+// NewSearchService 创建搜索服务实例
+func NewSearchService() SearchService {
+	return &searchService{}
+}
+
+// Search 在当前用户自己的标记、注释和笔记中搜索，按相关度和创建时间排序
+func (s *searchService) Search(ctx context.Context, userID string, query *model.SearchQuery) (*model.SearchResponse, error) {
+	page, size := query.Page, query.Size
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	terms := searchTerms(query.Keyword)
+	resp := &model.SearchResponse{Hits: []model.SearchHit{}, Terms: terms, Page: page, Size: size}
+	if len(terms) == 0 {
+		return resp, nil
+	}
+
+	filter := searchFilter(userID, terms, query)
+	var hits []model.SearchHit
+	if query.Type == "" || query.Type == model.SearchTypeMark {
+		marks, truncated, err := s.searchMarks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, marks...)
+		resp.Truncated = resp.Truncated || truncated
+	}
+	if query.Type == "" || query.Type == model.SearchTypeAnnotation {
+		annotations, truncated, err := s.searchAnnotations(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, annotations...)
+		resp.Truncated = resp.Truncated || truncated
+	}
+	if query.Type == "" || query.Type == model.SearchTypeNote {
+		notes, truncated, err := s.searchNotes(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, notes...)
+		resp.Truncated = resp.Truncated || truncated
+	}
+
+	phrase := strings.ToLower(strings.TrimSpace(query.Keyword))
+	for i := range hits {
+		hits[i].Highlight, hits[i].Score = highlightTerms(hits[i].Content, terms)
+		if phrase != "" && strings.Contains(strings.ToLower(hits[i].Content), phrase) {
+			hits[i].Score += len(terms)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+
+	resp.Total = len(hits)
+	start := (page - 1) * size
+	if start >= len(hits) {
+		return resp, nil
+	}
+	end := start + size
+	if end > len(hits) {
+		end = len(hits)
+	}
+	resp.Hits = hits[start:end]
+
+	if err := fillSearchVideoTitles(ctx, resp.Hits); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// searchFilter 构建查询条件：内容需要包含全部分词
+func searchFilter(userID string, terms []string, query *model.SearchQuery) bson.M {
+	and := make([]bson.M, 0, len(terms))
+	for _, term := range terms {
+		and = append(and, bson.M{"content": bson.M{"$regex": regexp.QuoteMeta(term), "$options": "i"}})
+	}
+	filter := bson.M{"user_id": userID, "$and": and}
+	if query.VideoID != "" {
+		filter["video_id"] = query.VideoID
+	}
+
+	created := bson.M{}
+	if !query.From.IsZero() {
+		created["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		created["$lt"] = query.To.Add(24 * time.Hour)
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+	return filter
+}
+
+// searchOptions 候选记录按创建时间倒序取前 searchCandidateLimit 条，多查询一条用于判断是否被截断
+func searchOptions() *options.FindOptions {
+	return options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(searchCandidateLimit + 1)
+}
+
+func (s *searchService) searchMarks(ctx context.Context, filter bson.M) ([]model.SearchHit, bool, error) {
+	cursor, err := database.GetCollection("marks").Find(ctx, filter, searchOptions())
+	if err != nil {
+		return nil, false, err
+	}
+	var marks []model.Mark
+	if err := cursor.All(ctx, &marks); err != nil {
+		return nil, false, err
+	}
+	truncated := len(marks) > searchCandidateLimit
+	if truncated {
+		marks = marks[:searchCandidateLimit]
+	}
+
+	hits := make([]model.SearchHit, 0, len(marks))
+	for _, m := range marks {
+		hit := model.SearchHit{
+			Type:      model.SearchTypeMark,
+			ID:        m.ID.Hex(),
+			VideoID:   m.VideoID,
+			Timestamp: m.Timestamp,
+			Content:   m.Content,
+			CreatedAt: m.CreatedAt,
+		}
+		if m.IsRange() {
+			hit.EndTime = m.EndTime
+		}
+		hits = append(hits, hit)
+	}
+	return hits, truncated, nil
+}
+
+func (s *searchService) searchAnnotations(ctx context.Context, filter bson.M) ([]model.SearchHit, bool, error) {
+	cursor, err := database.GetCollection("annotations").Find(ctx, filter, searchOptions())
+	if err != nil {
+		return nil, false, err
+	}
+	var annotations []model.Annotation
+	if err := cursor.All(ctx, &annotations); err != nil {
+		return nil, false, err
+	}
+	if len(annotations) == 0 {
+		return nil, false, nil
+	}
+	truncated := len(annotations) > searchCandidateLimit
+	if truncated {
+		annotations = annotations[:searchCandidateLimit]
+	}
+
+	// 注释跳转到所属标记的时间
+	markIDs := make([]primitive.ObjectID, 0, len(annotations))
+	for _, a := range annotations {
+		markIDs = append(markIDs, a.MarkID)
+	}
+	cursor, err = database.GetCollection("marks").Find(ctx, bson.M{"_id": bson.M{"$in": markIDs}},
+		options.Find().SetProjection(bson.M{"video_id": 1, "timestamp": 1, "end_time": 1}),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	var marks []model.Mark
+	if err := cursor.All(ctx, &marks); err != nil {
+		return nil, false, err
+	}
+	byID := make(map[primitive.ObjectID]model.Mark, len(marks))
+	for _, m := range marks {
+		byID[m.ID] = m
+	}
+
+	hits := make([]model.SearchHit, 0, len(annotations))
+	for _, a := range annotations {
+		mark, ok := byID[a.MarkID]
+		if !ok {
+			continue // 孤立注释，由 repair-annotations 任务清理
+		}
+		hit := model.SearchHit{
+			Type:      model.SearchTypeAnnotation,
+			ID:        a.ID.Hex(),
+			MarkID:    a.MarkID.Hex(),
+			VideoID:   mark.VideoID,
+			Timestamp: mark.Timestamp,
+			Content:   a.Content,
+			CreatedAt: a.CreatedAt,
+		}
+		if mark.IsRange() {
+			hit.EndTime = mark.EndTime
+		}
+		hits = append(hits, hit)
+	}
+	return hits, truncated, nil
+}
+
+func (s *searchService) searchNotes(ctx context.Context, filter bson.M) ([]model.SearchHit, bool, error) {
+	cursor, err := database.GetCollection("notes").Find(ctx, filter, searchOptions())
+	if err != nil {
+		return nil, false, err
+	}
+	var notes []model.Note
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, false, err
+	}
+	truncated := len(notes) > searchCandidateLimit
+	if truncated {
+		notes = notes[:searchCandidateLimit]
+	}
+
+	hits := make([]model.SearchHit, 0, len(notes))
+	for _, n := range notes {
+		hits = append(hits, model.SearchHit{
+			Type:      model.SearchTypeNote,
+			ID:        n.ID.Hex(),
+			VideoID:   n.VideoID,
+			Timestamp: n.Timestamp,
+			Content:   n.Content,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return hits, truncated, nil
+}
+
+// fillSearchVideoTitles 补充搜索结果的视频标题，已删除的视频标题为空
+func fillSearchVideoTitles(ctx context.Context, hits []model.SearchHit) error {
+	ids := make([]string, 0, len(hits))
+	for _, h := range hits {
+		if primitive.IsValidObjectID(h.VideoID) {
+			ids = append(ids, h.VideoID)
+		}
+	}
+	ids = uniqueStrings(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	titles, err := loadVideoTitles(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range hits {
+		hits[i].VideoTitle = titles[hits[i].VideoID]
+	}
+	return nil
+}
+
+// searchTerms 对查询分词：英文和数字按单词切分并转为小写，
+// 连续的中文超过两个字时切分为相邻的双字词，以便匹配词序不同或中间有其他字的内容
+func searchTerms(keyword string) []string {
+	var terms []string
+	var run []rune
+	han := false
+
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if han && len(run) > 2 {
+			for i := 0; i+1 < len(run); i++ {
+				terms = append(terms, string(run[i:i+2]))
+			}
+		} else {
+			terms = append(terms, string(run))
+		}
+		run = run[:0]
+	}
+
+	for _, r := range keyword {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if !han {
+				flush()
+				han = true
+			}
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if han {
+				flush()
+				han = false
+			}
+			run = append(run, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	terms = uniqueStrings(terms)
+	if len(terms) > searchTermLimit {
+		terms = terms[:searchTermLimit]
+	}
+	return terms
+}
+
+// highlightTerms 在内容中标出所有命中的分词，返回转义后的高亮片段和命中次数。
+// 重叠或相邻的命中合并为一段，内容过长时截取第一个命中附近的片段
+func highlightTerms(content string, terms []string) (string, int) {
+	text := []rune(content)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 每个位置是否命中
+	marked := make([]bool, len(text))
+	count := 0
+	for _, term := range terms {
+		t := []rune(term)
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == term {
+				count++
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	start, end := 0, len(text)
+	if len(text) > searchSnippetLength {
+		first := 0
+		for first < len(marked) && !marked[first] {
+			first++
+		}
+		if first == len(marked) {
+			first = 0
+		}
+		start = first - searchSnippetLength/4
+		if start < 0 {
+			start = 0
+		}
+		end = start + searchSnippetLength
+		if end > len(text) {
+			end = len(text)
+			start = end - searchSnippetLength
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(text[i:j]))
+		if marked[i] {
+			b.WriteString("<em>" + segment + "</em>")
+		} else {
+			b.WriteString(segment)
+		}
+		i = j
+	}
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String(), count
+}
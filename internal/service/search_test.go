@@ -0,0 +1,49 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// 测试查询分词：英文按单词，中文切分为双字词
+func TestSearchTerms(t *testing.T) {
+	assert.Equal(t, []string{"线性", "性代", "代数"}, searchTerms("线性代数"))
+	assert.Equal(t, []string{"特征", "svd", "分解"}, searchTerms("特征 SVD分解"))
+	assert.Equal(t, []string{"go", "1", "23"}, searchTerms("Go 1.23，"))
+	assert.Empty(t, searchTerms(" ，。 "))
+	assert.Len(t, searchTerms(strings.Repeat("字", 30)), 1)
+	assert.Len(t, searchTerms("一二三四五六七八九十甲乙丙丁"), searchTermLimit)
+}
+
+// 测试高亮：合并相邻命中并转义HTML
+func TestHighlightTerms(t *testing.T) {
+	highlight, count := highlightTerms("<b>线性代数</b>里的特征值", searchTerms("线性代数"))
+	assert.Equal(t, "&lt;b&gt;<em>线性代数</em>&lt;/b&gt;里的特征值", highlight)
+	assert.Equal(t, 3, count)
+
+	highlight, count = highlightTerms("Use SVD or svd", []string{"svd"})
+	assert.Equal(t, "Use <em>SVD</em> or <em>svd</em>", highlight)
+	assert.Equal(t, 2, count)
+
+	// 长内容截取第一个命中附近的片段
+	content := strings.Repeat("甲", 100) + "特征值" + strings.Repeat("乙", 100)
+	highlight, _ = highlightTerms(content, []string{"特征"})
+	assert.True(t, strings.HasPrefix(highlight, "…"+strings.Repeat("甲", 20)+"<em>特征</em>值"))
+	assert.True(t, strings.HasSuffix(highlight, "乙…"))
+}
+
+// 测试搜索条件：分词、视频和日期过滤
+func TestSearchFilter(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	filter := searchFilter("u1", []string{"a.b"}, &model.SearchQuery{VideoID: "v1", From: from, To: from})
+
+	assert.Equal(t, "u1", filter["user_id"])
+	assert.Equal(t, "v1", filter["video_id"])
+	assert.Equal(t, []bson.M{{"content": bson.M{"$regex": `a\.b`, "$options": "i"}}}, filter["$and"])
+	assert.Equal(t, bson.M{"$gte": from, "$lt": from.Add(24 * time.Hour)}, filter["created_at"])
+}
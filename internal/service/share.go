@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// 分享相关错误
+var (
+	ErrShareLinkNotFound = errors.New("分享链接不存在")
+	// ErrShareVideoUnavailable 分享的视频已删除、下架或不再公开
+	ErrShareVideoUnavailable = errors.New("分享的视频不存在或已下架")
+)
+
+const (
+	shareCodeLength   = 8
+	shareCodeAlphabet = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ" // 去掉了易混淆的 0/1/l/o/I/O
+	shareCodeRetries  = 5
+)
+
+// ShareService 分享服务接口
+type ShareService interface {
+	Create(ctx context.Context, userID, videoID, channel string) (*model.ShareLinkResponse, error)
+	Resolve(ctx context.Context, code string) (*model.ShareLink, error)
+	RecordView(ctx context.Context, code, videoID string) error
+	GetStats(ctx context.Context, userID, videoID string) (*model.ShareStatsResponse, error)
+}
+
+type shareService struct {
+	collection string
+}
+
+// NewShareService 创建分享服务实例
+func NewShareService() ShareService {
+	return &shareService{
+		collection: "share_links",
+	}
+}
+
+// Create 创建分享链接，同一用户（游客视为同一用户）在同一渠道分享同一视频时复用已有链接。
+// 视频的分享数按 (用户, 视频, 渠道) 只计一次，游客分享不计入，避免重复调用刷高分享数和热度
+func (s *shareService) Create(ctx context.Context, userID, videoID, channel string) (*model.ShareLinkResponse, error) {
+	if !model.IsValidShareChannel(channel) {
+		return nil, errors.New("无效的分享渠道")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, errors.New("无效的视频ID")
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		return nil, errors.New("视频不存在")
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != userID {
+		return nil, errors.New("无权分享该视频")
+	}
+
+	link, created, err := s.findOrCreateLink(ctx, userID, videoID, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if created && userID != "" {
+		// 记录分享事件，重建排行榜时按分享时间回放
+		if _, err := database.GetCollection("share_events").InsertOne(ctx, model.ShareEvent{
+			VideoID:   videoID,
+			UserID:    userID,
+			Channel:   channel,
+			CreatedAt: link.CreatedAt,
+		}); err != nil {
+			return nil, err
+		}
+
+		// 更新视频分享数
+		if _, err := database.GetCollection("videos").UpdateOne(ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$inc": bson.M{"stats.shares": 1}},
+		); err != nil {
+			return nil, err
+		}
+		recordTrendingAsync(video, model.RankingEventShare)
+	}
+
+	return &model.ShareLinkResponse{
+		Code:    link.Code,
+		URL:     ShareURL(link.Code),
+		Channel: link.Channel,
+	}, nil
+}
+
+// findOrCreateLink 查找用户在该渠道分享该视频的链接，不存在时创建。
+// 登录用户的链接由 (video_id, user_id, channel) 唯一索引保证并发时只创建一次，created 表示本次新建了链接
+func (s *shareService) findOrCreateLink(ctx context.Context, userID, videoID, channel string) (*model.ShareLink, bool, error) {
+	collection := database.GetCollection(s.collection)
+	filter := bson.M{"video_id": videoID, "user_id": userID, "channel": channel}
+
+	var link model.ShareLink
+	err := collection.FindOne(ctx, filter).Decode(&link)
+	if err == nil {
+		return &link, false, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, false, err
+	}
+
+	code, err := s.generateCode(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now()
+	link = model.ShareLink{
+		Code:      code,
+		VideoID:   videoID,
+		UserID:    userID,
+		Channel:   channel,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if userID != "" {
+		link.Shares = 1
+	}
+	if _, err := collection.InsertOne(ctx, link); err != nil {
+		if !mongo.IsDuplicateKeyError(err) || userID == "" {
+			return nil, false, err
+		}
+		// 并发请求已经创建了链接
+		if err := collection.FindOne(ctx, filter).Decode(&link); err != nil {
+			return nil, false, err
+		}
+		return &link, false, nil
+	}
+	return &link, true, nil
+}
+
+// Resolve 解析短链接并记录一次点击，视频不再公开（包括被下架）时链接失效
+func (s *shareService) Resolve(ctx context.Context, code string) (*model.ShareLink, error) {
+	collection := database.GetCollection(s.collection)
+	var link model.ShareLink
+	err := collection.FindOne(ctx, bson.M{"code": code}).Decode(&link)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(link.VideoID)
+	if err != nil {
+		return nil, ErrShareVideoUnavailable
+	}
+	count, err := database.GetCollection("videos").CountDocuments(ctx, bson.M{
+		"_id":    objectID,
+		"status": model.VideoStatusPublic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrShareVideoUnavailable
+	}
+
+	if _, err := collection.UpdateOne(ctx, bson.M{"_id": link.ID}, bson.M{"$inc": bson.M{"clicks": 1}}); err != nil {
+		return nil, err
+	}
+	link.Clicks++
+	return &link, nil
+}
+
+// RecordView 记录一次由分享链接带来的播放
+func (s *shareService) RecordView(ctx context.Context, code, videoID string) error {
+	result, err := database.GetCollection(s.collection).UpdateOne(ctx,
+		bson.M{"code": code, "video_id": videoID},
+		bson.M{"$inc": bson.M{"views": 1}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// GetStats 获取视频按渠道的分享统计，仅视频作者可查看
+func (s *shareService) GetStats(ctx context.Context, userID, videoID string) (*model.ShareStatsResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, errors.New("无效的视频ID")
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		return nil, errors.New("视频不存在")
+	}
+	if video.UserID != userID {
+		return nil, errors.New("无权查看该视频的分享统计")
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"video_id": videoID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$channel",
+			"links":  bson.M{"$sum": 1},
+			"shares": bson.M{"$sum": "$shares"},
+			"clicks": bson.M{"$sum": "$clicks"},
+			"views":  bson.M{"$sum": "$views"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"shares": -1}}},
+	}
+	cursor, err := database.GetCollection(s.collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	channels := make([]model.ShareChannelStats, 0)
+	if err := cursor.All(ctx, &channels); err != nil {
+		return nil, err
+	}
+
+	stats := &model.ShareStatsResponse{
+		VideoID:  videoID,
+		Shares:   video.Stats.Shares,
+		Channels: channels,
+	}
+	for _, ch := range channels {
+		stats.Clicks += ch.Clicks
+		stats.Views += ch.Views
+	}
+	return stats, nil
+}
+
+// generateCode 生成未被占用的短链接码
+func (s *shareService) generateCode(ctx context.Context) (string, error) {
+	collection := database.GetCollection(s.collection)
+	for i := 0; i < shareCodeRetries; i++ {
+		code, err := randomShareCode(shareCodeLength)
+		if err != nil {
+			return "", err
+		}
+		count, err := collection.CountDocuments(ctx, bson.M{"code": code})
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("生成分享链接失败，请重试")
+}
+
+// randomShareCode 生成指定长度的随机短链接码
+func randomShareCode(length int) (string, error) {
+	max := big.NewInt(int64(len(shareCodeAlphabet)))
+	var sb strings.Builder
+	sb.Grow(length)
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(shareCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// ShareURL 根据短链接码生成完整的短链接
+func ShareURL(code string) string {
+	return fmt.Sprintf("%s/s/%s", strings.TrimRight(config.GlobalConfig.Share.BaseURL, "/"), code)
+}
+
+// ShareLandingURL 短链接跳转的目标地址，附带分享码用于归因播放
+func ShareLandingURL(link *model.ShareLink) string {
+	target := fmt.Sprintf(config.GlobalConfig.Share.LandingURL, link.VideoID)
+	sep := "?"
+	if strings.Contains(target, "?") {
+		sep = "&"
+	}
+	return target + sep + "share=" + link.Code
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"video-platform/config"
+	"video-platform/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试短链接码的长度和字符集
+func TestRandomShareCode(t *testing.T) {
+	code, err := randomShareCode(shareCodeLength)
+
+	assert.Nil(t, err)
+	assert.Len(t, code, shareCodeLength)
+	for _, ch := range code {
+		assert.True(t, strings.ContainsRune(shareCodeAlphabet, ch))
+	}
+}
+
+// 测试短链接及跳转地址的生成
+func TestShareURLs(t *testing.T) {
+	config.GlobalConfig.Share = config.ShareConfig{
+		BaseURL:    "https://v.example.com/",
+		LandingURL: "/api/v1/videos/%s/stream",
+	}
+	link := &model.ShareLink{Code: "abc123", VideoID: "video1"}
+
+	assert.Equal(t, "https://v.example.com/s/abc123", ShareURL(link.Code))
+	assert.Equal(t, "/api/v1/videos/video1/stream?share=abc123", ShareLandingURL(link))
+
+	// 目标地址已带查询参数时追加分享码
+	config.GlobalConfig.Share.LandingURL = "/watch?v=%s"
+	assert.Equal(t, "/watch?v=video1&share=abc123", ShareLandingURL(link))
+}
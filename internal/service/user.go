@@ -22,6 +22,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrUserDisabled 用户已被禁用
+var ErrUserDisabled = errors.New("账号已被禁用")
+
 // UserService 用户服务接口
 type UserService interface {
 	Register(ctx context.Context, req *model.RegisterRequest) (*model.User, error)
@@ -31,11 +34,14 @@ type UserService interface {
 	GetUserProfile(ctx context.Context, id string) (*model.UserProfileResponse, error)
 	UpdateUserProfile(ctx context.Context, id string, req *model.UpdateProfileRequest, avatar *multipart.FileHeader) (*model.UserProfileResponse, error)
 	GetWatchHistory(ctx context.Context, id string, page, size int) (*model.WatchHistoryResponse, error)
-	GetFavorites(ctx context.Context, id string, page, size int) (*model.FavoriteResponse, error)
-	AddToFavorites(ctx context.Context, userID, videoID string) error
+	GetFavorites(ctx context.Context, id, folderID string, page, size int) (*model.FavoriteResponse, error)
+	AddToFavorites(ctx context.Context, userID, videoID, folderID string) error
 	RemoveFromFavorites(ctx context.Context, userID, videoID string) error
-	RecordWatchHistory(ctx context.Context, userID, videoID string) error
+	RecordWatchHistory(ctx context.Context, userID, videoID string, progress float64) error
 	CheckFavoriteStatus(ctx context.Context, userID, videoID string) (bool, error)
+	LikeVideo(ctx context.Context, userID, videoID string) error
+	UnlikeVideo(ctx context.Context, userID, videoID string) error
+	CheckLikeStatus(ctx context.Context, userID, videoID string) (bool, error)
 	LoginOrRegisterByPhone(ctx context.Context, phone string) (*model.User, string, error)
 }
 
@@ -97,6 +103,9 @@ func (s *userService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
 		return nil, "", errors.New("密码错误")
 	}
+	if user.Status == model.UserStatusDisabled {
+		return nil, "", ErrUserDisabled
+	}
 
 	// 生成 JWT token
 	token, err := utils.GenerateToken(user.ID.Hex(), user.Username)
@@ -183,8 +192,7 @@ func (s *userService) getUserStats(ctx context.Context, userID string) (*model.U
 		return nil, err
 	}
 
-	// 获取获得的总点赞数
-	// 假设videos集合中有stats字段，包含likes
+	// 获取获得的总点赞数（点赞与收藏分开统计）
 	pipeline := []bson.M{
 		{"$match": bson.M{"user_id": userID}},
 		{"$group": bson.M{
@@ -237,10 +245,25 @@ func (s *userService) getUserStats(ctx context.Context, userID string) (*model.U
 		totalWatchTime = int64(result[0]["totalWatchTime"].(float64) / 60)
 	}
 
+	// 获取关注数和粉丝数
+	followStats, err := loadFollowStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取稍后再看数
+	watchLaterCount, err := database.GetCollection("watch_later").CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
 	return &model.UserStats{
 		UploadedVideos: videosCount,
 		TotalLikes:     totalLikes,
 		TotalWatchTime: totalWatchTime,
+		Following:      followStats.Following,
+		Followers:      followStats.Followers,
+		WatchLater:     watchLaterCount,
 	}, nil
 }
 
@@ -257,6 +280,63 @@ func (s *userService) getUserProfileData(ctx context.Context, userID string) (*m
 	return &profile, nil
 }
 
+// loadUserBriefs 批量获取用户简要信息，返回以用户ID为键的映射
+func loadUserBriefs(ctx context.Context, userIDs []string) (map[string]model.UserBrief, error) {
+	briefs := make(map[string]model.UserBrief, len(userIDs))
+	if len(userIDs) == 0 {
+		return briefs, nil
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+		briefs[id] = model.UserBrief{ID: id}
+	}
+
+	// 查询用户基本信息
+	cursor, err := database.GetCollection("users").Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []model.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		brief := briefs[u.ID.Hex()]
+		brief.Username = u.Username
+		briefs[u.ID.Hex()] = brief
+	}
+
+	// 查询用户资料（昵称、头像）
+	cursor, err = database.GetCollection("user_profiles").Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var profiles []struct {
+		ID       string `bson:"_id"`
+		Nickname string `bson:"nickname"`
+		Avatar   string `bson:"avatar"`
+	}
+	if err = cursor.All(ctx, &profiles); err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		brief := briefs[p.ID]
+		brief.Nickname = p.Nickname
+		brief.Avatar = p.Avatar
+		briefs[p.ID] = brief
+	}
+
+	return briefs, nil
+}
+
 // UpdateUserProfile 更新用户资料
 func (s *userService) UpdateUserProfile(ctx context.Context, id string, req *model.UpdateProfileRequest, avatar *multipart.FileHeader) (*model.UserProfileResponse, error) {
 	// 获取用户基本信息
@@ -470,8 +550,8 @@ func (s *userService) GetWatchHistory(ctx context.Context, id string, page, size
 	}, nil
 }
 
-// GetFavorites 获取用户收藏列表
-func (s *userService) GetFavorites(ctx context.Context, id string, page, size int) (*model.FavoriteResponse, error) {
+// GetFavorites 获取用户收藏列表，folderID为空时返回全部收藏，为 model.FavoriteFolderDefault 时只返回默认收藏夹
+func (s *userService) GetFavorites(ctx context.Context, id, folderID string, page, size int) (*model.FavoriteResponse, error) {
 	collection := database.GetCollection("favorites")
 
 	// 设置默认分页参数
@@ -484,6 +564,13 @@ func (s *userService) GetFavorites(ctx context.Context, id string, page, size in
 
 	// 查询条件
 	filter := bson.M{"user_id": id}
+	switch folderID {
+	case "":
+	case model.FavoriteFolderDefault:
+		filter["folder_id"] = bson.M{"$exists": false}
+	default:
+		filter["folder_id"] = folderID
+	}
 
 	// 获取总数
 	total, err := collection.CountDocuments(ctx, filter)
@@ -519,8 +606,8 @@ func (s *userService) GetFavorites(ctx context.Context, id string, page, size in
 	}, nil
 }
 
-// AddToFavorites 添加到收藏
-func (s *userService) AddToFavorites(ctx context.Context, userID, videoID string) error {
+// AddToFavorites 添加到收藏，folderID为空表示放入默认收藏夹
+func (s *userService) AddToFavorites(ctx context.Context, userID, videoID, folderID string) error {
 	// 获取视频信息
 	videoCollection := database.GetCollection("videos")
 
@@ -547,6 +634,13 @@ func (s *userService) AddToFavorites(ctx context.Context, userID, videoID string
 		return errors.New("已经收藏过该视频")
 	}
 
+	// 检查收藏夹是否属于当前用户
+	if folderID != "" {
+		if _, err := findFavoriteFolder(ctx, userID, folderID); err != nil {
+			return err
+		}
+	}
+
 	// 创建会话
 	session, err := database.GetClient().StartSession()
 	if err != nil {
@@ -562,9 +656,10 @@ func (s *userService) AddToFavorites(ctx context.Context, userID, videoID string
 			UserID:        userID,
 			VideoID:       videoID,
 			VideoTitle:    video.Title,
-			CoverURL:      video.CoverURL,
+			CoverURL:      videoCover(video),
 			AddedAt:       time.Now(),
 			VideoDuration: video.Duration,
+			FolderID:      folderID,
 		}
 
 		_, err := collection.InsertOne(sessCtx, favorite)
@@ -572,10 +667,10 @@ func (s *userService) AddToFavorites(ctx context.Context, userID, videoID string
 			return nil, err
 		}
 
-		// 2. 更新视频的likes统计
+		// 2. 更新视频的favorites统计
 		update := bson.M{
 			"$inc": bson.M{
-				"stats.likes": 1,
+				"stats.favorites": 1,
 			},
 		}
 
@@ -587,8 +682,21 @@ func (s *userService) AddToFavorites(ctx context.Context, userID, videoID string
 
 		return nil, err
 	})
+	if err != nil {
+		return err
+	}
+
+	// 通知视频作者
+	notifyAsync(model.Notification{
+		UserID:     video.UserID,
+		Type:       model.NotificationTypeFavorite,
+		TargetType: model.NotificationTargetVideo,
+		TargetID:   videoID,
+		ActorIDs:   []string{userID},
+		Content:    video.Title,
+	})
 
-	return err
+	return nil
 }
 
 // RemoveFromFavorites 从收藏中移除
@@ -625,17 +733,17 @@ func (s *userService) RemoveFromFavorites(ctx context.Context, userID, videoID s
 			return nil, errors.New("收藏不存在")
 		}
 
-		// 2. 更新视频的likes统计（减1，但确保不会小于0）
+		// 2. 更新视频的favorites统计（减1，但确保不会小于0）
 		update := bson.M{
 			"$inc": bson.M{
-				"stats.likes": -1,
+				"stats.favorites": -1,
 			},
 		}
 
-		// 条件更新，确保likes不会小于0
+		// 条件更新，确保favorites不会小于0
 		filter := bson.M{
-			"_id":         objectID,
-			"stats.likes": bson.M{"$gt": 0},
+			"_id":             objectID,
+			"stats.favorites": bson.M{"$gt": 0},
 		}
 
 		_, err = videoCollection.UpdateOne(
@@ -679,17 +787,25 @@ func (s *userService) saveAvatarFile(file *multipart.FileHeader, dst string) err
 	return err
 }
 
-// RecordWatchHistory 记录用户观看历史
-func (s *userService) RecordWatchHistory(ctx context.Context, userID, videoID string) error {
+// RecordWatchHistory 记录用户观看历史，progress为观看进度（秒），小于0表示已看完
+func (s *userService) RecordWatchHistory(ctx context.Context, userID, videoID string, progress float64) error {
 	// 获取视频信息
 	videoCollection := database.GetCollection("videos")
 
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return fmt.Errorf("无效的ID格式: %w", err)
+	}
 	var video model.Video
-	err := videoCollection.FindOne(ctx, bson.M{"_id": videoID}).Decode(&video)
+	err = videoCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&video)
 	if err != nil {
 		return errors.New("视频不存在")
 	}
 
+	if progress < 0 || (video.Duration > 0 && progress > video.Duration) {
+		progress = video.Duration
+	}
+
 	// 检查是否已有观看记录
 	historyCollection := database.GetCollection("watch_history")
 
@@ -705,11 +821,10 @@ func (s *userService) RecordWatchHistory(ctx context.Context, userID, videoID st
 			"user_id":        userID,
 			"video_id":       videoID,
 			"video_title":    video.Title,
-			"cover_url":      video.CoverURL,
+			"cover_url":      videoCover(video),
 			"watched_at":     time.Now(),
 			"video_duration": video.Duration,
-			// 简单实现，不记录具体进度
-			"progress": video.Duration, // 假设看完了
+			"progress":       progress,
 		},
 	}
 
@@ -717,8 +832,13 @@ func (s *userService) RecordWatchHistory(ctx context.Context, userID, videoID st
 	opts := options.Update().SetUpsert(true)
 
 	// 执行更新
-	_, err = historyCollection.UpdateOne(ctx, filter, update, opts)
-	return err
+	if _, err = historyCollection.UpdateOne(ctx, filter, update, opts); err != nil {
+		return err
+	}
+
+	// 看完（达到阈值）后移出稍后再看
+	removeWatchedLaterAsync(userID, videoID, progress, video.Duration)
+	return nil
 }
 
 // CheckFavoriteStatus 检查视频是否被用户收藏
@@ -740,6 +860,139 @@ func (s *userService) CheckFavoriteStatus(ctx context.Context, userID, videoID s
 	return count > 0, nil
 }
 
+// LikeVideo 点赞视频，重复点赞不会重复计数
+func (s *userService) LikeVideo(ctx context.Context, userID, videoID string) error {
+	// 检查视频是否存在
+	videoCollection := database.GetCollection("videos")
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return fmt.Errorf("无效的ID格式: %w", err)
+	}
+	var video model.Video
+	if err := videoCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		return errors.New("视频不存在")
+	}
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中执行添加点赞和更新视频统计
+	liked := false
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		liked = false
+		// 1. 添加点赞记录，已存在时不做修改
+		result, err := database.GetCollection("likes").UpdateOne(
+			sessCtx,
+			bson.M{"user_id": userID, "video_id": videoID},
+			bson.M{"$setOnInsert": bson.M{
+				"user_id":    userID,
+				"video_id":   videoID,
+				"created_at": time.Now(),
+			}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// 已经点赞过，直接返回
+		if result.UpsertedCount == 0 {
+			return nil, nil
+		}
+
+		// 2. 更新视频的likes统计
+		_, err = videoCollection.UpdateOne(
+			sessCtx,
+			bson.M{"_id": objectID},
+			bson.M{"$inc": bson.M{"stats.likes": 1}},
+		)
+		liked = err == nil
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 首次点赞时通知视频作者并计入热门排行榜
+	if liked {
+		recordTrendingAsync(video, model.RankingEventLike)
+		notifyAsync(model.Notification{
+			UserID:     video.UserID,
+			Type:       model.NotificationTypeLike,
+			TargetType: model.NotificationTargetVideo,
+			TargetID:   videoID,
+			ActorIDs:   []string{userID},
+			Content:    video.Title,
+		})
+	}
+
+	return nil
+}
+
+// UnlikeVideo 取消点赞，未点赞时直接返回
+func (s *userService) UnlikeVideo(ctx context.Context, userID, videoID string) error {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return fmt.Errorf("无效的ID格式: %w", err)
+	}
+
+	// 创建会话
+	session, err := database.GetClient().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// 在事务中执行删除点赞和更新视频统计
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		// 1. 删除点赞记录
+		result, err := database.GetCollection("likes").DeleteOne(sessCtx, bson.M{
+			"user_id":  userID,
+			"video_id": videoID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// 未点赞过，直接返回
+		if result.DeletedCount == 0 {
+			return nil, nil
+		}
+
+		// 2. 更新视频的likes统计（条件更新，确保likes不会小于0）
+		_, err = database.GetCollection("videos").UpdateOne(
+			sessCtx,
+			bson.M{"_id": objectID, "stats.likes": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"stats.likes": -1}},
+		)
+		return nil, err
+	})
+
+	return err
+}
+
+// CheckLikeStatus 检查视频是否被用户点赞
+func (s *userService) CheckLikeStatus(ctx context.Context, userID, videoID string) (bool, error) {
+	// 如果没有userID或videoID则返回未点赞
+	if userID == "" || videoID == "" {
+		return false, nil
+	}
+
+	count, err := database.GetCollection("likes").CountDocuments(ctx, bson.M{
+		"user_id":  userID,
+		"video_id": videoID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 // LoginOrRegisterByPhone 通过手机号登录或注册
 func (s *userService) LoginOrRegisterByPhone(ctx context.Context, phone string) (*model.User, string, error) {
 	collection := database.GetCollection(s.collection)
@@ -773,6 +1026,9 @@ func (s *userService) LoginOrRegisterByPhone(ctx context.Context, phone string)
 			return nil, "", fmt.Errorf("数据库查询错误: %w", err)
 		}
 	}
+	if user.Status == model.UserStatusDisabled {
+		return nil, "", ErrUserDisabled
+	}
 	
 	// 生成 JWT token
 	token, err := utils.GenerateToken(user.ID.Hex(), user.Username)
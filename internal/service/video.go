@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -21,6 +22,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrVideoBlocked 视频已被审核员下架
+var ErrVideoBlocked = errors.New("视频已被下架，不能修改状态")
+
 // VideoService 视频服务接口
 type VideoService interface {
 	Upload(ctx context.Context, videoFile *multipart.FileHeader, coverFile *multipart.FileHeader, info model.Video) (*model.Video, error)
@@ -167,6 +171,9 @@ func (s *videoService) Upload(ctx context.Context, videoFile *multipart.FileHead
 		return nil, err
 	}
 
+	// 公开视频推送到粉丝的关注动态
+	fanOutVideoAsync(video)
+
 	return &video, nil
 }
 
@@ -298,6 +305,9 @@ func (s *videoService) Update(ctx context.Context, id string, video model.Video)
 		updateFields["description"] = video.Description
 	}
 	if video.Status != "" {
+		if !model.IsValidVideoStatus(video.Status) {
+			return errors.New("无效的视频状态")
+		}
 		updateFields["status"] = video.Status
 	}
 	if len(video.Tags) > 0 {
@@ -308,9 +318,22 @@ func (s *videoService) Update(ctx context.Context, id string, video model.Video)
 		"$set": updateFields,
 	}
 
+	// 已下架的视频只能由审核员恢复，作者不能修改状态
+	filter := bson.M{"_id": objectID}
+	if video.Status != "" {
+		blocked, err := database.GetCollection(s.collection).CountDocuments(ctx, bson.M{"_id": objectID, "status": model.VideoStatusBlocked})
+		if err != nil {
+			return err
+		}
+		if blocked > 0 {
+			return ErrVideoBlocked
+		}
+		filter["status"] = bson.M{"$ne": model.VideoStatusBlocked}
+	}
+
 	result, err := database.GetCollection(s.collection).UpdateOne(
 		ctx,
-		bson.M{"_id": objectID},
+		filter,
 		update,
 	)
 
@@ -322,6 +345,15 @@ func (s *videoService) Update(ctx context.Context, id string, video model.Video)
 		return errors.New("视频不存在")
 	}
 
+	// 发布视频变更事件，由订阅者同步冗余数据、推送关注动态等
+	fields := make([]string, 0, len(updateFields))
+	for _, field := range []string{VideoFieldTitle, VideoFieldDescription, VideoFieldStatus, VideoFieldTags} {
+		if _, ok := updateFields[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	s.publishChanged(ctx, objectID, fields...)
+
 	return nil
 }
 
@@ -346,9 +378,13 @@ func (s *videoService) Delete(ctx context.Context, id string) error {
 	}
 	defer session.EndSession(ctx)
 
+	// 笔记图片的文件在事务提交后删除，协作标注房间在事务提交后断开成员连接
+	var noteImages []model.NoteImage
+	var roomIDs []interface{}
+
 	// 在事务中执行所有删除操作
 	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
-		// 1. 删除相关收藏记录
+		// 1. 删除相关收藏、点赞记录
 		_, err := database.GetCollection("favorites").DeleteMany(
 			sessCtx,
 			bson.M{"video_id": id},
@@ -357,7 +393,15 @@ func (s *videoService) Delete(ctx context.Context, id string) error {
 			return nil, fmt.Errorf("删除收藏记录失败: %w", err)
 		}
 
-		// 2. 删除相关观看历史
+		_, err = database.GetCollection("likes").DeleteMany(
+			sessCtx,
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("删除点赞记录失败: %w", err)
+		}
+
+		// 2. 删除相关观看历史及稍后再看
 		_, err = database.GetCollection("watch_history").DeleteMany(
 			sessCtx,
 			bson.M{"video_id": id},
@@ -366,7 +410,41 @@ func (s *videoService) Delete(ctx context.Context, id string) error {
 			return nil, fmt.Errorf("删除观看历史失败: %w", err)
 		}
 
-		// 3. 删除相关评论
+		_, err = database.GetCollection("watch_later").DeleteMany(
+			sessCtx,
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("删除稍后再看失败: %w", err)
+		}
+
+		// 3. 删除相关评论及评论点赞
+		cursor, err := database.GetCollection("comments").Find(
+			sessCtx,
+			bson.M{"video_id": id},
+			options.Find().SetProjection(bson.M{"_id": 1}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("查询评论失败: %w", err)
+		}
+		var comments []model.Comment
+		if err = cursor.All(sessCtx, &comments); err != nil {
+			return nil, fmt.Errorf("查询评论失败: %w", err)
+		}
+		if len(comments) > 0 {
+			commentIDs := make([]primitive.ObjectID, 0, len(comments))
+			for _, c := range comments {
+				commentIDs = append(commentIDs, c.ID)
+			}
+			_, err = database.GetCollection("comment_likes").DeleteMany(
+				sessCtx,
+				bson.M{"comment_id": bson.M{"$in": commentIDs}},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("删除评论点赞失败: %w", err)
+			}
+		}
+
 		_, err = database.GetCollection("comments").DeleteMany(
 			sessCtx,
 			bson.M{"video_id": id},
@@ -375,7 +453,59 @@ func (s *videoService) Delete(ctx context.Context, id string) error {
 			return nil, fmt.Errorf("删除评论失败: %w", err)
 		}
 
-		// 4. 删除相关标记和注释
+		// 4. 删除相关弹幕、分享链接及播放列表记录
+		_, err = database.GetCollection("danmaku").DeleteMany(
+			sessCtx,
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("删除弹幕失败: %w", err)
+		}
+
+		_, err = database.GetCollection("share_links").DeleteMany(
+			sessCtx,
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("删除分享链接失败: %w", err)
+		}
+
+		// 从播放列表中移除并更新列表的视频数
+		playlistIDs, err := database.GetCollection("playlist_items").Distinct(
+			sessCtx,
+			"playlist_id",
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("查询播放列表失败: %w", err)
+		}
+		if len(playlistIDs) > 0 {
+			_, err = database.GetCollection("playlist_items").DeleteMany(
+				sessCtx,
+				bson.M{"video_id": id},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("删除播放列表记录失败: %w", err)
+			}
+			objectIDs := make([]primitive.ObjectID, 0, len(playlistIDs))
+			for _, playlistID := range playlistIDs {
+				if hex, ok := playlistID.(string); ok {
+					if objectID, err := primitive.ObjectIDFromHex(hex); err == nil {
+						objectIDs = append(objectIDs, objectID)
+					}
+				}
+			}
+			_, err = database.GetCollection("playlists").UpdateMany(
+				sessCtx,
+				bson.M{"_id": bson.M{"$in": objectIDs}, "item_count": bson.M{"$gt": 0}},
+				bson.M{"$inc": bson.M{"item_count": -1}},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("更新播放列表失败: %w", err)
+			}
+		}
+
+		// 5. 删除相关标记和注释
 		_, err = database.GetCollection("marks").DeleteMany(
 			sessCtx,
 			bson.M{"video_id": id},
@@ -392,7 +522,60 @@ func (s *videoService) Delete(ctx context.Context, id string) error {
 			return nil, fmt.Errorf("删除注释失败: %w", err)
 		}
 
-		// 5. 最后删除视频记录本身
+		// 6. 删除笔记中插入的图片记录
+		noteIDs, err := database.GetCollection("notes").Distinct(
+			sessCtx,
+			"_id",
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("查询笔记失败: %w", err)
+		}
+		noteImages = nil
+		if len(noteIDs) > 0 {
+			noteImages, err = deleteNoteImageRecords(sessCtx, bson.M{"note_id": bson.M{"$in": noteIDs}})
+			if err != nil {
+				return nil, fmt.Errorf("删除笔记图片失败: %w", err)
+			}
+		}
+
+		// 7. 删除笔记及其历史版本
+		_, err = database.GetCollection("note_revisions").DeleteMany(
+			sessCtx,
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("删除笔记历史版本失败: %w", err)
+		}
+
+		_, err = database.GetCollection("notes").DeleteMany(
+			sessCtx,
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("删除笔记失败: %w", err)
+		}
+
+		// 8. 删除协作标注房间
+		roomIDs, err = database.GetCollection("annotation_rooms").Distinct(
+			sessCtx,
+			"_id",
+			bson.M{"video_id": id},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("查询协作标注房间失败: %w", err)
+		}
+		if len(roomIDs) > 0 {
+			_, err = database.GetCollection("annotation_rooms").DeleteMany(
+				sessCtx,
+				bson.M{"_id": bson.M{"$in": roomIDs}},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("删除协作标注房间失败: %w", err)
+			}
+		}
+
+		// 9. 最后删除视频记录本身
 		result, err := database.GetCollection(s.collection).DeleteOne(
 			sessCtx,
 			bson.M{"_id": objectID},
@@ -412,6 +595,16 @@ func (s *videoService) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	// 删除笔记图片文件(事务提交后执行，避免事务回滚后图片丢失)
+	removeNoteImageFiles(noteImages)
+
+	// 断开已删除房间的成员连接
+	for _, roomID := range roomIDs {
+		if objectID, ok := roomID.(primitive.ObjectID); ok {
+			closeRoom(ctx, roomRelay, objectID.Hex())
+		}
+	}
+
 	// 删除视频文件(事务外执行文件系统操作)
 	filePath := filepath.Join(config.GlobalConfig.Storage.UploadDir, video.FileName)
 	if err := os.Remove(filePath); err != nil {
@@ -474,7 +667,7 @@ func (s *videoService) BatchOperation(ctx context.Context, req model.BatchOperat
 				result.SuccessCount++
 			}
 		case "update_status":
-			if !model.IsValidVideoStatus(req.Status) {
+			if !model.IsValidVideoStatus(req.Status) || video.Status == model.VideoStatusBlocked {
 				result.FailedCount++
 				result.FailedIDs = append(result.FailedIDs, id)
 				continue
@@ -498,6 +691,8 @@ func (s *videoService) BatchOperation(ctx context.Context, req model.BatchOperat
 				result.FailedIDs = append(result.FailedIDs, id)
 			} else {
 				result.SuccessCount++
+				video.Status = req.Status
+				publishVideoChanged(video, VideoFieldStatus)
 			}
 		default:
 			return nil, errors.New("不支持的操作类型")
@@ -583,9 +778,24 @@ func (s *videoService) UpdateThumbnail(ctx context.Context, id string, file *mul
 		return "", err
 	}
 
+	s.publishChanged(ctx, objectID, VideoFieldCover)
+
 	return thumbnailURL, nil
 }
 
+// publishChanged 读取变更后的视频并发布变更事件
+func (s *videoService) publishChanged(ctx context.Context, objectID primitive.ObjectID, fields ...string) {
+	if len(fields) == 0 {
+		return
+	}
+	var video model.Video
+	if err := database.GetCollection(s.collection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		slog.Warn("[publishVideoChanged] 读取视频失败", "error", err, "videoId", objectID.Hex())
+		return
+	}
+	publishVideoChanged(video, fields...)
+}
+
 // GetStats 获取视频统计信息
 func (s *videoService) GetStats(ctx context.Context, id string) (*model.VideoStats, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -615,13 +825,22 @@ func (s *videoService) IncrementStats(ctx context.Context, id string, field stri
 		},
 	}
 
-	_, err = database.GetCollection(s.collection).UpdateOne(
+	var video model.Video
+	err = database.GetCollection(s.collection).FindOneAndUpdate(
 		ctx,
 		bson.M{"_id": objectID},
 		update,
-	)
+		options.FindOneAndUpdate().SetProjection(bson.M{"status": 1, "tags": 1}),
+	).Decode(&video)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// 播放计入热门排行榜
+	if field == "views" {
+		recordTrendingAsync(video, model.RankingEventView)
+	}
+	return nil
 }
 
 // ListOptions 视频列表查询选项
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"video-platform/config"
+	"video-platform/internal/model"
+	"video-platform/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrWatchLaterExists   = errors.New("视频已在稍后再看中")
+	ErrWatchLaterNotFound = errors.New("视频不在稍后再看中")
+	ErrWatchLaterFull     = errors.New("稍后再看中的视频数已达上限")
+)
+
+// watchLaterJobTimeout 异步清理任务的超时时间
+const watchLaterJobTimeout = 10 * time.Second
+
+// WatchLaterService 稍后再看服务接口
+type WatchLaterService interface {
+	List(ctx context.Context, userID string, page, size int) (*model.WatchLaterResponse, error)
+	Add(ctx context.Context, userID, videoID string) (*model.WatchLater, error)
+	Remove(ctx context.Context, userID, videoID string) error
+	Move(ctx context.Context, userID, videoID string, index int) error
+}
+
+type watchLaterService struct {
+	collection string
+}
+
+// NewWatchLaterService 创建稍后再看服务实例
+func NewWatchLaterService() WatchLaterService {
+	return &watchLaterService{
+		collection: "watch_later",
+	}
+}
+
+// List 按顺序分页获取稍后再看列表
+func (s *watchLaterService) List(ctx context.Context, userID string, page, size int) (*model.WatchLaterResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 50 {
+		size = 20
+	}
+
+	collection := database.GetCollection(s.collection)
+	filter := bson.M{"user_id": userID}
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "position", Value: 1}, {Key: "added_at", Value: 1}}).
+		SetSkip(int64((page - 1) * size)).
+		SetLimit(int64(size))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	items := []model.WatchLater{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return &model.WatchLaterResponse{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  size,
+	}, nil
+}
+
+// Add 将视频添加到稍后再看末尾
+func (s *watchLaterService) Add(ctx context.Context, userID, videoID string) (*model.WatchLater, error) {
+	objectID, err := primitive.ObjectIDFromHex(videoID)
+	if err != nil {
+		return nil, errors.New("无效的视频ID")
+	}
+	var video model.Video
+	if err := database.GetCollection("videos").FindOne(ctx, bson.M{"_id": objectID}).Decode(&video); err != nil {
+		return nil, errors.New("视频不存在")
+	}
+	if video.Status != model.VideoStatusPublic && video.UserID != userID {
+		return nil, errors.New("无权添加该视频")
+	}
+
+	collection := database.GetCollection(s.collection)
+	exists, err := collection.CountDocuments(ctx, bson.M{"user_id": userID, "video_id": videoID})
+	if err != nil {
+		return nil, err
+	}
+	if exists > 0 {
+		return nil, ErrWatchLaterExists
+	}
+	count, err := collection.CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if maxItems := config.GlobalConfig.WatchLater.MaxItems; maxItems > 0 && count >= maxItems {
+		return nil, ErrWatchLaterFull
+	}
+
+	// 排在当前最后一个视频之后
+	position := int64(0)
+	var last model.WatchLater
+	err = collection.FindOne(ctx,
+		bson.M{"user_id": userID},
+		options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}}),
+	).Decode(&last)
+	if err == nil {
+		position = last.Position + 1
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	item := &model.WatchLater{
+		ID:            primitive.NewObjectID(),
+		UserID:        userID,
+		VideoID:       videoID,
+		VideoTitle:    video.Title,
+		CoverURL:      videoCover(video),
+		VideoDuration: video.Duration,
+		Position:      position,
+		AddedAt:       time.Now(),
+	}
+
+	// 并发重复添加由 (user_id, video_id) 唯一索引拦截
+	if _, err := collection.InsertOne(ctx, item); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrWatchLaterExists
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// Remove 从稍后再看中移除视频
+func (s *watchLaterService) Remove(ctx context.Context, userID, videoID string) error {
+	result, err := database.GetCollection(s.collection).DeleteOne(ctx, bson.M{"user_id": userID, "video_id": videoID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrWatchLaterNotFound
+	}
+	return nil
+}
+
+// Move 拖动排序：将视频移动到指定位置，并重写受影响区间内视频的位置
+func (s *watchLaterService) Move(ctx context.Context, userID, videoID string, index int) error {
+	collection := database.GetCollection(s.collection)
+	cursor, err := collection.Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().
+			SetSort(bson.D{{Key: "position", Value: 1}, {Key: "added_at", Value: 1}}).
+			SetProjection(bson.M{"video_id": 1, "position": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	var items []model.WatchLater
+	if err := cursor.All(ctx, &items); err != nil {
+		return err
+	}
+
+	videoIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		videoIDs = append(videoIDs, item.VideoID)
+	}
+	ordered, ok := moveToIndex(videoIDs, videoID, index)
+	if !ok {
+		return ErrWatchLaterNotFound
+	}
+
+	models := make([]mongo.WriteModel, 0)
+	for i, id := range ordered {
+		if items[i].VideoID == id && items[i].Position == int64(i) {
+			continue
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": userID, "video_id": id}).
+			SetUpdate(bson.M{"$set": bson.M{"position": int64(i)}}))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	_, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// moveToIndex 将元素移动到指定位置，返回新的顺序；元素不存在时返回false
+func moveToIndex(ids []string, id string, index int) ([]string, bool) {
+	from := -1
+	for i, v := range ids {
+		if v == id {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		return nil, false
+	}
+
+	ordered := make([]string, 0, len(ids))
+	ordered = append(ordered, ids[:from]...)
+	ordered = append(ordered, ids[from+1:]...)
+	if index > len(ordered) {
+		index = len(ordered)
+	}
+	ordered = append(ordered[:index], append([]string{id}, ordered[index:]...)...)
+	return ordered, true
+}
+
+// watchedEnough 观看进度是否达到自动移出稍后再看的阈值
+func watchedEnough(progress, duration float64) bool {
+	percent := config.GlobalConfig.WatchLater.AutoRemovePercent
+	if percent <= 0 || duration <= 0 {
+		return false
+	}
+	return progress >= duration*float64(percent)/100
+}
+
+// removeWatchedLaterAsync 观看进度达到阈值后异步将视频移出稍后再看
+func removeWatchedLaterAsync(userID, videoID string, progress, duration float64) {
+	if !watchedEnough(progress, duration) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), watchLaterJobTimeout)
+		defer cancel()
+		if _, err := database.GetCollection("watch_later").DeleteOne(ctx, bson.M{"user_id": userID, "video_id": videoID}); err != nil {
+			slog.Warn("[removeWatchedLater] 移出稍后再看失败", "error", err, "userId", userID, "videoId", videoID)
+		}
+	}()
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+	"video-platform/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试拖动排序后的顺序
+func TestMoveToIndex(t *testing.T) {
+	ids := []string{"a", "b", "c", "d"}
+
+	ordered, ok := moveToIndex(ids, "d", 0)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"d", "a", "b", "c"}, ordered)
+
+	ordered, _ = moveToIndex(ids, "a", 2)
+	assert.Equal(t, []string{"b", "c", "a", "d"}, ordered)
+
+	ordered, _ = moveToIndex(ids, "b", 100)
+	assert.Equal(t, []string{"a", "c", "d", "b"}, ordered)
+
+	// 原切片不受影响
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids)
+
+	_, ok = moveToIndex(ids, "x", 0)
+	assert.False(t, ok)
+}
+
+// 测试观看进度达到阈值的判断
+func TestWatchedEnough(t *testing.T) {
+	original := config.GlobalConfig.WatchLater
+	defer func() { config.GlobalConfig.WatchLater = original }()
+
+	config.GlobalConfig.WatchLater.AutoRemovePercent = 90
+	assert.True(t, watchedEnough(90, 100))
+	assert.True(t, watchedEnough(100, 100))
+	assert.False(t, watchedEnough(89, 100))
+	assert.False(t, watchedEnough(10, 0))
+
+	config.GlobalConfig.WatchLater.AutoRemovePercent = 0
+	assert.False(t, watchedEnough(100, 100))
+}
@@ -0,0 +1,35 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试常用Markdown语法的渲染
+func TestRender(t *testing.T) {
+	assert.Equal(t, "<p><strong>重点</strong><br>\n第二行</p>\n", Render("**重点**\n第二行"))
+	assert.Contains(t, Render("- [x] 完成"), `<input checked="" disabled="" type="checkbox">`)
+	assert.Contains(t, Render("![图](/uploads/note_a.png)"), `<img src="/uploads/note_a.png" alt="图">`)
+	assert.Contains(t, Render("[站外](https://example.com)"), `target="_blank"`)
+	assert.Contains(t, Render("| a |\n|---|\n| 1 |"), "<table>")
+}
+
+// 测试过滤XSS
+func TestRenderSanitize(t *testing.T) {
+	cases := []string{
+		"<script>alert(1)</script>",
+		`<img src=x onerror="alert(1)">`,
+		"[点我](javascript:alert(1))",
+		`![x](javascript:alert(1))`,
+		`<a href="#" onclick="alert(1)">x</a>`,
+	}
+	for _, source := range cases {
+		out := strings.ToLower(Render(source))
+		assert.NotContains(t, out, "<script", source)
+		assert.NotContains(t, out, "onerror", source)
+		assert.NotContains(t, out, "onclick", source)
+		assert.NotContains(t, out, "javascript:", source)
+	}
+}
@@ -0,0 +1,226 @@
+package ws
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second    // 写超时
+	pongWait       = 60 * time.Second    // 等待pong的超时
+	pingPeriod     = (pongWait * 9) / 10 // 发送ping的周期，必须小于pongWait
+	maxMessageSize = 4096                // 单条消息最大字节数
+	sendBufferSize = 256                 // 发送缓冲区大小
+)
+
+// Upgrader 将HTTP连接升级为WebSocket连接，跨域策略与CORSMiddleware保持一致
+var Upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// Message 推送给客户端的消息
+type Message struct {
+	Type string      `json:"type"` // 消息类型
+	Data interface{} `json:"data"` // 消息内容
+}
+
+// Hub 按房间管理WebSocket连接
+type Hub struct {
+	mu    sync.RWMutex
+	rooms map[string]map[*Client]struct{}
+}
+
+// NewHub 创建Hub实例
+func NewHub() *Hub {
+	return &Hub{
+		rooms: make(map[string]map[*Client]struct{}),
+	}
+}
+
+// Join 加入房间
+func (h *Hub) Join(room string, c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients, ok := h.rooms[room]
+	if !ok {
+		clients = make(map[*Client]struct{})
+		h.rooms[room] = clients
+	}
+	clients[c] = struct{}{}
+}
+
+// Leave 离开房间，房间为空时删除
+func (h *Hub) Leave(room string, c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if clients, ok := h.rooms[room]; ok {
+		delete(clients, c)
+		if len(clients) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Count 获取房间内的连接数
+func (h *Hub) Count(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Broadcast 向房间内所有连接发送消息
+func (h *Hub) Broadcast(room string, msg Message) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("[Hub.Broadcast] 消息序列化失败", "error", err, "room", room)
+		return
+	}
+	h.BroadcastRaw(room, payload)
+}
+
+// BroadcastRaw 向房间内所有连接发送已序列化的消息
+func (h *Hub) BroadcastRaw(room string, payload []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.rooms[room] {
+		c.SendRaw(payload)
+	}
+}
+
+// DisconnectRaw 向房间内所有连接发送最后一条消息后关闭连接，连接的读写循环随之退出
+func (h *Hub) DisconnectRaw(room string, payload []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.rooms[room] {
+		c.SendRaw(payload)
+		c.Close()
+	}
+}
+
+// Client 单个WebSocket连接
+type Client struct {
+	conn   *websocket.Conn
+	send   chan []byte
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewClient 创建连接实例
+func NewClient(conn *websocket.Conn) *Client {
+	return &Client{
+		conn: conn,
+		send: make(chan []byte, sendBufferSize),
+	}
+}
+
+// Send 发送消息
+func (c *Client) Send(msg Message) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("[Client.Send] 消息序列化失败", "error", err)
+		return
+	}
+	c.SendRaw(payload)
+}
+
+// SendRaw 发送已序列化的消息，缓冲区已满时丢弃消息并关闭连接，避免慢连接阻塞广播
+func (c *Client) SendRaw(payload []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- payload:
+	default:
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// Close 关闭连接
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// Run 启动读写循环，阻塞直到连接关闭；onMessage为nil时忽略客户端发送的消息
+func (c *Client) Run(onMessage func(payload []byte)) {
+	go c.writePump()
+	c.readPump(onMessage)
+}
+
+// readPump 读取客户端消息
+func (c *Client) readPump(onMessage func(payload []byte)) {
+	defer func() {
+		c.Close()
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, payload, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.Error("[Client.readPump] 连接异常关闭", "error", err)
+			}
+			return
+		}
+		if onMessage != nil {
+			onMessage(payload)
+		}
+	}
+}
+
+// writePump 向客户端写入消息并定时发送ping
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// 通道已关闭
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
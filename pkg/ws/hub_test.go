@@ -0,0 +1,68 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试房间广播
+func TestHubBroadcast(t *testing.T) {
+	hub := NewHub()
+	joined := make(chan struct{})
+
+	// 启动WebSocket服务，连接后加入房间
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("WebSocket升级失败: %v", err)
+			return
+		}
+		client := NewClient(conn)
+		hub.Join("room", client)
+		defer hub.Leave("room", client)
+		close(joined)
+		client.Run(nil)
+	}))
+	defer server.Close()
+
+	// 客户端连接
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	<-joined
+	assert.Equal(t, 1, hub.Count("room"))
+
+	// 广播消息
+	hub.Broadcast("room", Message{Type: "test", Data: "hello"})
+	hub.Broadcast("other", Message{Type: "test", Data: "ignored"})
+
+	// 验证收到的消息
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, payload, err := conn.ReadMessage()
+	assert.Nil(t, err)
+
+	var msg Message
+	assert.Nil(t, json.Unmarshal(payload, &msg))
+	assert.Equal(t, "test", msg.Type)
+	assert.Equal(t, "hello", msg.Data)
+}
+
+// 测试关闭后发送不会panic
+func TestClientSendAfterClose(t *testing.T) {
+	client := &Client{send: make(chan []byte, 1)}
+	client.Close()
+
+	assert.NotPanics(t, func() {
+		client.SendRaw([]byte("late"))
+		client.Close()
+	})
+}
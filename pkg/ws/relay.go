@@ -0,0 +1,122 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"video-platform/pkg/redis"
+)
+
+// envelope 通过Redis转发的消息
+type envelope struct {
+	Room       string          `json:"room"`
+	Payload    json.RawMessage `json:"payload"`
+	Disconnect bool            `json:"disconnect,omitempty"` // 发送后关闭房间内的连接
+}
+
+// Relay 基于Redis发布订阅在多个实例之间转发房间消息。
+// 每个实例订阅同一个频道，收到消息后推送给本实例上的连接；未初始化Redis时退化为本地广播。
+type Relay struct {
+	hub     *Hub
+	channel string
+
+	mu         sync.Mutex
+	subscribed bool
+}
+
+// NewRelay 创建转发实例
+func NewRelay(hub *Hub, channel string) *Relay {
+	if hub == nil {
+		hub = NewHub()
+	}
+	return &Relay{
+		hub:     hub,
+		channel: channel,
+	}
+}
+
+// Hub 获取本地连接管理
+func (r *Relay) Hub() *Hub {
+	return r.hub
+}
+
+// Join 加入房间，首次加入时开始订阅Redis频道
+func (r *Relay) Join(room string, c *Client) {
+	r.subscribe()
+	r.hub.Join(room, c)
+}
+
+// Leave 离开房间
+func (r *Relay) Leave(room string, c *Client) {
+	r.hub.Leave(room, c)
+}
+
+// Publish 向所有实例上该房间的连接发送消息
+func (r *Relay) Publish(ctx context.Context, room string, msg Message) error {
+	return r.publish(ctx, room, msg, false)
+}
+
+// Disconnect 向所有实例上该房间的连接发送最后一条消息后关闭连接，用于撤销订阅权限
+func (r *Relay) Disconnect(ctx context.Context, room string, msg Message) error {
+	return r.publish(ctx, room, msg, true)
+}
+
+func (r *Relay) publish(ctx context.Context, room string, msg Message, disconnect bool) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	env := envelope{Room: room, Payload: payload, Disconnect: disconnect}
+	client := redis.GetClient()
+	if client == nil {
+		r.deliver(env)
+		return nil
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	if err := client.Publish(ctx, r.channel, data).Err(); err != nil {
+		// Redis不可用时至少保证本实例上的连接能收到
+		r.deliver(env)
+		return err
+	}
+	return nil
+}
+
+// deliver 把消息推送给本实例上的连接
+func (r *Relay) deliver(env envelope) {
+	if env.Disconnect {
+		r.hub.DisconnectRaw(env.Room, env.Payload)
+		return
+	}
+	r.hub.BroadcastRaw(env.Room, env.Payload)
+}
+
+// subscribe 订阅Redis频道，只订阅一次
+func (r *Relay) subscribe() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client := redis.GetClient()
+	if r.subscribed || client == nil {
+		return
+	}
+	r.subscribed = true
+
+	pubsub := client.Subscribe(context.Background(), r.channel)
+	go func() {
+		defer pubsub.Close()
+		for msg := range pubsub.Channel() {
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				slog.Error("[Relay] 消息解析失败", "error", err, "channel", r.channel)
+				continue
+			}
+			r.deliver(env)
+		}
+	}()
+}
@@ -0,0 +1,51 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试未初始化Redis时退化为本地广播
+func TestRelayPublishLocal(t *testing.T) {
+	relay := NewRelay(nil, "test")
+	client := NewClient(nil)
+	relay.Join("room", client)
+	defer relay.Leave("room", client)
+
+	err := relay.Publish(context.Background(), "room", Message{Type: "test", Data: "hello"})
+	assert.Nil(t, err)
+
+	payload := <-client.send
+	var msg Message
+	assert.Nil(t, json.Unmarshal(payload, &msg))
+	assert.Equal(t, "test", msg.Type)
+	assert.Equal(t, "hello", msg.Data)
+}
+
+// 测试断开房间内的连接：先收到最后一条消息，然后连接关闭
+func TestRelayDisconnectLocal(t *testing.T) {
+	relay := NewRelay(nil, "test")
+	client := NewClient(nil)
+	other := NewClient(nil)
+	relay.Join("room:u1", client)
+	relay.Join("room:u2", other)
+	defer relay.Leave("room:u1", client)
+	defer relay.Leave("room:u2", other)
+
+	err := relay.Disconnect(context.Background(), "room:u1", Message{Type: "removed"})
+	assert.Nil(t, err)
+
+	payload, ok := <-client.send
+	assert.True(t, ok)
+	var msg Message
+	assert.Nil(t, json.Unmarshal(payload, &msg))
+	assert.Equal(t, "removed", msg.Type)
+	_, ok = <-client.send
+	assert.False(t, ok)
+
+	// 其他房间的连接不受影响
+	assert.False(t, other.closed)
+}
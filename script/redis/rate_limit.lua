@@ -0,0 +1,14 @@
+local key = KEYS[1] -- 限流计数的key rate:biz:user
+local limit = tonumber(ARGV[1]) -- 窗口内允许的最大次数
+local window = tonumber(ARGV[2]) -- 窗口长度（秒）
+
+local cnt = redis.call("incr", key)
+if cnt == 1 then -- 窗口内第一次请求，设置过期时间
+    redis.call("expire", key, window)
+end
+
+if cnt > limit then
+    return -1 -- 超过限流阈值
+else
+    return 0 -- 允许通过
+end
\ No newline at end of file
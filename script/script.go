@@ -7,4 +7,6 @@ var (
 	LuaSendCode string
 	//go:embed redis/verify_code.lua
 	LuaVerifyCode string
+	//go:embed redis/rate_limit.lua
+	LuaRateLimit string
 )